@@ -23,11 +23,10 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"mime/multipart"
 	"net/http"
 	"net/textproto"
@@ -40,7 +39,7 @@ import (
 	"github.com/websublime/sublime-cli/utils"
 )
 
-func (ctx *Supabase) CreateWorkspaceBucket(name string, public bool) (models.Bucket, error) {
+func (ctx *Supabase) CreateWorkspaceBucket(c context.Context, name string, public bool) (models.Bucket, error) {
 	bucket := models.NewBucket(name, slug.Make(name), public)
 	model := models.Bucket{}
 
@@ -49,43 +48,18 @@ func (ctx *Supabase) CreateWorkspaceBucket(name string, public bool) (models.Buc
 		return model, err
 	}
 
-	uri := fmt.Sprintf("%s/%s/bucket", ctx.BaseURL, StorageEndpoint)
-
-	req, err := http.NewRequest("POST", uri, bytes.NewBuffer(payload))
-	if err != nil {
-		return model, err
-	}
-	req.Header.Add("Content-Type", "application/json; charset=UTF-8")
-	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", ctx.ApiToken))
-	req.Header.Add("apikey", ctx.ApiKey)
-
-	response, err := ctx.HTTPClient.Do(req)
-	if err != nil {
-		return model, err
-	}
-
-	defer response.Body.Close()
-
-	body, err := ioutil.ReadAll(response.Body)
-	if err != nil {
-		return model, err
-	}
-
-	if response.StatusCode >= 400 {
-		return model, errors.New(string(body))
-	}
-
-	err = json.Unmarshal(body, &model)
-	if err != nil {
-		return model, err
-	}
+	err = ctx.Send(c, Request{
+		Method: http.MethodPost,
+		Path:   fmt.Sprintf("%s/bucket", StorageEndpoint),
+		Body:   payload,
+	}, &model)
 
 	return model, err
 }
 
-func (ctx *Supabase) Upload(bucket string, filePath string, destination string) (models.BucketUpload, error) {
+func (ctx *Supabase) Upload(c context.Context, bucket string, filePath string, destination string) (models.BucketUpload, error) {
 	model := models.BucketUpload{}
-	file, err := os.OpenFile(filePath, os.O_RDWR, 0755)
+	file, err := os.Open(filePath)
 	if err != nil {
 		return model, err
 	}
@@ -94,11 +68,6 @@ func (ctx *Supabase) Upload(bucket string, filePath string, destination string)
 	fileExtension := filepath.Ext(filePath)
 	mime := utils.GetMimeType(strings.TrimPrefix(fileExtension, "."))
 
-	stat, err := file.Stat()
-	if err != nil {
-		return model, err
-	}
-
 	payload := new(bytes.Buffer)
 	writer := multipart.NewWriter(payload)
 
@@ -120,36 +89,16 @@ func (ctx *Supabase) Upload(bucket string, filePath string, destination string)
 
 	writer.Close()
 
-	uri := fmt.Sprintf("%s/%s/object/%s/%s/%s", ctx.BaseURL, StorageEndpoint, bucket, destination, filepath.Base(file.Name()))
-
-	req, _ := http.NewRequest("POST", uri, payload)
-	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", ctx.ApiToken))
-	req.Header.Add("apikey", ctx.ApiKey)
-	req.Header.Add("x-upsert", "true")
-	req.Header.Add("Content-Type", writer.FormDataContentType())
-	req.Header.Add("Content-Length", fmt.Sprintf("%d", stat.Size()))
-
 	// https://gist.github.com/mattetti/5914158/f4d1393d83ebedc682a3c8e7bdc6b49670083b84
-	response, err := ctx.HTTPClient.Do(req)
-	if err != nil {
-		return model, err
-	}
-
-	defer response.Body.Close()
-
-	body, err := ioutil.ReadAll(response.Body)
-	if err != nil {
-		return model, err
-	}
-
-	if response.StatusCode >= 400 {
-		return model, errors.New(string(body))
-	}
+	// x-upsert makes the upload safe to retry.
+	err = ctx.Send(c, Request{
+		Method:      http.MethodPost,
+		Path:        fmt.Sprintf("%s/object/%s/%s/%s", StorageEndpoint, bucket, destination, filepath.Base(file.Name())),
+		Body:        payload.Bytes(),
+		ContentType: writer.FormDataContentType(),
+		Headers:     map[string]string{"x-upsert": "true"},
+		Idempotent:  true,
+	}, &model)
 
-	err = json.Unmarshal(body, &model)
-	if err != nil {
-		return model, err
-	}
-
-	return model, nil
+	return model, err
 }
@@ -0,0 +1,42 @@
+/*
+Copyright © 2022 Websublime.dev organization@websublime.dev
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/websublime/sublime-cli/models"
+)
+
+// Health checks the api is reachable and the api key is accepted.
+func (ctx *Supabase) Health(c context.Context) (models.Health, error) {
+	model := models.Health{}
+
+	err := ctx.Send(c, Request{
+		Method: http.MethodGet,
+		Path:   fmt.Sprintf("%s/health", AuthEndpoint),
+	}, &model)
+
+	return model, err
+}
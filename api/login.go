@@ -22,17 +22,15 @@ THE SOFTWARE.
 package api
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
-	"io/ioutil"
 	"net/http"
 
 	"github.com/websublime/sublime-cli/models"
 )
 
-func (ctx *Supabase) Login(email string, password string) (models.LoginResponse, error) {
+func (ctx *Supabase) Login(c context.Context, email string, password string) (models.LoginResponse, error) {
 	login := models.NewLogin(email, password)
 	model := models.LoginResponse{}
 
@@ -41,36 +39,11 @@ func (ctx *Supabase) Login(email string, password string) (models.LoginResponse,
 		return model, err
 	}
 
-	uri := fmt.Sprintf("%s/%s/token?grant_type=password", ctx.BaseURL, AuthEndpoint)
-
-	req, err := http.NewRequest("POST", uri, bytes.NewBuffer(payload))
-	if err != nil {
-		return model, err
-	}
-	req.Header.Add("Content-Type", "application/json; charset=UTF-8")
-	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", ctx.ApiToken))
-	req.Header.Add("apikey", ctx.ApiKey)
-
-	response, err := ctx.HTTPClient.Do(req)
-	if err != nil {
-		return model, err
-	}
-
-	defer response.Body.Close()
-
-	body, err := ioutil.ReadAll(response.Body)
-	if err != nil {
-		return model, err
-	}
-
-	if response.StatusCode >= 400 {
-		return model, errors.New(string(body))
-	}
-
-	err = json.Unmarshal(body, &model)
-	if err != nil {
-		return model, err
-	}
+	err = ctx.Send(c, Request{
+		Method: http.MethodPost,
+		Path:   fmt.Sprintf("%s/token?grant_type=password", AuthEndpoint),
+		Body:   payload,
+	}, &model)
 
 	return model, err
 }
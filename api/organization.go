@@ -22,18 +22,16 @@ THE SOFTWARE.
 package api
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
-	"io/ioutil"
 	"net/http"
 
 	"github.com/websublime/sublime-cli/core"
 	"github.com/websublime/sublime-cli/models"
 )
 
-func (ctx *Supabase) CreateOrganizationWorkspace(name string, repo string, description string, orgID string) ([]models.Workspace, error) {
+func (ctx *Supabase) CreateOrganizationWorkspace(c context.Context, name string, repo string, description string, orgID string) ([]models.Workspace, error) {
 	workspace := models.NewWorkspace(name, repo, description, orgID)
 	model := []models.Workspace{}
 
@@ -42,95 +40,143 @@ func (ctx *Supabase) CreateOrganizationWorkspace(name string, repo string, descr
 		return model, err
 	}
 
-	uri := fmt.Sprintf("%s/%s/workspaces", ctx.BaseURL, RestEndpoint)
+	err = ctx.Send(c, Request{
+		Method:  http.MethodPost,
+		Path:    fmt.Sprintf("%s/workspaces", RestEndpoint),
+		Body:    payload,
+		Headers: map[string]string{"Prefer": "return=representation"},
+	}, &model)
 
-	req, err := http.NewRequest("POST", uri, bytes.NewBuffer(payload))
-	if err != nil {
-		return model, err
-	}
-	req.Header.Add("Content-Type", "application/json; charset=UTF-8")
-	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", ctx.ApiToken))
-	req.Header.Add("apikey", ctx.ApiKey)
-	req.Header.Add("Prefer", "return=representation")
+	return model, err
+}
 
-	response, err := ctx.HTTPClient.Do(req)
-	if err != nil {
-		return model, err
-	}
+func (ctx *Supabase) GetOrganizationByUser(c context.Context, userID string) ([]models.OrganizationByUserResponse, error) {
+	model := []models.OrganizationByUserResponse{}
+
+	err := ctx.Send(c, Request{
+		Method: http.MethodGet,
+		Path:   fmt.Sprintf("%s/organization_users?user_id=eq.%s&select=role,organization(id,name,created_at)", RestEndpoint, userID),
+	}, &model)
+
+	return model, err
+}
 
-	defer response.Body.Close()
+func (ctx *Supabase) ValidateUserOrganization(c context.Context, authorID string, organization string) (bool, error) {
+	var isUserOrganization bool = false
+
+	app := core.GetApp()
 
-	body, err := ioutil.ReadAll(response.Body)
+	organizations, err := ctx.GetOrganizationByUser(c, authorID)
 	if err != nil {
-		return model, err
+		return isUserOrganization, err
 	}
 
-	if response.StatusCode >= 400 {
-		return model, errors.New(string(body))
+	for _, org := range organizations {
+		if org.Organization.Name == organization {
+			isUserOrganization = true
+			app.OrganizationID = org.Organization.ID
+			app.Organization = org.Organization.Name
+			break
+		}
 	}
 
-	err = json.Unmarshal(body, &model)
+	return isUserOrganization, nil
+}
+
+// CreateOrganization goes through an rpc that creates the organization and
+// makes the caller its owner in one transaction.
+func (ctx *Supabase) CreateOrganization(c context.Context, name string) (models.Organization, error) {
+	model := models.Organization{}
+
+	payload, err := json.Marshal(models.NewOrganization(name))
 	if err != nil {
 		return model, err
 	}
 
-	return model, nil
+	err = ctx.Send(c, Request{
+		Method: http.MethodPost,
+		Path:   fmt.Sprintf("%s/rpc/create_organization", RestEndpoint),
+		Body:   payload,
+	}, &model)
+
+	return model, err
 }
 
-func (ctx *Supabase) GetOrganizationByUser(userID string) ([]models.OrganizationByUserResponse, error) {
-	uri := fmt.Sprintf("%s/%s/organization_users?user_id=eq.%s&select=organization(id,name,created_at)", ctx.BaseURL, RestEndpoint, userID)
-	model := []models.OrganizationByUserResponse{}
+func (ctx *Supabase) DeleteOrganizationByID(c context.Context, orgID string) (models.Organization, error) {
+	model := models.Organization{}
+	rows := []models.Organization{}
 
-	req, err := http.NewRequest("GET", uri, nil)
+	err := ctx.Send(c, Request{
+		Method:  http.MethodDelete,
+		Path:    fmt.Sprintf("%s/organization?id=eq.%s", RestEndpoint, orgID),
+		Headers: map[string]string{"Prefer": "return=representation"},
+	}, &rows)
 	if err != nil {
 		return model, err
 	}
-	req.Header.Add("Content-Type", "application/json; charset=UTF-8")
-	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", ctx.ApiToken))
-	req.Header.Add("apikey", ctx.ApiKey)
 
-	response, err := ctx.HTTPClient.Do(req)
-	if err != nil {
-		return model, err
+	if len(rows) > 0 {
+		model = rows[0]
 	}
 
-	defer response.Body.Close()
+	return model, nil
+}
+
+// GetOrganizationMembers goes through an rpc because member emails and
+// names live on auth.users, which is not exposed on rest/v1.
+func (ctx *Supabase) GetOrganizationMembers(c context.Context, orgID string) ([]models.OrganizationMember, error) {
+	model := []models.OrganizationMember{}
 
-	body, err := ioutil.ReadAll(response.Body)
+	payload, err := json.Marshal(&models.OrganizationInvite{OrganizationID: orgID})
 	if err != nil {
 		return model, err
 	}
 
-	if response.StatusCode >= 400 {
-		return model, errors.New(string(body))
-	}
+	err = ctx.Send(c, Request{
+		Method:     http.MethodPost,
+		Path:       fmt.Sprintf("%s/rpc/get_organization_members", RestEndpoint),
+		Body:       payload,
+		Idempotent: true,
+	}, &model)
+
+	return model, err
+}
 
-	err = json.Unmarshal(body, &model)
+// InviteOrganizationMember adds a registered author, found by email, to the
+// organization. Only owners are allowed to invite.
+func (ctx *Supabase) InviteOrganizationMember(c context.Context, orgID string, email string, role string) (models.OrganizationMember, error) {
+	model := models.OrganizationMember{}
+
+	payload, err := json.Marshal(&models.OrganizationInvite{OrganizationID: orgID, Email: email, Role: role})
 	if err != nil {
 		return model, err
 	}
 
-	return model, nil
-}
+	err = ctx.Send(c, Request{
+		Method: http.MethodPost,
+		Path:   fmt.Sprintf("%s/rpc/invite_organization_member", RestEndpoint),
+		Body:   payload,
+	}, &model)
 
-func (ctx *Supabase) ValidateUserOrganization(authorID string, organization string) (bool, error) {
-	var isUserOrganization bool = false
+	return model, err
+}
 
-	app := core.GetApp()
+func (ctx *Supabase) RemoveOrganizationMember(c context.Context, orgID string, userID string) (models.OrganizationUser, error) {
+	model := models.OrganizationUser{}
+	rows := []models.OrganizationUser{}
 
-	organizations, err := ctx.GetOrganizationByUser(authorID)
+	err := ctx.Send(c, Request{
+		Method:  http.MethodDelete,
+		Path:    fmt.Sprintf("%s/organization_users?organization_id=eq.%s&user_id=eq.%s", RestEndpoint, orgID, userID),
+		Headers: map[string]string{"Prefer": "return=representation"},
+	}, &rows)
 	if err != nil {
-		return isUserOrganization, err
+		return model, err
 	}
 
-	for _, org := range organizations {
-		if org.Organization.Name == organization {
-			isUserOrganization = true
-			app.OrganizationID = org.Organization.ID
-			app.Organization = org.Organization.Name
-			break
-		}
+	if len(rows) == 0 {
+		return model, &ApiError{StatusCode: http.StatusNotFound, Message: fmt.Sprintf("member %s not found on organization", userID)}
 	}
 
-	return isUserOrganization, nil
+	return rows[0], nil
 }
@@ -22,18 +22,16 @@ THE SOFTWARE.
 package api
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
-	"io/ioutil"
 	"net/http"
 
 	"github.com/websublime/sublime-cli/models"
 	"github.com/websublime/sublime-cli/utils"
 )
 
-func (ctx *Supabase) CreateWorkspacePackage(name string, description string, types utils.PackageType, template utils.TemplateType, workspaceID string) ([]models.Package, error) {
+func (ctx *Supabase) CreateWorkspacePackage(c context.Context, name string, description string, types utils.PackageType, template utils.TemplateType, workspaceID string) ([]models.Package, error) {
 	workspace := models.NewPackage(name, description, types, template, workspaceID)
 	model := []models.Package{}
 
@@ -42,123 +40,84 @@ func (ctx *Supabase) CreateWorkspacePackage(name string, description string, typ
 		return model, err
 	}
 
-	uri := fmt.Sprintf("%s/%s/packages", ctx.BaseURL, RestEndpoint)
+	err = ctx.Send(c, Request{
+		Method:  http.MethodPost,
+		Path:    fmt.Sprintf("%s/packages", RestEndpoint),
+		Body:    payload,
+		Headers: map[string]string{"Prefer": "return=representation"},
+	}, &model)
 
-	req, err := http.NewRequest("POST", uri, bytes.NewBuffer(payload))
-	if err != nil {
-		return model, err
-	}
-	req.Header.Add("Content-Type", "application/json; charset=UTF-8")
-	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", ctx.ApiToken))
-	req.Header.Add("apikey", ctx.ApiKey)
-	req.Header.Add("Prefer", "return=representation")
-
-	response, err := ctx.HTTPClient.Do(req)
-	if err != nil {
-		return model, err
-	}
-
-	defer response.Body.Close()
-
-	body, err := ioutil.ReadAll(response.Body)
-	if err != nil {
-		return model, err
-	}
+	return model, err
+}
 
-	if response.StatusCode >= 400 {
-		return model, errors.New(string(body))
-	}
+func (ctx *Supabase) GetPackagesByWorkspace(c context.Context, workspaceID string) ([]models.Package, error) {
+	model := []models.Package{}
 
-	err = json.Unmarshal(body, &model)
-	if err != nil {
-		return model, err
-	}
+	err := ctx.Send(c, Request{
+		Method: http.MethodGet,
+		Path:   fmt.Sprintf("%s/packages?workspace_id=eq.%s&order=name.asc", RestEndpoint, workspaceID),
+	}, &model)
 
-	return model, nil
+	return model, err
 }
 
-func (ctx *Supabase) DeletePackageByID(packageID string) (models.Package, error) {
+func (ctx *Supabase) DeletePackageByID(c context.Context, packageID string) (models.Package, error) {
 	model := models.Package{
 		ID: packageID,
 	}
+	rows := []models.Package{}
 
-	uri := fmt.Sprintf("%s/%s/packages?id=eq.%s", ctx.BaseURL, RestEndpoint, packageID)
-
-	req, err := http.NewRequest("DELETE", uri, nil)
-	if err != nil {
-		return model, err
-	}
-	req.Header.Add("Content-Type", "application/json; charset=UTF-8")
-	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", ctx.ApiToken))
-	req.Header.Add("apikey", ctx.ApiKey)
-	req.Header.Add("Prefer", "return=representation")
-
-	response, err := ctx.HTTPClient.Do(req)
-	if err != nil {
-		return model, err
-	}
-
-	defer response.Body.Close()
-
-	body, err := ioutil.ReadAll(response.Body)
+	err := ctx.Send(c, Request{
+		Method:  http.MethodDelete,
+		Path:    fmt.Sprintf("%s/packages?id=eq.%s", RestEndpoint, packageID),
+		Headers: map[string]string{"Prefer": "return=representation"},
+	}, &rows)
 	if err != nil {
 		return model, err
 	}
 
-	if response.StatusCode >= 400 {
-		return model, errors.New(string(body))
-	}
-
-	err = json.Unmarshal(body, &model)
-	if err != nil {
-		return model, err
+	if len(rows) > 0 {
+		model = rows[0]
 	}
 
 	return model, nil
 }
 
-func (ctx *Supabase) UpdateWorkspacePackageVersion(id string, version string) (models.Package, error) {
-	workspace := &models.Package{
+func (ctx *Supabase) UpdateWorkspacePackageVersion(c context.Context, id string, version string) (models.Package, error) {
+	return ctx.UpdateWorkspacePackage(c, id, &models.Package{
 		Version: version,
-	}
-	model := models.Package{}
-
-	payload, err := json.Marshal(workspace)
-	if err != nil {
-		return model, err
-	}
+	})
+}
 
-	uri := fmt.Sprintf("%s/%s/packages?id=eq.%s", ctx.BaseURL, RestEndpoint, id)
+// UpdateWorkspacePackage patches the non empty fields of pkg. An empty id
+// would match no rows and silently update nothing, so it is an error, as is
+// an id unknown to the cloud.
+func (ctx *Supabase) UpdateWorkspacePackage(c context.Context, id string, pkg *models.Package) (models.Package, error) {
+	model := models.Package{}
+	rows := []models.Package{}
 
-	req, err := http.NewRequest("PATCH", uri, bytes.NewBuffer(payload))
-	if err != nil {
-		return model, err
+	if id == "" {
+		return model, &ApiError{StatusCode: http.StatusBadRequest, Message: "package id is empty", Hint: "Run \"sublime sync\" to back-fill package ids."}
 	}
-	req.Header.Add("Content-Type", "application/json; charset=UTF-8")
-	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", ctx.ApiToken))
-	req.Header.Add("apikey", ctx.ApiKey)
-	req.Header.Add("Prefer", "return=representation")
 
-	response, err := ctx.HTTPClient.Do(req)
+	payload, err := json.Marshal(pkg)
 	if err != nil {
 		return model, err
 	}
 
-	defer response.Body.Close()
-
-	body, err := ioutil.ReadAll(response.Body)
+	err = ctx.Send(c, Request{
+		Method:  http.MethodPatch,
+		Path:    fmt.Sprintf("%s/packages?id=eq.%s", RestEndpoint, id),
+		Body:    payload,
+		Headers: map[string]string{"Prefer": "return=representation"},
+	}, &rows)
 	if err != nil {
 		return model, err
 	}
 
-	if response.StatusCode >= 400 {
-		return model, errors.New(string(body))
-	}
-
-	err = json.Unmarshal(body, &model)
-	if err != nil {
-		return model, err
+	if len(rows) == 0 {
+		return model, &ApiError{StatusCode: http.StatusNotFound, Message: fmt.Sprintf("package %s not found", id), Hint: "Run \"sublime sync\" to back-fill package ids."}
 	}
 
-	return model, nil
+	return rows[0], nil
 }
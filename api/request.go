@@ -0,0 +1,348 @@
+/*
+Copyright © 2022 Websublime.dev organization@websublime.dev
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Request describes a single call to one of the supabase endpoints.
+// Path is relative to the base url (ex: rest/v1/packages?id=eq.1).
+// Idempotent opts a POST or PATCH that is safe to repeat into retries.
+type Request struct {
+	Method      string
+	Path        string
+	Body        []byte
+	ContentType string
+	Token       string
+	Headers     map[string]string
+	Idempotent  bool
+}
+
+// ApiError is returned for every response with a status >= 400. It carries
+// the fields used by PostgREST (code, message, details, hint), GoTrue
+// (error, error_description, msg) and storage (statusCode, error, message).
+type ApiError struct {
+	StatusCode int    `json:"status"`
+	Code       string `json:"code,omitempty"`
+	Message    string `json:"message,omitempty"`
+	Details    string `json:"details,omitempty"`
+	Hint       string `json:"hint,omitempty"`
+	Body       string `json:"-"`
+}
+
+func (err *ApiError) Error() string {
+	message := err.Message
+	if message == "" {
+		message = strings.TrimSpace(err.Body)
+	}
+	if message == "" {
+		message = http.StatusText(err.StatusCode)
+	}
+
+	parts := []string{fmt.Sprintf("status %d", err.StatusCode)}
+	if err.Code != "" {
+		parts = append(parts, fmt.Sprintf("code %s", err.Code))
+	}
+
+	output := fmt.Sprintf("%s (%s)", message, strings.Join(parts, ", "))
+	if err.Details != "" {
+		output = fmt.Sprintf("%s: %s", output, err.Details)
+	}
+	if err.Hint != "" {
+		output = fmt.Sprintf("%s. Hint: %s", output, err.Hint)
+	}
+
+	return output
+}
+
+// IsApiError reports if err is an ApiError with one of the given status codes.
+// Without codes it only checks the error type.
+func IsApiError(err error, status ...int) bool {
+	var apiErr *ApiError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+
+	if len(status) == 0 {
+		return true
+	}
+
+	for _, code := range status {
+		if apiErr.StatusCode == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RetryPolicy controls how many times an idempotent request is attempted
+// and how long to wait between attempts. Backoff doubles on each attempt
+// up to MaxBackoff.
+type RetryPolicy struct {
+	MaxAttempts int
+	Backoff     time.Duration
+	MaxBackoff  time.Duration
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	Backoff:     500 * time.Millisecond,
+	MaxBackoff:  5 * time.Second,
+}
+
+func (policy RetryPolicy) delay(attempt int) time.Duration {
+	wait := policy.Backoff
+	for idx := 1; idx < attempt; idx++ {
+		wait = wait * 2
+		if policy.MaxBackoff > 0 && wait > policy.MaxBackoff {
+			return policy.MaxBackoff
+		}
+	}
+
+	return wait
+}
+
+func isRetryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+
+	return false
+}
+
+func isIdempotentMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	}
+
+	return false
+}
+
+// Send executes the request, retrying according to the retry policy, and
+// decodes the response body into out (when out is not nil).
+func (ctx *Supabase) Send(c context.Context, req Request, out interface{}) error {
+	if c == nil {
+		c = context.Background()
+	}
+
+	attempts := ctx.Retry.MaxAttempts
+	if attempts < 1 || !(req.Idempotent || isIdempotentMethod(req.Method)) {
+		attempts = 1
+	}
+
+	var err error
+	var body []byte
+
+	for attempt := 1; attempt <= attempts; attempt++ {
+		var retry bool
+
+		body, retry, err = ctx.do(c, req)
+		if err == nil || !retry || attempt == attempts {
+			break
+		}
+
+		ctx.logf("<- retrying %s %s in %s (attempt %d/%d): %s", req.Method, req.Path, ctx.Retry.delay(attempt), attempt+1, attempts, err.Error())
+
+		select {
+		case <-c.Done():
+			return c.Err()
+		case <-time.After(ctx.Retry.delay(attempt)):
+		}
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if out == nil || len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(body, out)
+}
+
+func (ctx *Supabase) do(c context.Context, req Request) ([]byte, bool, error) {
+	uri := fmt.Sprintf("%s/%s", strings.TrimSuffix(ctx.BaseURL, "/"), req.Path)
+
+	var payload io.Reader
+	if req.Body != nil {
+		payload = bytes.NewReader(req.Body)
+	}
+
+	request, err := http.NewRequestWithContext(c, req.Method, uri, payload)
+	if err != nil {
+		return nil, false, err
+	}
+
+	token := req.Token
+	if token == "" {
+		token = ctx.ApiToken
+	}
+
+	contentType := req.ContentType
+	if contentType == "" {
+		contentType = "application/json; charset=UTF-8"
+	}
+
+	request.Header.Add("Content-Type", contentType)
+	request.Header.Add("Authorization", fmt.Sprintf("Bearer %s", token))
+	request.Header.Add("apikey", ctx.ApiKey)
+	for key, value := range req.Headers {
+		request.Header.Set(key, value)
+	}
+
+	ctx.logRequest(request, req.Body)
+
+	start := time.Now()
+	response, err := ctx.HTTPClient.Do(request)
+	if err != nil {
+		ctx.logf("<- %s %s failed after %s: %s", req.Method, req.Path, time.Since(start), err.Error())
+		return nil, c.Err() == nil, err
+	}
+
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, true, err
+	}
+
+	ctx.logResponse(response, body, time.Since(start))
+
+	if response.StatusCode >= 400 {
+		return body, isRetryableStatus(response.StatusCode), parseApiError(response.StatusCode, body)
+	}
+
+	return body, false, nil
+}
+
+func parseApiError(status int, body []byte) *ApiError {
+	apiErr := &ApiError{
+		StatusCode: status,
+		Body:       string(body),
+	}
+
+	fields := map[string]interface{}{}
+	if json.Unmarshal(body, &fields) != nil {
+		return apiErr
+	}
+
+	text := func(keys ...string) string {
+		for _, key := range keys {
+			switch value := fields[key].(type) {
+			case string:
+				if value != "" {
+					return value
+				}
+			case float64:
+				return fmt.Sprintf("%.0f", value)
+			}
+		}
+		return ""
+	}
+
+	apiErr.Code = text("error_code", "code", "error")
+	apiErr.Message = text("message", "msg", "error_description", "error")
+	apiErr.Details = text("details")
+	apiErr.Hint = text("hint")
+
+	if apiErr.Code == apiErr.Message || apiErr.Code == fmt.Sprintf("%d", status) {
+		apiErr.Code = text("error")
+		if apiErr.Code == apiErr.Message {
+			apiErr.Code = ""
+		}
+	}
+
+	return apiErr
+}
+
+var secretFields = regexp.MustCompile(`("(?:password|access_token|refresh_token|token|secret)"\s*:\s*)"[^"]*"`)
+
+func redactHeader(key string, value string) string {
+	switch strings.ToLower(key) {
+	case "authorization", "apikey", "x-sublime-deploy-token":
+		if strings.HasPrefix(value, "Bearer ") {
+			return "Bearer [REDACTED]"
+		}
+		return "[REDACTED]"
+	}
+
+	return value
+}
+
+func redactBody(contentType string, body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	if !strings.Contains(contentType, "json") {
+		return fmt.Sprintf("<%s, %d bytes>", contentType, len(body))
+	}
+
+	return secretFields.ReplaceAllString(string(body), `$1"[REDACTED]"`)
+}
+
+func (ctx *Supabase) logf(format string, args ...interface{}) {
+	if ctx.Logger == nil {
+		return
+	}
+
+	fmt.Fprintf(ctx.Logger, format+"\n", args...)
+}
+
+func (ctx *Supabase) logRequest(request *http.Request, body []byte) {
+	if ctx.Logger == nil {
+		return
+	}
+
+	ctx.logf("-> %s %s", request.Method, request.URL.String())
+	for key, values := range request.Header {
+		ctx.logf("   %s: %s", key, redactHeader(key, strings.Join(values, ", ")))
+	}
+	if payload := redactBody(request.Header.Get("Content-Type"), body); payload != "" {
+		ctx.logf("   %s", payload)
+	}
+}
+
+func (ctx *Supabase) logResponse(response *http.Response, body []byte, elapsed time.Duration) {
+	if ctx.Logger == nil {
+		return
+	}
+
+	ctx.logf("<- %s %s %d (%s)", response.Request.Method, response.Request.URL.Path, response.StatusCode, elapsed)
+	if payload := redactBody(response.Header.Get("Content-Type"), body); payload != "" {
+		ctx.logf("   %s", payload)
+	}
+}
@@ -22,9 +22,12 @@ THE SOFTWARE.
 package api
 
 import (
+	"io"
 	"net/http"
+	"os"
 	"strings"
-	"time"
+
+	"github.com/websublime/sublime-cli/core"
 )
 
 const (
@@ -39,6 +42,8 @@ type Supabase struct {
 	ApiToken    string
 	Environment string
 	HTTPClient  *http.Client
+	Retry       RetryPolicy
+	Logger      io.Writer
 }
 
 var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
@@ -48,13 +53,25 @@ func escapeQuotes(s string) string {
 }
 
 func NewSupabase(baseURL string, supabaseKey string, supabaseToken string, env string) *Supabase {
-	return &Supabase{
+	config := core.GetConfig()
+
+	retry := DefaultRetryPolicy
+	retry.MaxAttempts = config.Retries + 1
+
+	supabase := &Supabase{
 		BaseURL:     baseURL,
 		ApiKey:      supabaseKey,
 		ApiToken:    supabaseToken,
 		Environment: env,
 		HTTPClient: &http.Client{
-			Timeout: time.Minute,
+			Timeout: config.Timeout,
 		},
+		Retry: retry,
 	}
+
+	if config.Verbose {
+		supabase.Logger = os.Stderr
+	}
+
+	return supabase
 }
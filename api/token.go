@@ -0,0 +1,146 @@
+/*
+Copyright © 2022 Websublime.dev organization@websublime.dev
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/websublime/sublime-cli/models"
+)
+
+const DeployTokenPrefix = "sbt_"
+
+// HashDeployToken is the value stored on deploy_tokens.token_hash. The raw
+// token never leaves the machine that created it.
+func HashDeployToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
+}
+
+func newDeployToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return DeployTokenPrefix + hex.EncodeToString(buf), nil
+}
+
+// CreateDeployToken stores a new token for the workspace and returns the
+// row together with the raw token, which is only available at this point.
+func (ctx *Supabase) CreateDeployToken(c context.Context, workspaceID string, name string, scopes []string, expiresAt string) (models.DeployToken, string, error) {
+	model := models.DeployToken{}
+	rows := []models.DeployToken{}
+
+	token, err := newDeployToken()
+	if err != nil {
+		return model, "", err
+	}
+
+	prefix := token[:len(DeployTokenPrefix)+6]
+	payload, err := json.Marshal(models.NewDeployToken(workspaceID, name, scopes, HashDeployToken(token), prefix, expiresAt))
+	if err != nil {
+		return model, "", err
+	}
+
+	err = ctx.Send(c, Request{
+		Method:  http.MethodPost,
+		Path:    fmt.Sprintf("%s/deploy_tokens", RestEndpoint),
+		Body:    payload,
+		Headers: map[string]string{"Prefer": "return=representation"},
+	}, &rows)
+	if err != nil {
+		return model, "", err
+	}
+
+	if len(rows) > 0 {
+		model = rows[0]
+	}
+
+	return model, token, nil
+}
+
+func (ctx *Supabase) GetDeployTokensByWorkspace(c context.Context, workspaceID string) ([]models.DeployToken, error) {
+	model := []models.DeployToken{}
+
+	err := ctx.Send(c, Request{
+		Method: http.MethodGet,
+		Path:   fmt.Sprintf("%s/deploy_tokens?workspace_id=eq.%s&select=id,workspace_id,name,scopes,prefix,expires_at,revoked_at,last_used_at,created_at,created_by&order=created_at.desc", RestEndpoint, workspaceID),
+	}, &model)
+
+	return model, err
+}
+
+func (ctx *Supabase) RevokeDeployToken(c context.Context, tokenID string) (models.DeployToken, error) {
+	model := models.DeployToken{}
+	rows := []models.DeployToken{}
+
+	payload, err := json.Marshal(&models.DeployToken{
+		RevokedAt: time.Now().UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return model, err
+	}
+
+	err = ctx.Send(c, Request{
+		Method:  http.MethodPatch,
+		Path:    fmt.Sprintf("%s/deploy_tokens?id=eq.%s&revoked_at=is.null", RestEndpoint, tokenID),
+		Body:    payload,
+		Headers: map[string]string{"Prefer": "return=representation"},
+	}, &rows)
+	if err != nil {
+		return model, err
+	}
+
+	if len(rows) == 0 {
+		return model, &ApiError{StatusCode: http.StatusNotFound, Message: fmt.Sprintf("deploy token %s not found or already revoked", tokenID)}
+	}
+
+	return rows[0], nil
+}
+
+// ExchangeDeployToken trades a deploy token for a short lived access token
+// restricted to the token workspace and scopes.
+func (ctx *Supabase) ExchangeDeployToken(c context.Context, token string) (models.DeploySession, error) {
+	model := models.DeploySession{}
+
+	payload, err := json.Marshal(&models.DeployTokenExchange{Token: token})
+	if err != nil {
+		return model, err
+	}
+
+	err = ctx.Send(c, Request{
+		Method: http.MethodPost,
+		Path:   fmt.Sprintf("%s/rpc/exchange_deploy_token", RestEndpoint),
+		Body:   payload,
+	}, &model)
+
+	return model, err
+}
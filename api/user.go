@@ -22,48 +22,21 @@ THE SOFTWARE.
 package api
 
 import (
-	"encoding/json"
-	"errors"
+	"context"
 	"fmt"
-	"io/ioutil"
 	"net/http"
 
 	"github.com/websublime/sublime-cli/models"
 )
 
-func (ctx *Supabase) GetUser(token string) (models.User, error) {
+func (ctx *Supabase) GetUser(c context.Context, token string) (models.User, error) {
 	model := models.User{}
 
-	uri := fmt.Sprintf("%s/%s/user", ctx.BaseURL, AuthEndpoint)
-
-	req, err := http.NewRequest("GET", uri, nil)
-	if err != nil {
-		return model, err
-	}
-	req.Header.Add("Content-Type", "application/json; charset=UTF-8")
-	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", token))
-	req.Header.Add("apikey", ctx.ApiKey)
-
-	response, err := ctx.HTTPClient.Do(req)
-	if err != nil {
-		return model, err
-	}
-
-	defer response.Body.Close()
-
-	body, err := ioutil.ReadAll(response.Body)
-	if err != nil {
-		return model, err
-	}
-
-	if response.StatusCode >= 400 {
-		return model, errors.New(string(body))
-	}
-
-	err = json.Unmarshal(body, &model)
-	if err != nil {
-		return model, err
-	}
+	err := ctx.Send(c, Request{
+		Method: http.MethodGet,
+		Path:   fmt.Sprintf("%s/user", AuthEndpoint),
+		Token:  token,
+	}, &model)
 
 	return model, err
 }
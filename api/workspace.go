@@ -22,95 +22,69 @@ THE SOFTWARE.
 package api
 
 import (
-	"encoding/json"
-	"errors"
+	"context"
 	"fmt"
-	"io/ioutil"
 	"net/http"
 
 	"github.com/websublime/sublime-cli/models"
 )
 
-func (ctx *Supabase) DeleteWorkspaceByID(workspaceID string) (models.Workspace, error) {
+func (ctx *Supabase) DeleteWorkspaceByID(c context.Context, workspaceID string) (models.Workspace, error) {
 	model := models.Workspace{
 		ID: workspaceID,
 	}
+	rows := []models.Workspace{}
 
-	uri := fmt.Sprintf("%s/%s/workspaces?id=eq.%s", ctx.BaseURL, RestEndpoint, workspaceID)
-
-	req, err := http.NewRequest("DELETE", uri, nil)
+	err := ctx.Send(c, Request{
+		Method:  http.MethodDelete,
+		Path:    fmt.Sprintf("%s/workspaces?id=eq.%s", RestEndpoint, workspaceID),
+		Headers: map[string]string{"Prefer": "return=representation"},
+	}, &rows)
 	if err != nil {
 		return model, err
 	}
-	req.Header.Add("Content-Type", "application/json; charset=UTF-8")
-	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", ctx.ApiToken))
-	req.Header.Add("apikey", ctx.ApiKey)
-	req.Header.Add("Prefer", "return=representation")
 
-	response, err := ctx.HTTPClient.Do(req)
-	if err != nil {
-		return model, err
-	}
-
-	defer response.Body.Close()
-
-	body, err := ioutil.ReadAll(response.Body)
-	if err != nil {
-		return model, err
-	}
-
-	if response.StatusCode >= 400 {
-		return model, errors.New(string(body))
-	}
-
-	err = json.Unmarshal(body, &model)
-	if err != nil {
-		return model, err
+	if len(rows) > 0 {
+		model = rows[0]
 	}
 
 	return model, nil
 }
 
-func (ctx *Supabase) GetWorkspacesByOrganization(orgID string) ([]models.WorkspacesByOrganizationResponse, error) {
-	uri := fmt.Sprintf("%s/%s/workspaces?organization_id=eq.%s", ctx.BaseURL, RestEndpoint, orgID)
+func (ctx *Supabase) GetWorkspacesByOrganization(c context.Context, orgID string) ([]models.WorkspacesByOrganizationResponse, error) {
 	model := []models.WorkspacesByOrganizationResponse{}
 
-	req, err := http.NewRequest("GET", uri, nil)
-	if err != nil {
-		return model, err
-	}
-	req.Header.Add("Content-Type", "application/json; charset=UTF-8")
-	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", ctx.ApiToken))
-	req.Header.Add("apikey", ctx.ApiKey)
+	err := ctx.Send(c, Request{
+		Method: http.MethodGet,
+		Path:   fmt.Sprintf("%s/workspaces?organization_id=eq.%s", RestEndpoint, orgID),
+	}, &model)
 
-	response, err := ctx.HTTPClient.Do(req)
-	if err != nil {
-		return model, err
-	}
+	return model, err
+}
 
-	defer response.Body.Close()
+func (ctx *Supabase) GetWorkspaceByID(c context.Context, workspaceID string) (models.WorkspacesByOrganizationResponse, error) {
+	model := models.WorkspacesByOrganizationResponse{}
+	rows := []models.WorkspacesByOrganizationResponse{}
 
-	body, err := ioutil.ReadAll(response.Body)
+	err := ctx.Send(c, Request{
+		Method: http.MethodGet,
+		Path:   fmt.Sprintf("%s/workspaces?id=eq.%s", RestEndpoint, workspaceID),
+	}, &rows)
 	if err != nil {
 		return model, err
 	}
 
-	if response.StatusCode >= 400 {
-		return model, errors.New(string(body))
-	}
-
-	err = json.Unmarshal(body, &model)
-	if err != nil {
-		return model, err
+	if len(rows) == 0 {
+		return model, &ApiError{StatusCode: http.StatusNotFound, Message: fmt.Sprintf("workspace %s not found", workspaceID)}
 	}
 
-	return model, nil
+	return rows[0], nil
 }
 
-func (ctx *Supabase) ValidateWorkspaceOrganization(workspaceID string, orgID string) (bool, error) {
+func (ctx *Supabase) ValidateWorkspaceOrganization(c context.Context, workspaceID string, orgID string) (bool, error) {
 	var isWorkspaceOrganization bool = false
 
-	workspaces, err := ctx.GetWorkspacesByOrganization(orgID)
+	workspaces, err := ctx.GetWorkspacesByOrganization(c, orgID)
 	if err != nil {
 		return isWorkspaceOrganization, err
 	}
@@ -22,11 +22,11 @@ THE SOFTWARE.
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
-	"strconv"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -38,16 +38,21 @@ import (
 )
 
 type ActionFlags struct {
-	Type        string                   `json:"type"`
-	Environment string                   `json:"environment"`
-	Sublime     models.SublimeViperProps `json:"-"`
-	Packages    []models.SublimePackages `json:"-"`
+	Type        string                       `json:"type"`
+	Environment string                       `json:"environment"`
+	Tags        []string                     `json:"tags"`
+	Sublime     *models.SublimeJsonFileProps `json:"-"`
+	Packages    []models.SublimePackages     `json:"-"`
+	Supabase    *api.Supabase                `json:"-"`
+	Session     *models.DeploySession        `json:"-"`
+	Result      *models.ActionResult         `json:"-"`
 }
 
 func init() {
 	actionFlags := &ActionFlags{
-		Sublime:  models.SublimeViperProps{},
+		Sublime:  &models.SublimeJsonFileProps{},
 		Packages: []models.SublimePackages{},
+		Result:   &models.ActionResult{Artifacts: []models.ActionArtifact{}},
 	}
 	actionCmd := NewActionCmd(actionFlags)
 
@@ -55,123 +60,246 @@ func init() {
 
 	actionCmd.Flags().StringVar(&actionFlags.Type, utils.CommandFlagActionType, "branch", "Type of action (branch or tag)")
 	actionCmd.Flags().StringVar(&actionFlags.Environment, utils.CommandFlagActionEnv, "develop", "Environment")
+	actionCmd.Flags().StringSliceVar(&actionFlags.Tags, utils.CommandFlagActionTag, []string{}, utils.MessageCommandActionTag)
 }
 
 func NewActionCmd(cmdAction *ActionFlags) *cobra.Command {
 	return &cobra.Command{
-		Use:   utils.CommandAction,
-		Short: utils.MessageCommandActionShort,
-		Long:  utils.MessageCommandActionLong,
-		PreRun: func(cmd *cobra.Command, _ []string) {
+		Use:         utils.CommandAction,
+		Annotations: map[string]string{authorAnnotation: authorNone},
+		Short:       utils.MessageCommandActionShort,
+		Long:        utils.MessageCommandActionLong,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
 			isCiEnv := viper.GetBool("CI")
 
-			err := viper.Unmarshal(&cmdAction.Sublime)
+			sublime, err := core.GetApp().ReadSublime()
 			if err != nil {
-				utils.ErrorOut(err.Error(), utils.ErrorInvalidEnvironment)
+				return utils.NewCliError(err.Error(), utils.ErrorInvalidEnvironment)
 			}
+			cmdAction.Sublime = sublime
 
 			if !isCiEnv {
-				utils.ErrorOut(utils.MessageErrorCommandActionEnv, utils.ErrorInvalidEnvironment)
+				return utils.NewCliError(utils.MessageErrorCommandActionEnv, utils.ErrorInvalidEnvironment)
 			}
+
+			types := utils.GitType(cmdAction.Type)
+			if types != utils.Branch && types != utils.Tag {
+				return utils.NewCliError(utils.MessageCommandActionTypeUnknown, utils.ErrorInvalidFlag)
+			}
+
+			return cmdAction.Authenticate(cmd)
 		},
-		Run: func(cmd *cobra.Command, _ []string) {
-			cmdAction.Run(cmd)
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdAction.Run(cmd)
 		},
 	}
 }
 
-func getBranchDiffPackages(dir string, pkgs []models.SublimePackages) []models.SublimePackages {
-	packages := []models.SublimePackages{}
+// Authenticate exchanges SUBLIME_DEPLOY_TOKEN for a scoped access token. The
+// service role secret is only accepted as a deprecated fallback when set
+// explicitly with SUBLIME_API_SECRET.
+func (ctx *ActionFlags) Authenticate(cmd *cobra.Command) error {
+	env := string(utils.EnvType(ctx.Environment))
+	token := os.Getenv(utils.EnvDeployToken)
+
+	if token == "" {
+		secret := os.Getenv(core.EnvApiSecret)
+		if secret == "" {
+			return utils.NewCliError(utils.MessageErrorCommandActionNoToken, utils.ErrorInvalidToken)
+		}
+
+		utils.WarningOut(utils.MessageCommandActionServiceSecret)
+		ctx.Supabase = api.NewSupabase(utils.ApiUrl, secret, secret, env)
+		return nil
+	}
 
-	changedList, err := utils.GetBranchList(dir)
+	anon := api.NewSupabase(utils.ApiUrl, utils.ApiKey, utils.ApiKey, env)
+	session, err := anon.ExchangeDeployToken(cmd.Context(), token)
 	if err != nil {
-		utils.WarningOut(err.Error())
-		os.Exit(0)
+		return utils.NewCliError(err.Error(), utils.ErrorInvalidToken)
 	}
-	list := strings.Fields(changedList)
 
-	for _, pkg := range pkgs {
-		// founded := strings.Contains(changedList, pkg.Name)
-		founded := utils.Present(list, pkg.Name)
+	if ctx.Sublime.ID != "" && session.WorkspaceID != ctx.Sublime.ID {
+		return utils.NewCliError(fmt.Sprintf(utils.MessageErrorCommandActionWorkspace, session.WorkspaceID, ctx.Sublime.ID), utils.ErrorInvalidToken)
+	}
 
-		if founded {
-			packages = append(packages, pkg)
-		}
+	utils.InfoOut(fmt.Sprintf(utils.MessageCommandActionDeployToken, token[:len(api.DeployTokenPrefix)+6], strings.Join(session.Scopes, ",")))
+
+	ctx.Session = &session
+	ctx.Supabase = api.NewSupabase(utils.ApiUrl, utils.ApiKey, session.Token, env)
+
+	return nil
+}
+
+// RequireScope fails when the deploy token lacks the scope. Service role
+// credentials are not scoped.
+func (ctx *ActionFlags) RequireScope(scope utils.DeployScope) error {
+	if ctx.Session != nil && !ctx.Session.HasScope(string(scope)) {
+		return utils.NewCliError(fmt.Sprintf(utils.MessageErrorCommandActionScope, scope), utils.ErrorInvalidToken)
 	}
 
-	return packages
+	return nil
 }
 
-func (ctx *ActionFlags) Run(cmd *cobra.Command) {
+// Run deploys the artifacts of the changed packages (branch) or of the
+// packages of the pushed tags (tag). No commits or no changed packages is a
+// successful no-op.
+func (ctx *ActionFlags) Run(cmd *cobra.Command) error {
 	config := core.GetConfig()
 
-	types := utils.GitType(ctx.Type)
-	count, err := utils.GetCommitsCount(config.RootDir)
-	counter, err := strconv.ParseInt(count, 10, 0)
-	if err != nil || counter <= 0 {
-		utils.WarningOut(utils.MessageErrorCommandActionNoCommits)
-		os.Exit(0)
+	ctx.Result.Type = ctx.Type
+	utils.GetOutput().SetResult(ctx.Result)
+
+	repo, err := core.OpenGitRepo(config.RootDir)
+	if err != nil {
+		return utils.NewCliError(err.Error(), utils.ErrorInvalidGit)
 	}
 
-	switch types {
-	case utils.Branch:
-		ctx.Packages = getBranchDiffPackages(config.RootDir, ctx.Sublime.Packages)
+	count, err := repo.CommitsCount("HEAD")
+	if err != nil {
+		return utils.NewCliError(err.Error(), utils.ErrorInvalidGit)
+	}
 
-		if len(ctx.Packages) <= 0 {
-			utils.WarningOut(utils.MessageCommandActionNoPackages)
-			os.Exit(0)
-		} else {
-			utils.SuccessOut(fmt.Sprintf(utils.MessageCommandActionFoundPackages, len(ctx.Packages)))
+	if count <= 0 {
+		utils.WarningOut(utils.MessageErrorCommandActionNoCommits)
+		return nil
+	}
+
+	if utils.GitType(ctx.Type) == utils.Branch {
+		ctx.Packages, err = getBranchDiffPackages(commandContext(), repo, ctx.Sublime.Packages)
+		if err != nil {
+			return err
 		}
+	} else {
+		ctx.Packages, err = ctx.TagPackages(repo)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(ctx.Packages) <= 0 {
+		utils.WarningOut(utils.MessageCommandActionNoPackages)
+		return nil
+	}
 
-		ctx.DeployArtifacts()
-	case utils.Tag:
-		ctx.Packages = ctx.Sublime.Packages
+	utils.SuccessOut(fmt.Sprintf(utils.MessageCommandActionFoundPackages, len(ctx.Packages)))
 
-		if len(ctx.Packages) <= 0 {
-			utils.WarningOut(utils.MessageCommandActionNoPackages)
-			os.Exit(0)
-		} else {
-			utils.SuccessOut(fmt.Sprintf(utils.MessageCommandActionFoundPackages, len(ctx.Packages)))
-		}
+	if err := ctx.RunWorkspaceHook(core.HookPreDeploy); err != nil {
+		return err
+	}
 
-		ctx.DeployArtifacts()
-		ctx.UpdatePackageVersion()
-	default:
-		utils.WarningOut(utils.MessageCommandActionTypeUnknown)
-		os.Exit(0)
+	if err := ctx.DeployArtifacts(); err != nil {
+		return err
 	}
+
+	if utils.GitType(ctx.Type) == utils.Tag {
+		if err := ctx.UpdatePackageVersion(); err != nil {
+			return err
+		}
+	}
+
+	return ctx.RunWorkspaceHook(core.HookPostDeploy)
 }
 
-func (ctx *ActionFlags) DeployArtifacts() {
+// TagPackages are the packages of the release tags: --tag, the tag of
+// GITHUB_REF or the tags on HEAD (changesets tags every released package on
+// the release commit). Other tags are skipped, a package.json version not
+// matching its tag fails before anything is deployed.
+func (ctx *ActionFlags) TagPackages(repo *core.GitRepo) ([]models.SublimePackages, error) {
 	config := core.GetConfig()
-	env := utils.EnvType(ctx.Environment)
-	supabase := api.NewSupabase(utils.ApiUrl, utils.ApiSecret, utils.ApiSecret, string(env))
-	scope := fmt.Sprintf("@%s", ctx.Sublime.Organization)
-	isBranch := utils.GitType(ctx.Type) == utils.Branch
+	graph := config.ReadWorkspaceGraph(ctx.Sublime.Packages)
+	tags := ctx.Tags
 
-	for _, pkg := range ctx.Packages {
-		var libDir string = "libs"
-		isPackage := pkg.Type == utils.Package
+	if ref := os.Getenv(utils.EnvGithubRef); len(tags) == 0 && strings.HasPrefix(ref, "refs/tags/") {
+		tags = []string{strings.TrimPrefix(ref, "refs/tags/")}
+	}
 
-		if isPackage {
-			libDir = "packages"
+	if len(tags) == 0 {
+		headTags, err := repo.TagsAt("HEAD")
+		if err != nil {
+			return nil, utils.NewCliError(err.Error(), utils.ErrorInvalidGit)
 		}
 
-		packageDir := filepath.Join(config.RootDir, libDir, pkg.Name)
-		packageDistDir := filepath.Join(packageDir, "dist")
+		for _, tag := range headTags {
+			tags = append(tags, tag.Name)
+		}
+	}
 
-		packageJson := models.PackageJson{}
-		data, err := os.ReadFile(filepath.Join(packageDir, "package.json"))
+	packages := []models.SublimePackages{}
+
+	for _, tag := range tags {
+		packageTag, err := core.ParsePackageTag(tag)
 		if err != nil {
-			utils.WarningOut(utils.MessageErrorReadFile)
-			os.Exit(0)
+			utils.WarningOut(fmt.Sprintf(utils.MessageCommandActionTagSkipped, tag, err.Error()))
+			continue
+		}
+
+		found := graph.TagPackage(packageTag)
+		if found == nil {
+			utils.WarningOut(fmt.Sprintf(utils.MessageCommandActionTagSkipped, tag, fmt.Sprintf(utils.MessageErrorCommandActionTagName, packageTag.Name)))
+			continue
+		}
+
+		if found.Version != packageTag.Version {
+			return nil, utils.NewCliError(fmt.Sprintf(utils.MessageErrorCommandActionTagMatch, tag, found.Version, found.Package.Name), utils.ErrorVersionMismatch)
+		}
+
+		if !utils.Contains(ctx.Result.Tags, tag) {
+			ctx.Result.Tags = append(ctx.Result.Tags, tag)
+			packages = append(packages, found.Package)
 		}
+	}
+
+	if len(ctx.Result.Tags) > 0 {
+		utils.InfoOut(fmt.Sprintf(utils.MessageCommandActionTags, strings.Join(ctx.Result.Tags, ", ")))
+	}
+
+	return packages, nil
+}
+
+// RunWorkspaceHook runs a deploy hook of .sublime.json on the workspace root,
+// with the type, environment and names of the deployed packages.
+func (ctx *ActionFlags) RunWorkspaceHook(hook string) error {
+	if len(core.WorkspaceHookCommands(ctx.Sublime.Hooks, hook)) == 0 {
+		return nil
+	}
 
-		err = json.Unmarshal(data, &packageJson)
+	names := []string{}
+	for _, pkg := range ctx.Packages {
+		names = append(names, pkg.Name)
+	}
+
+	utils.InfoOut(fmt.Sprintf(utils.MessageCommandActionHooks, hook))
+
+	err := core.GetApp().RunWorkspaceHooks(commandContext(), ctx.Sublime.Hooks, hook, Version,
+		core.EnvDeployType+"="+ctx.Type,
+		core.EnvEnvironment+"="+ctx.Environment,
+		core.EnvPackages+"="+strings.Join(names, ","),
+	)
+	if err != nil {
+		return utils.NewCliError(err.Error(), utils.ErrorHookFailed)
+	}
+
+	return nil
+}
+
+func (ctx *ActionFlags) DeployArtifacts() error {
+	config := core.GetConfig()
+	supabase := ctx.Supabase
+	scope := fmt.Sprintf("@%s", ctx.Sublime.Organization)
+	isBranch := utils.GitType(ctx.Type) == utils.Branch
+
+	if err := ctx.RequireScope(utils.ScopeUpload); err != nil {
+		return err
+	}
+
+	for _, pkg := range ctx.Packages {
+		packageDir := filepath.Join(config.RootDir, packageLibDir(pkg), pkg.Name)
+		packageDistDir := filepath.Join(packageDir, "dist")
+
+		packageJson, err := readPackageJson(pkg.Name, packageDir)
 		if err != nil {
-			utils.WarningOut(utils.MessageErrorParseFile)
-			os.Exit(0)
+			return err
 		}
 
 		// patern: <bucket>/<package-json-name>/<package-json-version>(dev-SNAPSHOT)
@@ -189,15 +317,13 @@ func (ctx *ActionFlags) DeployArtifacts() {
 
 		distFiles, err := utils.PathWalk(packageDistDir)
 		if err != nil {
-			utils.WarningOut(err.Error())
-			os.Exit(0)
+			return utils.NewCliError(fmt.Sprintf(utils.MessageErrorCommandActionDist, pkg.Name, err.Error()), utils.ErrorMissingDirectory)
 		}
 
 		for _, file := range distFiles {
-			upload, err := supabase.Upload(ctx.Sublime.Organization, file, destinationFolder)
+			upload, err := supabase.Upload(commandContext(), ctx.Sublime.Organization, file, destinationFolder)
 			if err != nil {
-				utils.WarningOut(err.Error())
-				os.Exit(0)
+				return utils.NewCliError(fmt.Sprintf(utils.MessageErrorCommandActionUpload, file, err.Error()), utils.ErrorInvalidCloudOperation)
 			}
 
 			utils.InfoOut(fmt.Sprintf(utils.MessageCommandActionUploadFile, ctx.Sublime.Organization, upload.Key))
@@ -218,52 +344,104 @@ func (ctx *ActionFlags) DeployArtifacts() {
 			Docs:   fmt.Sprintf("https://websublime.dev/organization/%s/%s/%s", ctx.Sublime.Organization, ctx.Sublime.Name, pkg.Name),
 		})
 
-		manifest, err := supabase.Upload(ctx.Sublime.Organization, manifestFile.Name(), destinationFolder)
+		manifest, err := supabase.Upload(commandContext(), ctx.Sublime.Organization, manifestFile.Name(), destinationFolder)
+		os.Remove(manifestFile.Name())
 		if err != nil {
-			utils.WarningOut(err.Error())
-			os.Exit(0)
+			return utils.NewCliError(fmt.Sprintf(utils.MessageErrorCommandActionUpload, "manifest.json", err.Error()), utils.ErrorInvalidCloudOperation)
 		}
 
 		utils.InfoOut(fmt.Sprintf(utils.MessageCommandActionUploadFile, ctx.Sublime.Organization, manifest.Key))
 
-		os.Remove(manifestFile.Name())
-
+		ctx.Result.Artifacts = append(ctx.Result.Artifacts, models.ActionArtifact{
+			Name:     packageJson.Name,
+			Version:  pkgVersion,
+			Folder:   destinationFolder,
+			Files:    len(distFiles),
+			Manifest: manifest.Key,
+		})
 		utils.SuccessOut(utils.MessageCommandActionArtifact)
 	}
+
+	return nil
 }
 
-func (ctx *ActionFlags) UpdatePackageVersion() {
+// UpdatePackageVersion updates the cloud version of every package, failing
+// after the loop when any of them could not be updated.
+func (ctx *ActionFlags) UpdatePackageVersion() error {
 	config := core.GetConfig()
-	supabase := api.NewSupabase(utils.ApiUrl, utils.ApiSecret, utils.ApiSecret, "production")
+	supabase := ctx.Supabase
+	failed := 0
 
-	for _, pkg := range ctx.Packages {
-		var libDir string = "libs"
-		isPackage := pkg.Type == utils.Package
-
-		if isPackage {
-			libDir = "packages"
-		}
-
-		packageDir := filepath.Join(config.RootDir, libDir, pkg.Name)
+	if err := ctx.RequireScope(utils.ScopeVersion); err != nil {
+		return err
+	}
 
-		packageJson := models.PackageJson{}
-		data, err := os.ReadFile(filepath.Join(packageDir, "package.json"))
-		if err != nil {
-			utils.WarningOut(utils.MessageErrorReadFile)
-			os.Exit(0)
-		}
+	for _, pkg := range ctx.Packages {
+		packageDir := filepath.Join(config.RootDir, packageLibDir(pkg), pkg.Name)
 
-		err = json.Unmarshal(data, &packageJson)
+		packageJson, err := readPackageJson(pkg.Name, packageDir)
 		if err != nil {
-			utils.WarningOut(utils.MessageErrorParseFile)
-			os.Exit(0)
+			return err
 		}
 
-		_, err = supabase.UpdateWorkspacePackageVersion(pkg.ID, packageJson.Version)
+		_, err = supabase.UpdateWorkspacePackageVersion(commandContext(), pkg.ID, packageJson.Version)
 		if err != nil {
-			utils.WarningOut(err.Error())
+			utils.WarningOut(fmt.Sprintf("%s: %s", pkg.Name, err.Error()))
+			failed++
+			continue
 		}
 
+		ctx.Result.Versions = append(ctx.Result.Versions, models.PackageVersionResult{Name: pkg.Name, Version: packageJson.Version})
 		utils.SuccessOut(fmt.Sprintf(utils.MessageCommandActionVersionUpdate, pkg.Name, packageJson.Version))
 	}
+
+	if failed > 0 {
+		return utils.NewCliError(fmt.Sprintf(utils.MessageErrorCommandActionVersions, failed), utils.ErrorInvalidCloudOperation)
+	}
+
+	return nil
+}
+
+func packageLibDir(pkg models.SublimePackages) string {
+	if pkg.Type == utils.Package {
+		return "packages"
+	}
+
+	return "libs"
+}
+
+func readPackageJson(name string, packageDir string) (*models.PackageJson, error) {
+	packageJson := &models.PackageJson{}
+
+	data, err := os.ReadFile(filepath.Join(packageDir, "package.json"))
+	if err != nil {
+		return nil, utils.NewCliError(fmt.Sprintf(utils.MessageErrorCommandActionManifest, name, err.Error()), utils.ErrorReadFile)
+	}
+
+	if err := json.Unmarshal(data, packageJson); err != nil {
+		return nil, utils.NewCliError(fmt.Sprintf(utils.MessageErrorCommandActionManifest, name, err.Error()), utils.ErrorReadFile)
+	}
+
+	return packageJson, nil
+}
+
+// getBranchDiffPackages are the packages with files changed from the default
+// branch of origin.
+func getBranchDiffPackages(c context.Context, repo *core.GitRepo, pkgs []models.SublimePackages) ([]models.SublimePackages, error) {
+	packages := []models.SublimePackages{}
+
+	list, err := repo.ChangedFiles(c, "origin/"+core.GitDefaultBranch, "HEAD")
+	if err != nil {
+		return nil, utils.NewCliError(err.Error(), utils.ErrorInvalidGit)
+	}
+
+	for _, pkg := range pkgs {
+		founded := utils.Present(list, pkg.Name)
+
+		if founded {
+			packages = append(packages, pkg)
+		}
+	}
+
+	return packages, nil
 }
@@ -0,0 +1,376 @@
+/*
+Copyright © 2022 Websublime.dev organization@websublime.dev
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+	"github.com/websublime/sublime-cli/core"
+	"github.com/websublime/sublime-cli/models"
+	"github.com/websublime/sublime-cli/utils"
+)
+
+type ChangesetFlags struct {
+	Packages []string                     `json:"packages"`
+	Bump     string                       `json:"bump"`
+	Summary  string                       `json:"summary"`
+	DryRun   bool                         `json:"dry-run"`
+	Sublime  *models.SublimeJsonFileProps `json:"-"`
+}
+
+func init() {
+	changesetFlags := &ChangesetFlags{}
+	changesetCmd := NewChangesetCmd()
+
+	addCmd := NewChangesetAddCmd(changesetFlags)
+	addCmd.Flags().StringSliceVar(&changesetFlags.Packages, utils.CommandFlagChangesetPackage, []string{}, utils.MessageCommandChangesetPackage)
+	addCmd.Flags().StringVar(&changesetFlags.Bump, utils.CommandFlagChangesetBump, "", utils.MessageCommandChangesetBump)
+	addCmd.Flags().StringVar(&changesetFlags.Summary, utils.CommandFlagChangesetSummary, "", utils.MessageCommandChangesetSummary)
+
+	versionCmd := NewChangesetVersionCmd(changesetFlags)
+	versionCmd.Flags().BoolVar(&changesetFlags.DryRun, utils.CommandFlagChangesetDryRun, false, utils.MessageCommandChangesetDryRun)
+
+	changesetCmd.AddCommand(addCmd, NewChangesetStatusCmd(changesetFlags), versionCmd)
+	rootCommand.AddCommand(changesetCmd)
+}
+
+func NewChangesetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:         utils.CommandChangeset,
+		Annotations: map[string]string{authorAnnotation: authorNone},
+		Short:       utils.MessageCommandChangesetShort,
+		Long:        utils.MessageCommandChangesetLong,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cmd.Help()
+		},
+	}
+}
+
+func NewChangesetAddCmd(cmdChangeset *ChangesetFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   utils.CommandChangesetAdd,
+		Short: utils.MessageCommandChangesetAddShort,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			if err := cmdChangeset.ReadWorkspace(); err != nil {
+				return err
+			}
+
+			if cmdChangeset.Bump != "" && !utils.IsBumpType(cmdChangeset.Bump) {
+				return utils.NewCliError(fmt.Sprintf(utils.MessageErrorCommandChangesetBump, cmdChangeset.Bump), utils.ErrorInvalidFlag)
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdChangeset.Add(cmd)
+		},
+	}
+}
+
+func NewChangesetStatusCmd(cmdChangeset *ChangesetFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   utils.CommandChangesetStatus,
+		Short: utils.MessageCommandChangesetStatusShort,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdChangeset.ReadWorkspace()
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdChangeset.Status(cmd)
+		},
+	}
+}
+
+func NewChangesetVersionCmd(cmdChangeset *ChangesetFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   utils.CommandChangesetVersion,
+		Short: utils.MessageCommandChangesetVersionShort,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdChangeset.ReadWorkspace()
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdChangeset.Version(cmd)
+		},
+	}
+}
+
+func (ctx *ChangesetFlags) ReadWorkspace() error {
+	sublime, err := core.GetApp().ReadSublime()
+	if err != nil {
+		return utils.NewCliError(err.Error(), utils.ErrorInvalidWorkspace)
+	}
+
+	ctx.Sublime = sublime
+
+	return nil
+}
+
+// ChangedPackages are the packages with files changed since the base branch
+// (origin/<base>, or <base> without remote), committed or not.
+func (ctx *ChangesetFlags) ChangedPackages(graph *core.WorkspaceGraph, base string) ([]*core.WorkspacePackage, error) {
+	config := core.GetConfig()
+
+	repo, err := core.OpenGitRepo(config.RootDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, ref := range []string{"origin/" + base, base} {
+		if files, err = repo.BranchChanges(commandContext(), ref); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return graph.ChangedPackages(repo.Dir, config.RootDir, files), nil
+}
+
+// Candidates are the packages of --package, or the changed packages. Every
+// package is a candidate when none changed only when the bumps are prompted,
+// --bump without changes requires --package.
+func (ctx *ChangesetFlags) Candidates(graph *core.WorkspaceGraph, base string) ([]*core.WorkspacePackage, error) {
+	if len(ctx.Packages) > 0 {
+		candidates := []*core.WorkspacePackage{}
+
+		for _, name := range ctx.Packages {
+			node := graph.Find(name)
+			if node == nil {
+				return nil, utils.NewCliError(fmt.Sprintf(utils.MessageErrorCommandChangesetPackage, name), utils.ErrorInvalidFlag)
+			}
+
+			candidates = append(candidates, node)
+		}
+
+		return candidates, nil
+	}
+
+	changed, err := ctx.ChangedPackages(graph, base)
+	if err != nil {
+		utils.WarningOut(fmt.Sprintf(utils.MessageErrorCommandChangesetChanges, err.Error()))
+	}
+
+	if len(changed) == 0 {
+		if ctx.Bump != "" {
+			return nil, utils.NewCliError(fmt.Sprintf(utils.MessageErrorCommandChangesetNoChanges, base), utils.ErrorInvalidFlag)
+		}
+
+		utils.InfoOut(fmt.Sprintf(utils.MessageCommandChangesetAllPackages, base))
+		return graph.Packages, nil
+	}
+
+	return changed, nil
+}
+
+func (ctx *ChangesetFlags) Add(cmd *cobra.Command) error {
+	config := core.GetConfig()
+	changesetConfig := config.ReadChangesetConfig()
+	graph := config.ReadWorkspaceGraph(ctx.Sublime.Packages)
+
+	if (ctx.Bump == "" || ctx.Summary == "") && (!utils.IsInteractive() || utils.IsStructured()) {
+		return utils.NewCliError(utils.MessageErrorCommandChangesetPrompt, utils.ErrorPromptInvalid)
+	}
+
+	changeset := &models.Changeset{Releases: []models.ChangesetRelease{}}
+	bumps := []string{string(utils.BumpPatch), string(utils.BumpMinor), string(utils.BumpMajor), utils.MessageCommandChangesetSkip}
+
+	candidates, err := ctx.Candidates(graph, changesetConfig.BaseBranch)
+	if err != nil {
+		return err
+	}
+
+	for _, node := range candidates {
+		bump := utils.BumpType(ctx.Bump)
+
+		if bump == "" {
+			index, _, err := models.PromptGetSelect(models.PromptSelectContent{
+				Label: fmt.Sprintf(utils.MessageCommandChangesetBumpPrompt, node.Name, node.Version),
+				Items: bumps,
+			})
+			if err != nil {
+				return utils.NewCliError(err.Error(), utils.ErrorPromptInvalid)
+			}
+
+			if index >= len(bumps)-1 {
+				continue
+			}
+			bump = utils.BumpType(bumps[index])
+		}
+
+		changeset.Releases = append(changeset.Releases, models.ChangesetRelease{Name: node.Name, Type: bump})
+	}
+
+	if len(changeset.Releases) == 0 {
+		return utils.NewCliError(utils.MessageErrorCommandChangesetEmpty, utils.ErrorInvalidFlag)
+	}
+
+	changeset.Summary = ctx.Summary
+	if changeset.Summary == "" {
+		summary, err := models.PromptGetInput(models.PromptContent{
+			Error: utils.MessageErrorCommandChangesetSummary,
+			Label: utils.MessageCommandChangesetSummaryPrompt,
+		}, 0)
+		if err != nil {
+			return utils.NewCliError(err.Error(), utils.ErrorPromptInvalid)
+		}
+		changeset.Summary = summary
+	}
+
+	path, err := config.WriteChangeset(changeset)
+	if err != nil {
+		return utils.NewCliError(err.Error(), utils.ErrorCreateFile)
+	}
+
+	changesets, err := config.ReadChangesets()
+	if err != nil {
+		return utils.NewCliError(err.Error(), utils.ErrorReadFile)
+	}
+
+	file, _ := filepath.Rel(config.RootDir, path)
+	result := &models.ChangesetAddResult{
+		Changeset: *changeset,
+		File:      filepath.ToSlash(file),
+		Bumps:     core.PlanVersions(graph, changesets, changesetConfig),
+	}
+
+	utils.SuccessOut(fmt.Sprintf(utils.MessageCommandChangesetAdded, result.File))
+
+	render(result, func() {
+		fmt.Println(versionBumpsTable(result.Bumps))
+	})
+
+	return nil
+}
+
+func (ctx *ChangesetFlags) Status(cmd *cobra.Command) error {
+	config := core.GetConfig()
+	changesetConfig := config.ReadChangesetConfig()
+	graph := config.ReadWorkspaceGraph(ctx.Sublime.Packages)
+
+	changesets, err := config.ReadChangesets()
+	if err != nil {
+		return utils.NewCliError(err.Error(), utils.ErrorReadFile)
+	}
+
+	result := &models.ChangesetStatusResult{
+		Changesets: changesets,
+		Bumps:      core.PlanVersions(graph, changesets, changesetConfig),
+	}
+
+	if changed, err := ctx.ChangedPackages(graph, changesetConfig.BaseBranch); err == nil {
+		for _, node := range changed {
+			released := false
+			for _, bump := range result.Bumps {
+				released = released || (bump.Name == node.Name && len(bump.Changesets) > 0)
+			}
+
+			if !released {
+				result.Unreleased = append(result.Unreleased, node.Name)
+				utils.WarningOut(fmt.Sprintf(utils.MessageCommandChangesetUnreleased, node.Name))
+			}
+		}
+	}
+
+	if len(changesets) == 0 {
+		utils.InfoOut(utils.MessageCommandChangesetNone)
+		utils.GetOutput().SetResult(result)
+		return nil
+	}
+
+	render(result, func() {
+		fmt.Println(versionBumpsTable(result.Bumps))
+	})
+
+	return nil
+}
+
+func (ctx *ChangesetFlags) Version(cmd *cobra.Command) error {
+	config := core.GetConfig()
+	changesetConfig := config.ReadChangesetConfig()
+	graph := config.ReadWorkspaceGraph(ctx.Sublime.Packages)
+
+	changesets, err := config.ReadChangesets()
+	if err != nil {
+		return utils.NewCliError(err.Error(), utils.ErrorReadFile)
+	}
+
+	result := &models.ChangesetVersionResult{
+		DryRun: ctx.DryRun,
+		Bumps:  core.PlanVersions(graph, changesets, changesetConfig),
+		Files:  []string{},
+	}
+
+	if len(changesets) == 0 {
+		utils.InfoOut(utils.MessageCommandChangesetNone)
+		utils.GetOutput().SetResult(result)
+		return nil
+	}
+
+	if !ctx.DryRun {
+		result.Files, err = config.ApplyVersions(graph, changesets, result.Bumps, changesetConfig)
+		if err != nil {
+			return utils.NewCliError(err.Error(), utils.ErrorCreateFile)
+		}
+
+		removed, err := config.RemoveChangesets(changesets)
+		if err != nil {
+			return utils.NewCliError(err.Error(), utils.ErrorCreateFile)
+		}
+		result.Files = append(result.Files, removed...)
+	}
+
+	render(result, func() {
+		fmt.Println(versionBumpsTable(result.Bumps))
+	})
+
+	if ctx.DryRun {
+		utils.InfoOut(utils.MessageCommandChangesetDryRunOk)
+		return nil
+	}
+
+	manager := core.DetectPackageManager(config.RootDir, ctx.Sublime.PackageManager)
+	utils.SuccessOut(fmt.Sprintf(utils.MessageCommandChangesetVersioned, len(result.Bumps), manager.Command()+" install"))
+
+	return nil
+}
+
+func versionBumpsTable(bumps []models.VersionBump) string {
+	tabular := table.NewWriter()
+	tabular.SetStyle(table.StyleBold)
+	tabular.AppendHeader(table.Row{"Package", "Version", "Bump", "Next", "Changesets"})
+
+	for _, bump := range bumps {
+		reason := strings.Join(bump.Changesets, ", ")
+		if len(bump.Dependencies) > 0 {
+			reason = strings.TrimPrefix(reason+", dependencies: "+strings.Join(bump.Dependencies, ", "), ", ")
+		}
+
+		tabular.AppendRow(table.Row{bump.Name, bump.Version, bump.Type, bump.NewVersion, reason})
+	}
+
+	return tabular.Render()
+}
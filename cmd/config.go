@@ -0,0 +1,180 @@
+/*
+Copyright © 2022 Websublime.dev organization@websublime.dev
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+	"github.com/websublime/sublime-cli/core"
+	"github.com/websublime/sublime-cli/models"
+	"github.com/websublime/sublime-cli/utils"
+)
+
+type ConfigCommand struct{}
+
+func init() {
+	cmdConfig := &ConfigCommand{}
+	configCmd := NewConfigCmd()
+
+	configCmd.AddCommand(NewConfigValidateCmd(cmdConfig), NewConfigMigrateCmd(cmdConfig))
+	rootCommand.AddCommand(configCmd)
+}
+
+func NewConfigCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:         utils.CommandConfig,
+		Annotations: map[string]string{authorAnnotation: authorNone},
+		Short:       utils.MessageCommandConfigShort,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cmd.Help()
+		},
+	}
+}
+
+func NewConfigValidateCmd(cmdConfig *ConfigCommand) *cobra.Command {
+	return &cobra.Command{
+		Use:   utils.CommandConfigValidate,
+		Short: utils.MessageCommandConfigValidateShort,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdConfig.Validate()
+		},
+	}
+}
+
+func NewConfigMigrateCmd(cmdConfig *ConfigCommand) *cobra.Command {
+	return &cobra.Command{
+		Use:   utils.CommandConfigMigrate,
+		Short: utils.MessageCommandConfigMigrateShort,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdConfig.Migrate()
+		},
+	}
+}
+
+// Validate checks the file as it would be used by the cli, so an outdated
+// schema version is reported but the migrated document is validated.
+func (ctx *ConfigCommand) Validate() error {
+	store := core.GetApp().Store()
+	path := store.Path
+
+	doc, err := store.Document()
+	if err != nil {
+		return utils.NewCliError(fmt.Sprintf("%s %s", err.Error(), path), utils.ErrorInvalidConfig)
+	}
+
+	version := core.SchemaVersionOf(doc)
+
+	applied, err := core.MigrateSublime(doc)
+	if err != nil {
+		return utils.NewCliError(err.Error(), utils.ErrorInvalidConfig)
+	}
+
+	if len(applied) > 0 {
+		utils.WarningOut(fmt.Sprintf(utils.MessageCommandConfigOutdated, path, version, utils.SublimeSchemaVersion))
+	}
+
+	violations, err := core.ValidateSublime(doc)
+	if err != nil {
+		return utils.NewCliError(err.Error(), utils.ErrorInvalidConfig)
+	}
+
+	utils.GetOutput().SetResult(&models.ConfigResult{
+		Path:          path,
+		SchemaVersion: version,
+		Migrations:    migrationVersions(applied),
+		Violations:    violations,
+	})
+
+	if len(violations) == 0 {
+		utils.SuccessOut(fmt.Sprintf(utils.MessageCommandConfigValid, path, utils.SublimeSchemaVersion))
+		return nil
+	}
+
+	if !utils.IsStructured() {
+		tabular := table.NewWriter()
+		tabular.SetStyle(table.StyleBold)
+		tabular.AppendHeader(table.Row{"Path", "Violation"})
+
+		for _, violation := range violations {
+			tabular.AppendRow(table.Row{violation.Path, violation.Message})
+		}
+
+		fmt.Println(tabular.Render())
+	}
+
+	return utils.NewCliError(fmt.Sprintf(utils.MessageErrorCommandConfigInvalid, path, len(violations)), utils.ErrorInvalidConfig)
+}
+
+func (ctx *ConfigCommand) Migrate() error {
+	store := core.GetApp().Store()
+	path := store.Path
+
+	doc, err := store.Document()
+	if err != nil {
+		return utils.NewCliError(fmt.Sprintf("%s %s", err.Error(), path), utils.ErrorInvalidConfig)
+	}
+
+	version := core.SchemaVersionOf(doc)
+
+	applied, err := core.MigrateSublime(doc)
+	if err != nil {
+		return utils.NewCliError(err.Error(), utils.ErrorInvalidConfig)
+	}
+
+	utils.GetOutput().SetResult(&models.ConfigResult{
+		Path:          path,
+		SchemaVersion: version,
+		Migrations:    migrationVersions(applied),
+		Violations:    []models.SchemaViolation{},
+	})
+
+	if len(applied) == 0 {
+		utils.InfoOut(fmt.Sprintf(utils.MessageCommandConfigUpToDate, path, version))
+		return nil
+	}
+
+	err = store.Update(func(_ *models.SublimeJsonFileProps) error {
+		return nil
+	})
+	if err != nil {
+		return utils.NewCliError(err.Error(), utils.ErrorCreateFile)
+	}
+
+	for _, migration := range applied {
+		utils.InfoOut(fmt.Sprintf(utils.MessageCommandConfigMigration, migration.Version, migration.Description))
+	}
+
+	utils.SuccessOut(fmt.Sprintf(utils.MessageCommandConfigMigrated, path, version, utils.SublimeSchemaVersion))
+
+	return nil
+}
+
+func migrationVersions(applied []core.SublimeMigration) []int {
+	versions := []int{}
+	for _, migration := range applied {
+		versions = append(versions, migration.Version)
+	}
+
+	return versions
+}
@@ -24,14 +24,15 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"io/fs"
 	"os"
-	"os/exec"
+	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/gosimple/slug"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 	"github.com/websublime/sublime-cli/api"
 	"github.com/websublime/sublime-cli/core"
 	"github.com/websublime/sublime-cli/models"
@@ -39,20 +40,31 @@ import (
 )
 
 type CreateFlags struct {
-	Name        string                   `json:"name"`
-	Type        utils.PackageType        `json:"type"`
-	Template    utils.TemplateType       `json:"template"`
-	Description string                   `json:"description"`
-	Sublime     models.SublimeViperProps `json:"-"`
-	PackageDir  string                   `json:"-"`
-	LibTypeDir  string                   `json:"-"`
+	Name          string                       `json:"name"`
+	Type          utils.PackageType            `json:"type"`
+	Template      utils.TemplateType           `json:"template"`
+	Description   string                       `json:"description"`
+	Vars          map[string]string            `json:"vars"`
+	Sublime       *models.SublimeJsonFileProps `json:"-"`
+	PackageDir    string                       `json:"-"`
+	LibTypeDir    string                       `json:"-"`
+	TemplateDir   string                       `json:"-"`
+	TemplateFiles fs.FS                        `json:"-"`
+	Manifest      *models.TemplateManifest     `json:"-"`
+	Variables     map[string]string            `json:"-"`
+	Package       models.Package               `json:"-"`
 }
 
 func init() {
 	createFlags := &CreateFlags{
-		Sublime: models.SublimeViperProps{},
+		Sublime: &models.SublimeJsonFileProps{},
 	}
 	createCmd := NewCreateCmd(createFlags)
+	createCmd.Flags().StringVar((*string)(&createFlags.Template), utils.CommandFlagTemplate, "", utils.MessageCommandCreateTemplate)
+	createCmd.Flags().StringToStringVar(&createFlags.Vars, utils.CommandFlagVar, map[string]string{}, utils.MessageCommandCreateVar)
+	createCmd.Flags().StringVar(&createFlags.Name, utils.CommandFlagCreateName, "", utils.MessageCommandCreateName)
+	createCmd.Flags().StringVar((*string)(&createFlags.Type), utils.CommandFlagCreateType, "", utils.MessageCommandCreateType)
+	createCmd.Flags().StringVar(&createFlags.Description, utils.CommandFlagCreateDescription, "", utils.MessageCommandCreateDescription)
 
 	rootCommand.AddCommand(createCmd)
 }
@@ -62,44 +74,79 @@ func NewCreateCmd(cmdCreate *CreateFlags) *cobra.Command {
 		Use:   utils.CommandCreate,
 		Short: utils.MessageCommandCreateShort,
 		Long:  utils.MessageCommandCreateLong,
-		PreRun: func(cmd *cobra.Command, _ []string) {
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
 			app := core.GetApp()
 
-			err := viper.Unmarshal(&cmdCreate.Sublime)
+			if cmdCreate.Type != "" && !utils.IsPackageType(string(cmdCreate.Type)) {
+				return utils.NewCliError(fmt.Sprintf(utils.MessageErrorCommandCreateType, cmdCreate.Type), utils.ErrorInvalidFlag)
+			}
+
+			sublime, err := app.ReadSublime()
 			if err != nil {
-				utils.ErrorOut(err.Error(), utils.ErrorInvalidWorkspace)
+				return utils.NewCliError(err.Error(), utils.ErrorInvalidWorkspace)
 			}
+			cmdCreate.Sublime = sublime
 
 			supabase := api.NewSupabase(utils.ApiUrl, utils.ApiKey, app.Author.Token, "production")
-			isUserOrganization, err := supabase.ValidateUserOrganization(app.Author.ID, cmdCreate.Sublime.Organization)
+			isUserOrganization, err := supabase.ValidateUserOrganization(cmd.Context(), app.Author.ID, cmdCreate.Sublime.Organization)
 			if err != nil {
-				utils.ErrorOut(err.Error(), utils.ErrorInvalidOrganization)
+				return utils.NewCliError(err.Error(), utils.ErrorInvalidOrganization)
 			}
 
 			if !isUserOrganization {
-				utils.ErrorOut(utils.MessageErrorCommandWorkspaceInvalidOrganization, utils.ErrorInvalidOrganization)
+				return utils.NewCliError(utils.MessageErrorCommandWorkspaceInvalidOrganization, utils.ErrorInvalidOrganization)
 			}
 
-			isWorkspaceOrganization, err := supabase.ValidateWorkspaceOrganization(cmdCreate.Sublime.ID, app.OrganizationID)
+			isWorkspaceOrganization, err := supabase.ValidateWorkspaceOrganization(cmd.Context(), cmdCreate.Sublime.ID, app.OrganizationID)
 			if err != nil {
-				utils.ErrorOut(err.Error(), utils.ErrorInvalidOrganization)
+				return utils.NewCliError(err.Error(), utils.ErrorInvalidOrganization)
 			}
 
 			if !isWorkspaceOrganization {
-				utils.ErrorOut(utils.MessageErrorCommandWorkspaceInvalidOrganization, utils.ErrorInvalidWorkspace)
+				return utils.NewCliError(utils.MessageErrorCommandWorkspaceInvalidOrganization, utils.ErrorInvalidWorkspace)
 			}
+
+			return nil
 		},
-		Run: func(cmd *cobra.Command, _ []string) {
-			cmdCreate.Run(cmd)
-			cmdCreate.CreatePackage()
-			cmdCreate.UpdateRepoFiles()
-			cmdCreate.YarnLink()
-			cmdCreate.CreateCloudPackage()
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if err := cmdCreate.Run(cmd); err != nil {
+				return err
+			}
+
+			if err := cmdCreate.CreatePackage(); err != nil {
+				return err
+			}
+
+			if err := cmdCreate.UpdateRepoFiles(); err != nil {
+				return err
+			}
+
+			if err := cmdCreate.InstallPackages(); err != nil {
+				return err
+			}
+
+			if err := cmdCreate.RunHooks(); err != nil {
+				return err
+			}
+
+			if err := cmdCreate.CreateCloudPackage(); err != nil {
+				return err
+			}
+
+			cmdCreate.RecordTemplateFiles()
+
+			if err := cmdCreate.RunWorkspaceHook(core.HookPostCreate); err != nil {
+				return err
+			}
+
+			cmdCreate.Done()
+
+			return nil
 		},
 	}
 }
 
-func (ctx *CreateFlags) Run(cmd *cobra.Command) {
+func (ctx *CreateFlags) Run(cmd *cobra.Command) error {
 	nameContent := models.PromptContent{
 		Error: utils.MessageErrorCommandCreateNamePrompt,
 		Label: utils.MessageCommandCreateNamePrompt,
@@ -117,237 +164,344 @@ func (ctx *CreateFlags) Run(cmd *cobra.Command) {
 		Items: []string{fmt.Sprintf("Package: %s", string(utils.Package)), fmt.Sprintf("Library: %s", string(utils.Library))},
 	}
 
-	templateContent := models.PromptSelectContent{
-		Label: utils.MessageCommandCreateTemplatePrompt,
-		Items: []string{
-			fmt.Sprintf("SolidJS: %s", string(utils.Solid)),
-			fmt.Sprintf("Lit.dev: %s", string(utils.Lit)),
-			fmt.Sprintf("Vue: %s", string(utils.Vue)),
-			fmt.Sprintf("Typescript: %s", string(utils.Typescript)),
-		},
-	}
+	var err error
 
-	name, err := models.PromptGetInput(nameContent, 3)
-	if err != nil {
-		utils.ErrorOut(err.Error(), utils.ErrorPromptInvalid)
+	if ctx.Name == "" {
+		ctx.Name, err = models.PromptGetInput(nameContent, 3)
+		if err != nil {
+			return utils.NewCliError(err.Error(), utils.ErrorPromptInvalid)
+		}
 	}
 
-	description, err := models.PromptGetInput(descriptionContent, 3)
-	if err != nil {
-		utils.ErrorOut(err.Error(), utils.ErrorPromptInvalid)
+	if ctx.Description == "" {
+		ctx.Description, err = models.PromptGetInput(descriptionContent, 3)
+		if err != nil {
+			return utils.NewCliError(err.Error(), utils.ErrorPromptInvalid)
+		}
 	}
 
-	idxType, _, err := models.PromptGetSelect(typesContent)
-	if err != nil {
-		utils.ErrorOut(err.Error(), utils.ErrorPromptInvalid)
+	if ctx.Type == "" {
+		idxType, _, err := models.PromptGetSelect(typesContent)
+		if err != nil {
+			return utils.NewCliError(err.Error(), utils.ErrorPromptInvalid)
+		}
+
+		if idxType == 0 {
+			ctx.Type = utils.Package
+		} else {
+			ctx.Type = utils.Library
+		}
 	}
 
-	idxTemplate, _, err := models.PromptGetSelect(templateContent)
-	if err != nil {
-		utils.ErrorOut(err.Error(), utils.ErrorPromptInvalid)
+	if ctx.Template == "" {
+		ctx.Template, err = ctx.PromptTemplate()
+		if err != nil {
+			return err
+		}
 	}
 
-	if idxType == 0 {
-		ctx.Type = utils.Package
-	} else {
-		ctx.Type = utils.Library
+	ctx.Name = slug.Make(ctx.Name)
+
+	ctx.LibTypeDir = "libs"
+	if ctx.Type == utils.Package {
+		ctx.LibTypeDir = "packages"
 	}
 
-	if idxTemplate == 0 {
-		ctx.Template = utils.Solid
-	} else if idxTemplate == 1 {
-		ctx.Template = utils.Lit
-	} else if idxTemplate == 2 {
-		ctx.Template = utils.Vue
-	} else {
-		ctx.Template = utils.Typescript
+	ctx.PackageDir = filepath.Join(core.GetConfig().RootDir, ctx.LibTypeDir, ctx.Name)
+	if _, err := os.Stat(ctx.PackageDir); err == nil {
+		return utils.NewCliError(fmt.Sprintf(utils.MessageErrorCommandCreatePackageExists, ctx.PackageDir), utils.ErrorCreateDirectory)
 	}
 
-	ctx.Name = slug.Make(name)
-	ctx.Description = description
+	return ctx.PrepareTemplate()
 }
 
-func (ctx *CreateFlags) CreatePackage() {
+// PrepareTemplate fetches the template and loads its manifest. Templates
+// without a sublime-template.json use the builtin manifest of the same name
+// (typescript one for other templates). The manifest prompts are asked here,
+// before the progress starts.
+func (ctx *CreateFlags) PrepareTemplate() error {
 	config := core.GetConfig()
-	config.Progress.SetNumTrackersExpected(5)
-	config.Progress.Style().Visibility.Value = false
-
-	go config.Progress.Render()
-
-	config.UpdateProgress(utils.MessageCommandCreateProgressInit, 2)
-
-	ctx.LibTypeDir = "libs"
 
-	if ctx.Type == utils.Package {
-		ctx.LibTypeDir = "packages"
+	source, err := config.ResolveTemplate(string(ctx.Template), ctx.Sublime)
+	if err != nil {
+		return utils.NewCliError(err.Error(), utils.ErrorInvalidTemplate)
 	}
 
-	ctx.PackageDir = filepath.Join(config.RootDir, ctx.LibTypeDir, ctx.Name)
+	ctx.TemplateDir, err = config.FetchTemplate(commandContext(), source)
+	if err != nil {
+		return utils.NewCliError(err.Error(), utils.ErrorInvalidTemplate)
+	}
 
-	scope := fmt.Sprintf("@%s", ctx.Sublime.Organization)
-	libNamespace := strings.Join([]string{scope, ctx.Name}, "/")
-	viteRel, err := filepath.Rel(ctx.PackageDir, filepath.Join(config.RootDir, "libs/vite"))
+	ctx.Manifest, err = core.LoadTemplateManifest(ctx.TemplateDir)
 	if err != nil {
-		ctx.CommandError(err.Error(), utils.ErrorMissingDirectory)
+		return utils.NewCliError(err.Error(), utils.ErrorInvalidTemplate)
 	}
 
-	var templateLink string = ""
-	for i := range utils.TemplatesMap {
-		if utils.TemplatesMap[i].Template == ctx.Template {
-			templateLink = utils.TemplatesMap[i].Link
-			break
+	if ctx.Manifest == nil {
+		ctx.Manifest, ctx.TemplateFiles, err = BuiltinTemplateManifest(ctx.Template)
+		if err != nil {
+			return utils.NewCliError(err.Error(), utils.ErrorInvalidTemplate)
 		}
 	}
 
-	if templateLink == "" {
-		ctx.CommandError(utils.MessageErrorCommandCreateTemplateInvalid, utils.ErrorInvalidTemplate)
+	ctx.Variables, err = ctx.TemplateVariables()
+	if err != nil {
+		return utils.NewCliError(err.Error(), utils.ErrorInvalidTemplate)
 	}
 
-	gitCmd := exec.Command("git", "clone", templateLink, ctx.PackageDir)
-	_, err = gitCmd.Output()
+	return nil
+}
+
+// TemplateVariables are the builtin variables of the package plus the
+// variables and prompt answers of the manifest. Values given with --var
+// skip the prompts. Builtin variables can not be overridden.
+func (ctx *CreateFlags) TemplateVariables() (map[string]string, error) {
+	builtins, err := PackageTemplateVariables(ctx.Sublime, models.SublimePackages{
+		Name:        ctx.Name,
+		Type:        ctx.Type,
+		Description: ctx.Description,
+	}, ctx.Template)
 	if err != nil {
-		ctx.CommandError(err.Error(), utils.ErrorInvalidGit)
+		return nil, err
 	}
 
-	_ = os.RemoveAll(filepath.Join(ctx.PackageDir, ".git"))
-
-	var libPackageJson = "templates/lib-package.json"
-	var libTsconfigJson = "templates/tsconfig-lib.json"
-	var libViteConfigJson = "templates/vite-config-typescript.json"
+	vars := map[string]string{}
+	for name, value := range ctx.Vars {
+		vars[name] = value
+	}
+	for name, value := range builtins {
+		vars[name] = value
+	}
 
-	if ctx.Template == utils.Solid {
-		libPackageJson = "templates/lib-package-solid.json"
-		libTsconfigJson = "templates/tsconfig-lib-solid.json"
-		libViteConfigJson = "templates/vite-config-solid.json"
+	names := []string{}
+	for name := range ctx.Manifest.Variables {
+		names = append(names, name)
 	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if _, ok := ctx.Vars[name]; ok {
+			continue
+		}
+		if _, ok := builtins[name]; ok {
+			continue
+		}
 
-	if ctx.Template == utils.Vue {
-		libPackageJson = "templates/lib-package-vue.json"
-		libTsconfigJson = "templates/tsconfig-lib-vue.json"
-		libViteConfigJson = "templates/vite-config-vue.json"
+		value, err := core.RenderTemplateString(ctx.Manifest.Variables[name], ctx.Manifest.Delimiters, vars)
+		if err != nil {
+			return nil, err
+		}
+		vars[name] = value
 	}
 
-	if ctx.Template == utils.Lit {
-		libViteConfigJson = "templates/vite-config-lit.json"
+	for _, prompt := range ctx.Manifest.Prompts {
+		if _, ok := builtins[prompt.Name]; ok {
+			continue
+		}
+
+		value, ok := ctx.Vars[prompt.Name]
+		if !ok {
+			fallback, err := core.RenderTemplateString(prompt.Default, ctx.Manifest.Delimiters, vars)
+			if err != nil {
+				return nil, err
+			}
+
+			value = fallback
+			if utils.IsInteractive() {
+				if value, err = PromptTemplateVariable(prompt, fallback); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		if prompt.Required && value == "" {
+			return nil, fmt.Errorf(utils.MessageErrorTemplateVariable, prompt.Name)
+		}
+		vars[prompt.Name] = value
 	}
 
-	packageJson, err := FileTemplates.ReadFile(libPackageJson)
+	return vars, nil
+}
+
+// PackageTemplateVariables are the builtin template variables of a package
+// of the workspace.
+func PackageTemplateVariables(sublime *models.SublimeJsonFileProps, pkg models.SublimePackages, template utils.TemplateType) (map[string]string, error) {
+	config := core.GetConfig()
+	app := core.GetApp()
+	scope := fmt.Sprintf("@%s", sublime.Organization)
+
+	packageDir := filepath.Join(config.RootDir, packageLibDir(pkg), pkg.Name)
+	viteRel, err := filepath.Rel(packageDir, filepath.Join(config.RootDir, "libs/vite"))
 	if err != nil {
-		ctx.CommandError(err.Error(), utils.ErrorInvalidTemplate)
+		return nil, err
 	}
 
-	apiExtractorJson, err := FileTemplates.ReadFile("templates/api-extractor-lib.json")
-	if err != nil {
-		ctx.CommandError(err.Error(), utils.ErrorInvalidTemplate)
+	username, email := "", ""
+	if app.Author != nil {
+		username, email = app.Author.Username, app.Author.Email
 	}
 
-	tsConfigJson, err := FileTemplates.ReadFile(libTsconfigJson)
-	if err != nil {
-		ctx.CommandError(err.Error(), utils.ErrorInvalidTemplate)
+	variables := map[string]string{
+		"Name":         pkg.Name,
+		"Namespace":    strings.Join([]string{scope, pkg.Name}, "/"),
+		"Scope":        scope,
+		"Organization": sublime.Organization,
+		"Repo":         sublime.Repo,
+		"Description":  pkg.Description,
+		"Type":         packageLibDir(pkg),
+		"PackageType":  string(pkg.Type),
+		"Template":     string(template),
+		"Vite":         filepath.ToSlash(viteRel),
+		"Username":     username,
+		"Email":        email,
 	}
 
-	viteConfigJson, err := FileTemplates.ReadFile(libViteConfigJson)
-	if err != nil {
-		ctx.CommandError(err.Error(), utils.ErrorInvalidTemplate)
+	for name, value := range core.DetectPackageManager(config.RootDir, sublime.PackageManager).Variables() {
+		variables[name] = value
 	}
 
-	pkgJsonFile, err := os.Create(filepath.Join(ctx.PackageDir, "package.json"))
-	if err != nil {
-		ctx.CommandError(err.Error(), utils.ErrorCreateFile)
+	return variables, nil
+}
+
+// PromptTemplateVariable asks a manifest prompt. Confirm prompts answer true
+// or false.
+func PromptTemplateVariable(prompt models.TemplatePrompt, fallback string) (string, error) {
+	label := prompt.Message
+	if label == "" {
+		label = prompt.Name
+	}
+
+	switch prompt.Type {
+	case utils.TemplatePromptSelect:
+		items := []string{}
+		for _, choice := range prompt.Choices {
+			if choice == fallback {
+				items = append([]string{choice}, items...)
+			} else {
+				items = append(items, choice)
+			}
+		}
+
+		_, value, err := models.PromptGetSelect(models.PromptSelectContent{Label: label, Items: items})
+		return value, err
+	case utils.TemplatePromptConfirm:
+		items := []string{"Yes", "No"}
+		if !core.IsTemplateTruthy(fallback) {
+			items = []string{"No", "Yes"}
+		}
+
+		_, value, err := models.PromptGetSelect(models.PromptSelectContent{Label: label, Items: items})
+		return fmt.Sprint(value == "Yes"), err
+	default:
+		length := -1
+		if prompt.Required {
+			length = 0
+		}
+
+		return models.PromptGetInput(models.PromptContent{
+			Error:   fmt.Sprintf(utils.MessageErrorTemplateVariable, prompt.Name),
+			Label:   label,
+			Default: fallback,
+		}, length)
 	}
+}
 
-	_, err = pkgJsonFile.WriteString(utils.ProcessString(string(packageJson), &models.PackageJsonFileProps{
-		Namespace: libNamespace,
-		Repo:      ctx.Sublime.Repo,
-		Name:      ctx.Name,
-		Scope:     scope,
-		Type:      ctx.LibTypeDir,
-	}, "{{", "}}"))
-	if err != nil {
-		ctx.CommandError(err.Error(), utils.ErrorInvalidTemplate)
+// PromptTemplate asks for one of the builtin templates or of the templates
+// declared on .sublime.json and the home config.
+func (ctx *CreateFlags) PromptTemplate() (utils.TemplateType, error) {
+	labels := map[utils.TemplateType]string{
+		utils.Solid:      "SolidJS",
+		utils.Lit:        "Lit.dev",
+		utils.Vue:        "Vue",
+		utils.React:      "React",
+		utils.Preact:     "Preact",
+		utils.Svelte:     "Svelte",
+		utils.Vanilla:    "Vanilla web components",
+		utils.Typescript: "Typescript",
+	}
+	templates := []utils.TemplateType{utils.Solid, utils.Lit, utils.Vue, utils.React, utils.Preact, utils.Svelte, utils.Vanilla, utils.Typescript}
+
+	for _, source := range core.GetConfig().TemplateSources(ctx.Sublime) {
+		template := utils.TemplateType(source.Name)
+		if _, ok := labels[template]; ok || template == utils.Workspace {
+			continue
+		}
+
+		labels[template] = source.Name
+		templates = append(templates, template)
 	}
 
-	apiExtractorFile, err := os.Create(filepath.Join(ctx.PackageDir, "api-extractor.json"))
-	if err != nil {
-		ctx.CommandError(err.Error(), utils.ErrorCreateFile)
+	items := []string{}
+	for _, template := range templates {
+		items = append(items, fmt.Sprintf("%s: %s", labels[template], string(template)))
 	}
-	_, err = apiExtractorFile.WriteString(utils.ProcessString(string(apiExtractorJson), &models.ApiExtractorFileProps{
-		Name: ctx.Name,
-	}, "{{", "}}"))
+
+	idxTemplate, _, err := models.PromptGetSelect(models.PromptSelectContent{
+		Label: utils.MessageCommandCreateTemplatePrompt,
+		Items: items,
+	})
 	if err != nil {
-		ctx.CommandError(err.Error(), utils.ErrorInvalidTemplate)
+		return "", utils.NewCliError(err.Error(), utils.ErrorPromptInvalid)
 	}
 
-	tsConfigFile, err := os.Create(filepath.Join(ctx.PackageDir, "tsconfig.json"))
-	if err != nil {
-		ctx.CommandError(err.Error(), utils.ErrorCreateFile)
+	return templates[idxTemplate], nil
+}
+
+func (ctx *CreateFlags) CreatePackage() error {
+	config := core.GetConfig()
+
+	steps := 4
+	if len(ctx.Manifest.Hooks.PostCreate) > 0 {
+		steps++
 	}
-	_, err = tsConfigFile.WriteString(utils.ProcessString(string(tsConfigJson), &models.TsConfigJsonFileProps{
-		Namespace: libNamespace,
-		Vite:      viteRel,
-	}, "{{", "}}"))
-	if err != nil {
-		ctx.CommandError(err.Error(), utils.ErrorInvalidTemplate)
+
+	for _, hook := range []string{core.HookPreCreate, core.HookPostCreate} {
+		if len(core.WorkspaceHookCommands(ctx.Sublime.Hooks, hook)) > 0 {
+			steps++
+		}
 	}
 
-	viteConfigFile, err := os.Create(filepath.Join(ctx.PackageDir, "vite.config.js"))
-	if err != nil {
-		ctx.CommandError(err.Error(), utils.ErrorCreateFile)
+	config.Progress.Start(steps)
+	if err := ctx.RunWorkspaceHook(core.HookPreCreate); err != nil {
+		return err
 	}
-	viteConfigFile.WriteString(utils.ProcessString(string(viteConfigJson), &models.ViteJsonFileProps{
-		Scope: scope,
-		Name:  ctx.Name,
-	}, "{{", "}}"))
-	if err != nil {
-		ctx.CommandError(err.Error(), utils.ErrorInvalidTemplate)
+	config.Progress.Step(utils.MessageCommandCreateProgressInit)
+
+	if err := core.ApplyTemplate(ctx.TemplateDir, ctx.PackageDir, ctx.Manifest, ctx.TemplateFiles, ctx.Variables); err != nil {
+		return ctx.CommandError(err.Error(), utils.ErrorInvalidTemplate)
 	}
 
-	config.DoneProgress()
+	return nil
 }
 
-func (ctx *CreateFlags) UpdateRepoFiles() {
+func (ctx *CreateFlags) UpdateRepoFiles() error {
 	config := core.GetConfig()
 	app := core.GetApp()
 
-	config.AddTracker()
-
 	scope := fmt.Sprintf("@%s", ctx.Sublime.Organization)
 
-	go config.Progress.Render()
+	config.Progress.Step(utils.MessageCommandCreateProgressUpdate)
 
-	config.UpdateProgress(utils.MessageCommandCreateProgressUpdate, 2)
-
-	update := map[string]interface{}{
-		"namespace":    ctx.Sublime.Namespace,
-		"name":         ctx.Sublime.Name,
-		"repo":         ctx.Sublime.Repo,
-		"root":         "./",
-		"organization": ctx.Sublime.Organization,
-		"id":           ctx.Sublime.ID,
-		"description":  ctx.Sublime.Description,
-		"packages": append(ctx.Sublime.Packages, models.SublimePackages{
+	err := app.UpdateSublime(func(sublime *models.SublimeJsonFileProps) error {
+		sublime.Root = "./"
+		sublime.Packages = append(sublime.Packages, models.SublimePackages{
 			Name:        ctx.Name,
 			Scope:       scope,
 			Type:        ctx.Type,
 			Description: ctx.Description,
 			ID:          "",
-		}),
-	}
-
-	data, err := json.MarshalIndent(update, "", " ")
-	if err != nil {
-		ctx.CommandError(err.Error(), utils.ErrorInvalidaIndentation)
-	}
+		})
 
-	err = os.WriteFile(filepath.Join(config.RootDir, ".sublime.json"), data, 0644)
+		return nil
+	})
 	if err != nil {
-		ctx.CommandError(err.Error(), utils.ErrorCreateFile)
+		return ctx.CommandError(err.Error(), utils.ErrorCreateFile)
 	}
 
 	tsConfigBase, err := app.GetTsconfig()
 	if err != nil {
 		app.RemoveConfigurationsOnPackageError(ctx.Name, ctx.LibTypeDir)
-		ctx.CommandError(err.Error(), utils.ErrorInvalidTypescript)
+		return ctx.CommandError(err.Error(), utils.ErrorInvalidTypescript)
 	}
 
 	tsConfigBase.References = append(tsConfigBase.References, models.TsConfigReferences{
@@ -358,72 +512,174 @@ func (ctx *CreateFlags) UpdateRepoFiles() {
 	tsconfig, err := json.MarshalIndent(tsConfigBase, "", " ")
 	if err != nil {
 		app.RemoveConfigurationsOnPackageError(ctx.Name, ctx.LibTypeDir)
-		ctx.CommandError(err.Error(), utils.ErrorInvalidaIndentation)
+		return ctx.CommandError(err.Error(), utils.ErrorInvalidaIndentation)
 	}
 
 	err = os.WriteFile(filepath.Join(config.RootDir, "tsconfig.base.json"), tsconfig, 0644)
 	if err != nil {
 		app.RemoveConfigurationsOnPackageError(ctx.Name, ctx.LibTypeDir)
-		ctx.CommandError(err.Error(), utils.ErrorCreateFile)
+		return ctx.CommandError(err.Error(), utils.ErrorCreateFile)
 	}
 
-	config.DoneProgress()
+	return nil
 }
 
-func (ctx *CreateFlags) YarnLink() {
+// InstallPackages links the new package with the package manager of the
+// workspace.
+func (ctx *CreateFlags) InstallPackages() error {
 	config := core.GetConfig()
 	app := core.GetApp()
-	config.AddTracker()
 
-	go config.Progress.Render()
+	manager := core.DetectPackageManager(config.RootDir, ctx.Sublime.PackageManager)
 
-	config.UpdateProgress(utils.MessageCommandCreateProgressYarn, 2)
+	config.Progress.Step(fmt.Sprintf(utils.MessageCommandCreateProgressYarn, manager.Type))
 
-	_, err := utils.YarnInstall(config.RootDir)
-	if err != nil {
+	if err := manager.Install(commandContext(), config.RootDir); err != nil {
+		app.RemoveConfigurationsOnPackageError(ctx.Name, ctx.LibTypeDir)
+		return ctx.CommandError(err.Error(), utils.ErrorInvalidYarn)
+	}
+
+	return nil
+}
+
+// RecordTemplateFiles records the files rendered from the embedded templates
+// for "sublime upgrade". The package.json is not recorded, it is owned by
+// changesets and dependency updates.
+func (ctx *CreateFlags) RecordTemplateFiles() {
+	if ctx.TemplateFiles == nil {
+		return
+	}
+
+	config := core.GetConfig()
+
+	delimiters := ctx.Manifest.Delimiters
+	if delimiters == nil {
+		delimiters = []string{"{{", "}}"}
+	}
+
+	files := []models.GeneratedFile{}
+	for _, file := range ctx.Manifest.Files {
+		if file.Target == "package.json" {
+			continue
+		}
+
+		target := filepath.Join(ctx.LibTypeDir, ctx.Name, file.Target)
+		data, err := os.ReadFile(filepath.Join(config.RootDir, target))
+		if err != nil {
+			continue
+		}
+
+		files = append(files, models.GeneratedFile{
+			Path:       filepath.ToSlash(target),
+			Template:   path.Join("templates", file.Source),
+			Delimiters: delimiters,
+			Variables:  ctx.Variables,
+			Hash:       core.HashContent(data),
+			Version:    Version,
+		})
+	}
+
+	if err := core.RecordGeneratedFiles(config.RootDir, files...); err != nil {
+		utils.WarningOut(err.Error())
+	}
+}
+
+// RunHooks runs the post create hooks of the template on the package dir.
+func (ctx *CreateFlags) RunHooks() error {
+	if len(ctx.Manifest.Hooks.PostCreate) == 0 {
+		return nil
+	}
+
+	config := core.GetConfig()
+	app := core.GetApp()
+
+	config.Progress.Step(utils.MessageCommandCreateProgressHooks)
+
+	if err := core.RunTemplateHooks(commandContext(), ctx.PackageDir, ctx.Manifest, ctx.Variables); err != nil {
 		app.RemoveConfigurationsOnPackageError(ctx.Name, ctx.LibTypeDir)
-		ctx.CommandError(err.Error(), utils.ErrorInvalidYarn)
+		return ctx.CommandError(err.Error(), utils.ErrorInvalidTemplate)
 	}
 
-	config.DoneProgress()
+	return nil
 }
 
-func (ctx *CreateFlags) CreateCloudPackage() {
+// RunWorkspaceHook runs a create hook of .sublime.json on the workspace root.
+// A failed preCreate aborts before the package is written, a failed
+// postCreate keeps the created package.
+func (ctx *CreateFlags) RunWorkspaceHook(hook string) error {
+	if len(core.WorkspaceHookCommands(ctx.Sublime.Hooks, hook)) == 0 {
+		return nil
+	}
+
 	config := core.GetConfig()
 	app := core.GetApp()
-	config.AddTracker()
 
-	go config.Progress.Render()
+	config.Progress.Step(fmt.Sprintf(utils.MessageCommandCreateProgressWorkspace, hook))
+
+	err := app.RunWorkspaceHooks(commandContext(), ctx.Sublime.Hooks, hook, Version,
+		core.EnvPackage+"="+ctx.Name,
+		core.EnvPackageType+"="+string(ctx.Type),
+		core.EnvPackageDir+"="+ctx.PackageDir,
+		core.EnvTemplate+"="+string(ctx.Template),
+	)
+	if err == nil {
+		return nil
+	}
+
+	if hook == core.HookPreCreate {
+		return ctx.CommandError(err.Error(), utils.ErrorHookFailed)
+	}
+
+	config.Progress.Fail(fmt.Sprintf("Error: %s", utils.ErrorHookFailed))
+
+	return utils.NewCliError(err.Error(), utils.ErrorHookFailed)
+}
 
-	config.UpdateProgress(utils.MessageCommandCreateProgressCloud, 2)
+func (ctx *CreateFlags) CreateCloudPackage() error {
+	config := core.GetConfig()
+	app := core.GetApp()
+
+	config.Progress.Step(utils.MessageCommandCreateProgressCloud)
 
 	supabase := api.NewSupabase(utils.ApiUrl, utils.ApiKey, app.Author.Token, "production")
-	packages, err := supabase.CreateWorkspacePackage(ctx.Name, ctx.Description, ctx.Type, ctx.Template, ctx.Sublime.ID)
+	packages, err := supabase.CreateWorkspacePackage(commandContext(), ctx.Name, ctx.Description, ctx.Type, ctx.Template, ctx.Sublime.ID)
 	if err != nil {
 		app.RemoveConfigurationsOnPackageError(ctx.Name, ctx.LibTypeDir)
-		ctx.CommandError(err.Error(), utils.ErrorInvalidCloudOperation)
+		return ctx.CommandError(err.Error(), utils.ErrorInvalidCloudOperation)
 	}
 
-	config.UpdateProgress(utils.MessageCommandCreateProgressCloud, 6)
 	err = app.UpdatePackage(&packages[0])
 	if err != nil {
 		app.RemoveConfigurationsOnPackageError(ctx.Name, ctx.LibTypeDir)
-		_, _ = supabase.DeletePackageByID(packages[0].ID)
-		ctx.CommandError(err.Error(), utils.ErrorInvalidCloudOperation)
+		_, _ = supabase.DeletePackageByID(commandContext(), packages[0].ID)
+		return ctx.CommandError(err.Error(), utils.ErrorInvalidCloudOperation)
 	}
 
-	config.UpdateProgress(utils.MessageCommandCreateProgressCloud, 1)
-	config.TerminateProgress()
+	ctx.Package = packages[0]
+
+	return nil
+}
+
+func (ctx *CreateFlags) Done() {
+	config := core.GetConfig()
+
+	config.Progress.Done()
+
+	utils.GetOutput().SetResult(&models.PackageCreateResult{
+		Package: ctx.Package,
+		Path:    filepath.Join(ctx.LibTypeDir, ctx.Name),
+	})
 	utils.SuccessOut(utils.MessageCommandCreateSuccess)
 }
 
-func (ctx *CreateFlags) CommandError(message string, errorType utils.ErrorType) {
+func (ctx *CreateFlags) CommandError(message string, errorType utils.ErrorType) error {
 	config := core.GetConfig()
 
 	if ctx.PackageDir != "" {
 		os.RemoveAll(ctx.PackageDir)
 	}
 
-	config.TerminateErrorProgress(fmt.Sprintf("Error: %s", errorType))
-	utils.ErrorOut(message, errorType)
+	config.Progress.Fail(fmt.Sprintf("Error: %s", errorType))
+
+	return utils.NewCliError(message, errorType)
 }
@@ -0,0 +1,100 @@
+/*
+Copyright © 2022 Websublime.dev organization@websublime.dev
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/websublime/sublime-cli/devserver"
+	"github.com/websublime/sublime-cli/utils"
+)
+
+type DevServerFlags struct {
+	Host         string `json:"host"`
+	Port         int    `json:"port"`
+	DataDir      string `json:"data"`
+	Seed         string `json:"seed"`
+	Organization string `json:"organization"`
+	ApiKey       string `json:"api_key"`
+	ServiceKey   string `json:"service_key"`
+}
+
+func init() {
+	devServerFlags := &DevServerFlags{}
+	devServerCmd := NewDevServerCmd(devServerFlags)
+
+	devServerCmd.Flags().StringVar(&devServerFlags.Host, utils.CommandFlagDevServerHost, "127.0.0.1", utils.MessageCommandDevServerHost)
+	devServerCmd.Flags().IntVar(&devServerFlags.Port, utils.CommandFlagDevServerPort, 54321, utils.MessageCommandDevServerPort)
+	devServerCmd.Flags().StringVar(&devServerFlags.DataDir, utils.CommandFlagDevServerData, "", utils.MessageCommandDevServerData)
+	devServerCmd.Flags().StringVar(&devServerFlags.Seed, utils.CommandFlagDevServerSeed, "", utils.MessageCommandDevServerSeed)
+	devServerCmd.Flags().StringVar(&devServerFlags.Organization, utils.CommandFlagWorkspaceOrganization, "", utils.MessageCommandDevServerOrganization)
+	devServerCmd.Flags().StringVar(&devServerFlags.ApiKey, utils.CommandFlagDevServerApiKey, devserver.DefaultApiKey, utils.MessageCommandDevServerApiKey)
+	devServerCmd.Flags().StringVar(&devServerFlags.ServiceKey, utils.CommandFlagDevServerServiceKey, devserver.DefaultServiceKey, utils.MessageCommandDevServerServiceKey)
+
+	rootCommand.AddCommand(devServerCmd)
+}
+
+func NewDevServerCmd(cmdDevServer *DevServerFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:         utils.CommandDevServer,
+		Annotations: map[string]string{authorAnnotation: authorNone},
+		Short:       utils.MessageCommandDevServerShort,
+		Long:        utils.MessageCommandDevServerLong,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdDevServer.Run(cmd)
+		},
+	}
+}
+
+func (ctx *DevServerFlags) Run(cmd *cobra.Command) error {
+	server := devserver.NewServer()
+	server.ApiKey = ctx.ApiKey
+	server.ServiceKey = ctx.ServiceKey
+	server.DataDir = ctx.DataDir
+	server.Organization = ctx.Organization
+
+	if err := server.Load(); err != nil {
+		return utils.NewCliError(err.Error(), utils.ErrorReadFile)
+	}
+
+	if ctx.Seed != "" {
+		if err := server.Seed(ctx.Seed); err != nil {
+			return utils.NewCliError(err.Error(), utils.ErrorReadFile)
+		}
+	}
+
+	address := net.JoinHostPort(ctx.Host, strconv.Itoa(ctx.Port))
+	endpoint := fmt.Sprintf("http://%s", address)
+
+	utils.SuccessOut(fmt.Sprintf(utils.MessageCommandDevServerListening, endpoint))
+	utils.InfoOut(fmt.Sprintf(utils.MessageCommandDevServerKeys, ctx.ApiKey, ctx.ServiceKey))
+	utils.InfoOut(fmt.Sprintf(utils.MessageCommandDevServerUsage, endpoint, ctx.ApiKey, ctx.ServiceKey))
+
+	if err := server.ListenAndServe(address); err != nil {
+		return utils.NewCliError(err.Error(), utils.ErrorCmdExecution)
+	}
+
+	return nil
+}
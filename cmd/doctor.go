@@ -0,0 +1,321 @@
+/*
+Copyright © 2022 Websublime.dev organization@websublime.dev
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+	"github.com/websublime/sublime-cli/api"
+	"github.com/websublime/sublime-cli/core"
+	"github.com/websublime/sublime-cli/models"
+	"github.com/websublime/sublime-cli/utils"
+)
+
+type DoctorFlags struct {
+	Offline bool                 `json:"offline"`
+	Result  *models.DoctorResult `json:"-"`
+}
+
+func init() {
+	doctorFlags := &DoctorFlags{}
+	doctorCmd := NewDoctorCmd(doctorFlags)
+
+	doctorCmd.Flags().BoolVar(&doctorFlags.Offline, utils.CommandFlagDoctorOffline, false, utils.MessageCommandDoctorOffline)
+
+	rootCommand.AddCommand(doctorCmd)
+}
+
+func NewDoctorCmd(cmdDoctor *DoctorFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:         utils.CommandDoctor,
+		Annotations: map[string]string{authorAnnotation: authorNone},
+		Short:       utils.MessageCommandDoctorShort,
+		Long:        utils.MessageCommandDoctorLong,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdDoctor.Doctor(cmd)
+		},
+	}
+}
+
+// Add records the checks, counting the errors and warnings.
+func (ctx *DoctorFlags) Add(checks ...models.DoctorCheck) {
+	for _, check := range checks {
+		switch check.Status {
+		case utils.DoctorError:
+			ctx.Result.Errors++
+		case utils.DoctorWarning:
+			ctx.Result.Warnings++
+		}
+
+		ctx.Result.Checks = append(ctx.Result.Checks, check)
+	}
+}
+
+// Skip records the checks of group that could not run.
+func (ctx *DoctorFlags) Skip(group string, name string, reason string) {
+	ctx.Add(models.DoctorCheck{Group: group, Name: name, Status: utils.DoctorSkipped, Message: reason})
+}
+
+// NetworkContext bounds each network check by the api timeout, so an
+// unreachable host does not hang the report.
+func (ctx *DoctorFlags) NetworkContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(commandContext(), core.GetConfig().Timeout)
+}
+
+func (ctx *DoctorFlags) Doctor(cmd *cobra.Command) error {
+	config := core.GetConfig()
+	ctx.Result = &models.DoctorResult{Checks: []models.DoctorCheck{}}
+
+	var sublime *models.SublimeJsonFileProps
+	if config.HasSublime() {
+		sublime, _ = core.GetApp().ReadSublime()
+	}
+
+	ctx.Environment(sublime)
+	ctx.Templates(sublime)
+	author := ctx.Author()
+	ctx.Cloud(author, sublime)
+	ctx.Workspace(sublime)
+
+	result := ctx.Result
+
+	render(result, func() {
+		tabular := table.NewWriter()
+		tabular.SetStyle(table.StyleBold)
+		tabular.AppendHeader(table.Row{"Group", "Check", "Status", "Details"})
+
+		for _, check := range result.Checks {
+			tabular.AppendRow(table.Row{check.Group, check.Name, check.Status, check.Message})
+		}
+
+		fmt.Println(tabular.Render())
+
+		for _, check := range result.Checks {
+			if check.Hint != "" {
+				utils.InfoOut(fmt.Sprintf(utils.MessageCommandDoctorHint, check.Name, check.Hint))
+			}
+		}
+	})
+
+	switch {
+	case result.Errors > 0:
+		return utils.NewCliError(fmt.Sprintf(utils.MessageErrorCommandDoctorFailed, result.Errors), utils.ErrorDoctorFailed)
+	case result.Warnings > 0:
+		utils.WarningOut(fmt.Sprintf(utils.MessageCommandDoctorWarnings, result.Warnings))
+	default:
+		utils.SuccessOut(utils.MessageCommandDoctorOk)
+	}
+
+	return nil
+}
+
+// Environment checks node, the package manager of the workspace (yarn
+// outside workspaces) and git.
+func (ctx *DoctorFlags) Environment(sublime *models.SublimeJsonFileProps) {
+	config := core.GetConfig()
+	c := commandContext()
+
+	configured := ""
+	if sublime != nil {
+		configured = sublime.PackageManager
+	}
+
+	manager := core.DetectPackageManager(config.RootDir, configured)
+	minimum := map[utils.PackageManagerType]string{
+		utils.Yarn:      utils.MinimumYarn,
+		utils.YarnBerry: utils.MinimumYarnBerry,
+		utils.Npm:       utils.MinimumNpm,
+		utils.Pnpm:      utils.MinimumPnpm,
+	}[manager.Type]
+
+	managerCheck := config.CheckTool(c, manager.Command(), minimum, true, fmt.Sprintf(utils.MessageCommandDoctorHintPackageManager, manager.Type, minimum))
+	managerCheck.Name = string(manager.Type)
+
+	ctx.Add(
+		config.CheckTool(c, "node", utils.MinimumNode, true, fmt.Sprintf(utils.MessageCommandDoctorHintNode, utils.MinimumNode)),
+		managerCheck,
+		config.CheckTool(c, "git", utils.MinimumGit, false, fmt.Sprintf(utils.MessageCommandDoctorHintGit, utils.MinimumGit)),
+	)
+}
+
+// Templates checks the access to the git repos of the template sources, ssh
+// urls with the ssh agent and https urls with their tokenEnv.
+func (ctx *DoctorFlags) Templates(sublime *models.SublimeJsonFileProps) {
+	if ctx.Offline {
+		ctx.Skip(utils.DoctorGroupTemplates, "git", utils.MessageCommandDoctorSkippedOffline)
+		return
+	}
+
+	checked := []string{}
+
+	for _, source := range core.GetConfig().TemplateSources(sublime) {
+		link := strings.SplitN(source.Source, "#", 2)[0]
+		if core.TemplateSourceKindOf(source.Source) != utils.SourceGit || utils.Contains(checked, link) {
+			continue
+		}
+		checked = append(checked, link)
+
+		token := ""
+		if source.TokenEnv != "" {
+			token = os.Getenv(source.TokenEnv)
+		}
+
+		check := models.DoctorCheck{Group: utils.DoctorGroupTemplates, Name: source.Name, Status: utils.DoctorOk, Message: fmt.Sprintf("%s %s", link, utils.MessageCommandDoctorAccess)}
+
+		c, cancel := ctx.NetworkContext()
+		if err := core.CheckGitRemote(c, link, token); err != nil {
+			check.Status, check.Message, check.Hint = utils.DoctorError, err.Error(), utils.MessageCommandDoctorHintHttps
+			if strings.HasPrefix(link, "ssh://") || strings.HasPrefix(link, "git@") {
+				check.Hint = utils.MessageCommandDoctorHintSsh
+			}
+		}
+		cancel()
+
+		ctx.Add(check)
+	}
+}
+
+// Author checks the author file of the profile, returning the author when it
+// can be read.
+func (ctx *DoctorFlags) Author() *models.AuthorFileProps {
+	author, checks := core.GetConfig().CheckAuthorFile(time.Now())
+	ctx.Add(checks...)
+
+	return author
+}
+
+// Cloud checks the api answers and the author is a member of the
+// organization of the workspace (or the one selected with "org use").
+func (ctx *DoctorFlags) Cloud(author *models.AuthorFileProps, sublime *models.SublimeJsonFileProps) {
+	if ctx.Offline {
+		ctx.Skip(utils.DoctorGroupCloud, "api", utils.MessageCommandDoctorSkippedOffline)
+		ctx.Skip(utils.DoctorGroupCloud, "organization", utils.MessageCommandDoctorSkippedOffline)
+		return
+	}
+
+	token := ""
+	if author != nil {
+		token = author.Token
+	}
+
+	supabase := api.NewSupabase(utils.ApiUrl, utils.ApiKey, token, "production")
+
+	c, cancel := ctx.NetworkContext()
+	health, err := supabase.Health(c)
+	cancel()
+
+	check := models.DoctorCheck{Group: utils.DoctorGroupCloud, Name: "api", Status: utils.DoctorOk, Message: fmt.Sprintf(utils.MessageCommandDoctorApi, utils.ApiUrl, health.Version)}
+	if err != nil {
+		check.Status, check.Message, check.Hint = utils.DoctorError, fmt.Sprintf(utils.MessageCommandDoctorApi, utils.ApiUrl, err.Error()), utils.MessageCommandDoctorHintApi
+	}
+	ctx.Add(check)
+
+	organization := core.GetConfig().DefaultOrganization()
+	if sublime != nil && sublime.Organization != "" {
+		organization = sublime.Organization
+	}
+
+	switch {
+	case err != nil:
+		ctx.Skip(utils.DoctorGroupCloud, "organization", utils.MessageCommandDoctorSkippedApi)
+		return
+	case author == nil || author.Token == "":
+		ctx.Skip(utils.DoctorGroupCloud, "organization", utils.MessageCommandDoctorNoAuthor)
+		return
+	case time.Now().After(time.Unix(author.Expire, 0)):
+		ctx.Skip(utils.DoctorGroupCloud, "organization", utils.MessageCommandDoctorSkippedToken)
+		return
+	case organization == "":
+		ctx.Add(models.DoctorCheck{Group: utils.DoctorGroupCloud, Name: "organization", Status: utils.DoctorWarning, Message: utils.MessageCommandDoctorNoOrganization, Hint: utils.MessageCommandDoctorHintOrganizationUse})
+		return
+	}
+
+	check = models.DoctorCheck{Group: utils.DoctorGroupCloud, Name: "organization", Status: utils.DoctorOk, Message: fmt.Sprintf(utils.MessageCommandDoctorMember, organization)}
+
+	c, cancel = ctx.NetworkContext()
+	member, err := supabase.ValidateUserOrganization(c, author.ID, organization)
+	cancel()
+
+	switch {
+	case err != nil:
+		check.Status, check.Message, check.Hint = utils.DoctorError, err.Error(), utils.MessageCommandDoctorHintLogin
+	case !member:
+		check.Status, check.Message, check.Hint = utils.DoctorError, fmt.Sprintf(utils.MessageCommandDoctorNotMember, organization), fmt.Sprintf(utils.MessageCommandDoctorHintOrganization, organization)
+	}
+
+	ctx.Add(check)
+}
+
+// Workspace checks .sublime.json against the schema, the packages it lists,
+// the tsconfig references and the cli version of the workflows.
+func (ctx *DoctorFlags) Workspace(sublime *models.SublimeJsonFileProps) {
+	config := core.GetConfig()
+
+	if !config.HasSublime() {
+		ctx.Skip(utils.DoctorGroupWorkspace, ".sublime.json", utils.MessageCommandDoctorNoWorkspace)
+		return
+	}
+
+	store := core.GetApp().Store()
+	check := models.DoctorCheck{Group: utils.DoctorGroupWorkspace, Name: ".sublime.json", Status: utils.DoctorOk, Message: fmt.Sprintf(utils.MessageCommandDoctorSublimeValid, store.Path)}
+
+	doc, err := store.Document()
+	if err == nil {
+		var applied []core.SublimeMigration
+		var violations []models.SchemaViolation
+
+		version := core.SchemaVersionOf(doc)
+		if applied, err = core.MigrateSublime(doc); err == nil {
+			violations, err = core.ValidateSublime(doc)
+		}
+
+		switch {
+		case err != nil:
+		case len(violations) > 0:
+			check.Status, check.Message = utils.DoctorError, fmt.Sprintf(utils.MessageCommandDoctorSublimeInvalid, len(violations), store.Path)
+		case len(applied) > 0:
+			check.Status, check.Message = utils.DoctorWarning, fmt.Sprintf(utils.MessageCommandConfigOutdated, store.Path, version, utils.SublimeSchemaVersion)
+		}
+	}
+	if err != nil {
+		check.Status, check.Message = utils.DoctorError, fmt.Sprintf("%s %s", err.Error(), store.Path)
+	}
+	if check.Status != utils.DoctorOk {
+		check.Hint = utils.MessageCommandDoctorHintSublime
+	}
+
+	ctx.Add(check)
+
+	if sublime == nil {
+		return
+	}
+
+	ctx.Add(config.CheckPackages(sublime.Packages)...)
+	ctx.Add(config.CheckTsconfig(sublime.Packages))
+	ctx.Add(config.CheckWorkflows(Version)...)
+}
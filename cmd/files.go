@@ -0,0 +1,178 @@
+/*
+Copyright © 2022 Websublime.dev organization@websublime.dev
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/websublime/sublime-cli/core"
+	"github.com/websublime/sublime-cli/models"
+	"github.com/websublime/sublime-cli/utils"
+)
+
+// TemplateFile is an embedded template rendered to Target (relative to the
+// workspace dir) with Props, using the Open/Close delimiters.
+type TemplateFile struct {
+	Template string
+	Target   string
+	Props    interface{}
+	Open     string
+	Close    string
+}
+
+// WorkflowTemplates are the github workflows of a workspace, running the
+// commands of its package manager.
+func WorkflowTemplates(organization string, manager *core.PackageManager) []TemplateFile {
+	app := core.GetApp()
+	commands := manager.FileProps()
+
+	return []TemplateFile{
+		{
+			Template: "templates/workflow-release.yaml",
+			Target:   ".github/workflows/release.yaml",
+			Props: &models.ReleaseYamlFileProps{
+				PackageManagerFileProps: commands,
+				Username:                app.Author.Username,
+				Email:                   app.Author.Email,
+				Scope:                   fmt.Sprintf("@%s", organization),
+			},
+			Open:  "[[",
+			Close: "]]",
+		},
+		{
+			Template: "templates/workflow-feature.yaml",
+			Target:   ".github/workflows/feature.yaml",
+			Props:    &models.ArtifactsYamlFileProps{PackageManagerFileProps: commands, Version: Version},
+			Open:     "[[",
+			Close:    "]]",
+		},
+		{
+			Template: "templates/workflow-artifact.yaml",
+			Target:   ".github/workflows/artifact.yaml",
+			Props:    &models.ArtifactsYamlFileProps{PackageManagerFileProps: commands, Version: Version},
+			Open:     "[[",
+			Close:    "]]",
+		},
+		{
+			Template: "templates/workflow-snapshot.yaml",
+			Target:   ".github/workflows/snapshot.yaml",
+			Props: &models.SnapshotsYamlFileProps{
+				PackageManagerFileProps: commands,
+				Version:                 Version,
+				Username:                app.Author.Username,
+				Email:                   app.Author.Email,
+				Scope:                   fmt.Sprintf("@%s", organization),
+			},
+			Open:  "[[",
+			Close: "]]",
+		},
+	}
+}
+
+// ChangesetTemplate is the changeset config of a workspace.
+func ChangesetTemplate(repo string) TemplateFile {
+	return TemplateFile{
+		Template: "templates/changeset-config.json",
+		Target:   ".changeset/config.json",
+		Props:    &models.PackageJsonFileProps{Namespace: repo},
+		Open:     "{{",
+		Close:    "}}",
+	}
+}
+
+// WriteTemplateFile renders the template into dir, the workspace root, and
+// records it for "sublime upgrade". Existing files are kept unless force is
+// true.
+func WriteTemplateFile(dir string, file TemplateFile, force bool) (utils.FileStatus, error) {
+	content, err := FileTemplates.ReadFile(file.Template)
+	if err != nil {
+		return "", err
+	}
+
+	generated := models.GeneratedFile{
+		Path:       file.Target,
+		Template:   file.Template,
+		Delimiters: []string{file.Open, file.Close},
+		Variables:  templateVariables(file.Props),
+		Version:    Version,
+	}
+
+	rendered, err := core.RenderTemplateString(string(content), generated.Delimiters, generated.Variables)
+	if err != nil {
+		return "", err
+	}
+
+	status, err := WriteFile(filepath.Join(dir, file.Target), []byte(rendered), force)
+	if err != nil || status == utils.FileSkipped {
+		return status, err
+	}
+
+	generated.Hash = core.HashContent([]byte(rendered))
+
+	return status, core.RecordGeneratedFiles(dir, generated)
+}
+
+// templateVariables are the fields of template props set as variables.
+func templateVariables(props interface{}) map[string]string {
+	fields := map[string]interface{}{}
+	if data, err := json.Marshal(props); err == nil {
+		json.Unmarshal(data, &fields)
+	}
+
+	variables := map[string]string{}
+	for name, value := range fields {
+		if value != nil && value != "" {
+			variables[name] = fmt.Sprint(value)
+		}
+	}
+
+	return variables
+}
+
+// WriteFile writes data to target, creating the parent folders. Existing
+// files are kept unless force is true.
+func WriteFile(target string, data []byte, force bool) (utils.FileStatus, error) {
+	status := utils.FileCreated
+
+	if _, err := os.Stat(target); err == nil {
+		if !force {
+			return utils.FileSkipped, nil
+		}
+		status = utils.FileOverwritten
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return "", err
+	}
+
+	if err := os.WriteFile(target, data, 0644); err != nil {
+		return "", err
+	}
+
+	return status, nil
+}
@@ -0,0 +1,171 @@
+/*
+Copyright © 2022 Websublime.dev organization@websublime.dev
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/websublime/sublime-cli/api"
+	"github.com/websublime/sublime-cli/core"
+	"github.com/websublime/sublime-cli/devserver"
+	"github.com/websublime/sublime-cli/models"
+	"github.com/websublime/sublime-cli/utils"
+)
+
+// run executes the cli with args, like Execute does without exiting.
+func run(t *testing.T, stdin string, args ...string) error {
+	t.Helper()
+
+	osArgs := os.Args
+	os.Args = append([]string{utils.CommandRoot}, args...)
+	t.Cleanup(func() { os.Args = osArgs })
+
+	rootCommand.SetArgs(args)
+	rootCommand.SetIn(strings.NewReader(stdin))
+
+	return rootCommand.ExecuteContext(context.Background())
+}
+
+// writeFile writes content to root/name, creating its folder.
+func writeFile(t *testing.T, root string, name string, content string) {
+	t.Helper()
+
+	path := filepath.Join(root, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestFlow runs login, org create and create against the dev server, as an
+// author does on a new workspace.
+func TestFlow(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the package manager is replaced by a shell script")
+	}
+
+	server := devserver.NewServer()
+	listener := httptest.NewServer(server)
+	t.Cleanup(listener.Close)
+
+	config := core.GetConfig()
+	homeDir, rootDir := config.HomeDir, config.RootDir
+	t.Cleanup(func() { config.HomeDir, config.RootDir = homeDir, rootDir })
+	config.HomeDir = t.TempDir()
+
+	t.Setenv(core.EnvApiUrl, listener.URL)
+	t.Setenv(core.EnvApiKey, server.ApiKey)
+
+	// Installs are not part of the flow, npm is a no-op.
+	bin := t.TempDir()
+	writeFile(t, bin, "npm", "#!/bin/sh\nexit 0\n")
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	c := context.Background()
+	anon := api.NewSupabase(listener.URL, server.ApiKey, server.ApiKey, "production")
+	user, err := anon.RegisterAuthor(c, "dev", "dev", "dev@acme.test", "secret123")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := run(t, "secret123\n", utils.CommandLogin, "--quiet", "--email", "dev@acme.test", "--password-stdin"); err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	if _, err := os.Stat(config.AuthorFile()); err != nil {
+		t.Fatalf("login did not write the author file: %v", err)
+	}
+
+	if err := run(t, "", utils.CommandOrg, utils.CommandOrgCreate, "acme", "--quiet"); err != nil {
+		t.Fatalf("org create: %v", err)
+	}
+
+	owner := false
+	for _, row := range server.Rows("organization_users") {
+		owner = owner || (fmt.Sprint(row["user_id"]) == user.ID && row["role"] == string(utils.RoleOwner))
+	}
+	if !owner {
+		t.Fatalf("org create did not make dev the owner: %v", server.Rows("organization_users"))
+	}
+	if buckets := server.Rows("buckets"); len(buckets) != 1 || buckets[0]["name"] != "acme" {
+		t.Fatalf("org create buckets = %v, want acme", buckets)
+	}
+
+	// The workspace command scaffolds from a remote template, so the
+	// workspace is registered directly.
+	login, err := anon.Login(c, "dev@acme.test", "secret123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	author := api.NewSupabase(listener.URL, server.ApiKey, login.Token, "production")
+
+	orgs, err := author.GetOrganizationByUser(c, user.ID)
+	if err != nil || len(orgs) != 1 {
+		t.Fatalf("organizations: %v %v", orgs, err)
+	}
+	workspaces, err := author.CreateOrganizationWorkspace(c, "mono", "", "", orgs[0].Organization.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root := t.TempDir()
+	writeFile(t, root, ".sublime.json", fmt.Sprintf(`{"name": "mono", "namespace": "@acme/mono", "root": "./", "organization": "acme", "id": %q, "packageManager": "npm", "packages": []}`, workspaces[0].ID))
+	writeFile(t, root, "package.json", `{"name": "@acme/mono", "private": true, "workspaces": ["packages/*", "libs/*"]}`)
+	writeFile(t, root, "tsconfig.base.json", `{"compilerOptions": {}, "references": []}`)
+
+	template := t.TempDir()
+	writeFile(t, template, "package.json", `{"name": "{{.Name}}", "version": "0.0.0"}`)
+
+	err = run(t, "", utils.CommandCreate, "--quiet", "--root", root, "--name", "ui", "--type", string(utils.Package), "--description", "UI kit", "--template", template)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	packages := server.Rows("packages")
+	if len(packages) != 1 || packages[0]["name"] != "ui" || packages[0]["workspace_id"] != workspaces[0].ID {
+		t.Fatalf("create packages = %v, want ui on %s", packages, workspaces[0].ID)
+	}
+
+	data, err := os.ReadFile(filepath.Join(root, ".sublime.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sublime := models.SublimeJsonFileProps{}
+	if err := json.Unmarshal(data, &sublime); err != nil {
+		t.Fatal(err)
+	}
+	if len(sublime.Packages) != 1 || sublime.Packages[0].ID != packages[0]["id"] {
+		t.Errorf(".sublime.json packages = %+v, want ui with id %v", sublime.Packages, packages[0]["id"])
+	}
+	if _, err := os.Stat(filepath.Join(root, "packages", "ui", "package.json")); err != nil {
+		t.Errorf("create did not render the package: %v", err)
+	}
+}
@@ -0,0 +1,441 @@
+/*
+Copyright © 2022 Websublime.dev organization@websublime.dev
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gosimple/slug"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+	"github.com/websublime/sublime-cli/api"
+	"github.com/websublime/sublime-cli/core"
+	"github.com/websublime/sublime-cli/models"
+	"github.com/websublime/sublime-cli/utils"
+)
+
+type InitFlags struct {
+	Organization string                      `json:"organization"`
+	Name         string                      `json:"name"`
+	Repo         string                      `json:"repo"`
+	Description  string                      `json:"description"`
+	Force        bool                        `json:"force"`
+	Sublime      models.SublimeJsonFileProps `json:"-"`
+	Detected     []models.DetectedPackage    `json:"-"`
+	Files        []models.FileResult         `json:"-"`
+}
+
+func init() {
+	initFlags := &InitFlags{}
+	initCmd := NewInitCmd(initFlags)
+
+	initCmd.Flags().StringVar(&initFlags.Organization, utils.CommandFlagWorkspaceOrganization, "", utils.MessageCommandWorkspaceOrganization)
+	initCmd.Flags().StringVar(&initFlags.Name, utils.CommandFlagInitName, "", utils.MessageCommandInitName)
+	initCmd.Flags().StringVar(&initFlags.Repo, utils.CommandFlagInitRepo, "", utils.MessageCommandInitRepo)
+	initCmd.Flags().StringVar(&initFlags.Description, utils.CommandFlagInitDescription, "", utils.MessageCommandInitDescription)
+	initCmd.Flags().BoolVar(&initFlags.Force, utils.CommandFlagInitForce, false, utils.MessageCommandInitForce)
+
+	rootCommand.AddCommand(initCmd)
+}
+
+func NewInitCmd(cmdInit *InitFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   utils.CommandInit,
+		Short: utils.MessageCommandInitShort,
+		Long:  utils.MessageCommandInitLong,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			app := core.GetApp()
+			config := core.GetConfig()
+
+			if cmdInit.Organization == "" {
+				cmdInit.Organization = config.DefaultOrganization()
+			}
+
+			if cmdInit.Organization == "" {
+				return utils.NewCliError(utils.MessageErrorCommandOrgMissing, utils.ErrorInvalidFlag)
+			}
+
+			if strings.HasPrefix(cmdInit.Organization, "@") {
+				return utils.NewCliError(utils.MessageErrorCommandWorkspaceInvalidNamespace, utils.ErrorInvalidFlag)
+			}
+
+			if config.HasSublime() && !cmdInit.Force {
+				return utils.NewCliError(utils.MessageErrorCommandInitExists, utils.ErrorInvalidWorkspace)
+			}
+
+			supabase := api.NewSupabase(utils.ApiUrl, utils.ApiKey, app.Author.Token, "production")
+			isUserOrganization, err := supabase.ValidateUserOrganization(cmd.Context(), app.Author.ID, cmdInit.Organization)
+			if err != nil {
+				return utils.NewCliError(err.Error(), utils.ErrorInvalidOrganization)
+			}
+
+			if !isUserOrganization {
+				return utils.NewCliError(utils.MessageErrorCommandWorkspaceInvalidOrganization, utils.ErrorInvalidOrganization)
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if err := cmdInit.Detect(); err != nil {
+				return err
+			}
+
+			if err := cmdInit.GenerateFiles(); err != nil {
+				return err
+			}
+
+			if err := cmdInit.CreateCloudWorkspace(cmd); err != nil {
+				return err
+			}
+
+			cmdInit.Report()
+
+			return nil
+		},
+	}
+}
+
+// Detect resolves the workspace metadata and its packages. With --force the
+// ids of an existing .sublime.json are kept, so the cloud rows are reused.
+func (ctx *InitFlags) Detect() error {
+	config := core.GetConfig()
+	scope := fmt.Sprintf("@%s", ctx.Organization)
+
+	manifest, err := core.ReadPackageManifest(filepath.Join(config.RootDir, "package.json"))
+	if err != nil {
+		manifest = &models.PackageManifest{}
+	}
+
+	if ctx.Name == "" {
+		parts := strings.Split(manifest.Name, "/")
+		ctx.Name = parts[len(parts)-1]
+	}
+	if ctx.Name == "" {
+		ctx.Name = filepath.Base(config.RootDir)
+	}
+	ctx.Name = slug.Make(ctx.Name)
+
+	if ctx.Repo == "" {
+		if repo, err := core.OpenGitRepo(config.RootDir); err == nil {
+			ctx.Repo, _ = repo.RemoteRepo("origin")
+		}
+	}
+	if ctx.Repo == "" {
+		return utils.NewCliError(utils.MessageErrorCommandInitRepo, utils.ErrorInvalidGit)
+	}
+
+	if ctx.Description == "" {
+		ctx.Description = manifest.Description
+	}
+
+	existing := &models.SublimeJsonFileProps{}
+	if sublime, err := core.GetApp().ReadSublime(); err == nil {
+		existing = sublime
+	}
+
+	ctx.Detected, err = config.DetectPackages(scope)
+	if err != nil {
+		return utils.NewCliError(err.Error(), utils.ErrorReadFile)
+	}
+
+	packages := []models.SublimePackages{}
+	for idx := range ctx.Detected {
+		for _, pkg := range existing.Packages {
+			if pkg.Name == ctx.Detected[idx].Package.Name {
+				ctx.Detected[idx].Package.ID = pkg.ID
+			}
+		}
+		packages = append(packages, ctx.Detected[idx].Package)
+	}
+
+	ctx.Sublime = models.SublimeJsonFileProps{
+		Name:           ctx.Name,
+		Repo:           ctx.Repo,
+		Namespace:      strings.Join([]string{scope, ctx.Name}, "/"),
+		Root:           "./",
+		Organization:   ctx.Organization,
+		ID:             existing.ID,
+		Description:    ctx.Description,
+		Packages:       packages,
+		PackageManager: string(core.DetectPackageManager(config.RootDir, existing.PackageManager).Type),
+	}
+
+	utils.InfoOut(fmt.Sprintf(utils.MessageCommandInitDetected, len(packages)))
+
+	return nil
+}
+
+func (ctx *InitFlags) GenerateFiles() error {
+	config := core.GetConfig()
+
+	if err := ctx.SaveSublime(); err != nil {
+		return err
+	}
+
+	status, err := ctx.TsconfigReferences()
+	if err != nil {
+		utils.WarningOut(utils.MessageErrorCommandInitTsconfig)
+	}
+	ctx.Files = append(ctx.Files, models.FileResult{Path: "tsconfig.base.json", Status: status})
+
+	files := append([]TemplateFile{ChangesetTemplate(ctx.Repo)}, WorkflowTemplates(ctx.Organization, core.DetectPackageManager(config.RootDir, ctx.Sublime.PackageManager))...)
+
+	for _, file := range files {
+		status, err := WriteTemplateFile(config.RootDir, file, ctx.Force)
+		if err != nil {
+			return utils.NewCliError(err.Error(), utils.ErrorCreateFile)
+		}
+		ctx.Files = append(ctx.Files, models.FileResult{Path: file.Target, Status: status})
+	}
+
+	return nil
+}
+
+// SaveSublime writes .sublime.json. It is also called after the cloud ids
+// are known.
+func (ctx *InitFlags) SaveSublime() error {
+	store := core.GetApp().Store()
+
+	status := utils.FileCreated
+	if _, err := os.Stat(store.Path); err == nil {
+		status = utils.FileOverwritten
+	}
+
+	if err := store.Save(&ctx.Sublime); err != nil {
+		return utils.NewCliError(err.Error(), utils.ErrorCreateFile)
+	}
+
+	if len(ctx.Files) == 0 {
+		ctx.Files = append(ctx.Files, models.FileResult{Path: filepath.Base(store.Path), Status: status})
+	}
+
+	return nil
+}
+
+// TsconfigReferences adds the detected packages to the references of
+// tsconfig.base.json. An existing file only gets the missing references,
+// unless --force is given, where it is generated again from the template.
+func (ctx *InitFlags) TsconfigReferences() (utils.FileStatus, error) {
+	config := core.GetConfig()
+	target := filepath.Join(config.RootDir, "tsconfig.base.json")
+	status := utils.FileCreated
+
+	data, err := os.ReadFile(target)
+	switch {
+	case err == nil && !ctx.Force:
+		status = utils.FileUpdated
+	case err == nil || errors.Is(err, os.ErrNotExist):
+		if err == nil {
+			status = utils.FileOverwritten
+		}
+
+		template, err := FileTemplates.ReadFile("templates/tsconfig-base.json")
+		if err != nil {
+			return utils.FileSkipped, err
+		}
+		data = []byte(utils.ProcessString(string(template), &models.TsConfigJsonFileProps{
+			Namespace: fmt.Sprintf("@%s/vite", ctx.Organization),
+		}, "{{", "}}"))
+	default:
+		return utils.FileSkipped, err
+	}
+
+	tsconfig := map[string]interface{}{}
+	if err := json.Unmarshal(data, &tsconfig); err != nil {
+		return utils.FileSkipped, err
+	}
+
+	references := []interface{}{}
+	known := map[string]bool{}
+	if current, ok := tsconfig["references"].([]interface{}); ok {
+		for _, reference := range current {
+			if value, ok := reference.(map[string]interface{}); ok {
+				path := filepath.Clean(fmt.Sprint(value["path"]))
+				if status != utils.FileUpdated {
+					if _, err := os.Stat(filepath.Join(config.RootDir, path)); err != nil {
+						continue
+					}
+				}
+				known[path] = true
+			}
+			references = append(references, reference)
+		}
+	}
+
+	added := 0
+	for _, detected := range ctx.Detected {
+		if known[filepath.Clean(detected.Path)] {
+			continue
+		}
+
+		references = append(references, map[string]interface{}{
+			"path": "./" + filepath.ToSlash(detected.Path),
+			"name": filepath.Join(detected.Package.Scope, detected.Package.Name),
+		})
+		added++
+	}
+
+	if status == utils.FileUpdated && added == 0 {
+		return utils.FileSkipped, nil
+	}
+
+	tsconfig["references"] = references
+
+	output, err := json.MarshalIndent(tsconfig, "", " ")
+	if err != nil {
+		return utils.FileSkipped, err
+	}
+
+	if err := os.WriteFile(target, output, 0644); err != nil {
+		return utils.FileSkipped, err
+	}
+
+	return status, nil
+}
+
+// CreateCloudWorkspace registers the workspace (unless .sublime.json already
+// had an id or the repo is registered on the organization) and every package
+// without an id. Cloud packages with the same name are linked instead. A
+// package that fails is reported and left for "sublime sync".
+func (ctx *InitFlags) CreateCloudWorkspace(cmd *cobra.Command) error {
+	app := core.GetApp()
+
+	supabase := api.NewSupabase(utils.ApiUrl, utils.ApiKey, app.Author.Token, "production")
+
+	if ctx.Sublime.ID == "" {
+		workspaces, err := supabase.GetWorkspacesByOrganization(cmd.Context(), app.OrganizationID)
+		if err != nil {
+			return utils.NewCliError(err.Error(), utils.ErrorInvalidCloudOperation)
+		}
+
+		for _, workspace := range workspaces {
+			if workspace.Repo == ctx.Repo && workspace.Name == ctx.Name {
+				ctx.Sublime.ID = workspace.ID
+			}
+		}
+	}
+
+	if ctx.Sublime.ID == "" {
+		workspaces, err := supabase.CreateOrganizationWorkspace(cmd.Context(), ctx.Name, ctx.Repo, ctx.Description, app.OrganizationID)
+		if err == nil && len(workspaces) == 0 {
+			err = errors.New(utils.MessageErrorCommandSyncWorkspace)
+		}
+		if err != nil {
+			return utils.NewCliError(err.Error(), utils.ErrorInvalidCloudOperation)
+		}
+
+		ctx.Sublime.ID = workspaces[0].ID
+		utils.SuccessOut(fmt.Sprintf(utils.MessageCommandInitCloud, ctx.Name, ctx.Sublime.ID))
+	}
+
+	if err := ctx.SaveSublime(); err != nil {
+		return err
+	}
+
+	cloud, err := supabase.GetPackagesByWorkspace(cmd.Context(), ctx.Sublime.ID)
+	if err != nil {
+		return utils.NewCliError(err.Error(), utils.ErrorInvalidCloudOperation)
+	}
+
+	for idx, detected := range ctx.Detected {
+		if detected.Package.ID != "" {
+			continue
+		}
+
+		pkg := detected.Package
+		for _, cloudPkg := range cloud {
+			if cloudPkg.Name == pkg.Name {
+				if err := ctx.setPackageID(idx, cloudPkg.ID); err != nil {
+					return err
+				}
+			}
+		}
+		if ctx.Detected[idx].Package.ID != "" {
+			continue
+		}
+
+		created, err := supabase.CreateWorkspacePackage(cmd.Context(), pkg.Name, pkg.Description, pkg.Type, detected.Template, ctx.Sublime.ID)
+		if err == nil && len(created) == 0 {
+			err = errors.New(utils.MessageErrorCommandSyncWorkspace)
+		}
+		if err != nil {
+			utils.WarningOut(fmt.Sprintf(utils.MessageErrorCommandInitPackage, pkg.Name, err.Error()))
+			continue
+		}
+
+		if detected.Version != "" && detected.Version != created[0].Version {
+			if _, err := supabase.UpdateWorkspacePackageVersion(cmd.Context(), created[0].ID, detected.Version); err != nil {
+				utils.WarningOut(fmt.Sprintf(utils.MessageErrorCommandInitPackage, pkg.Name, err.Error()))
+			}
+		}
+
+		if err := ctx.setPackageID(idx, created[0].ID); err != nil {
+			return err
+		}
+		utils.SuccessOut(fmt.Sprintf(utils.MessageCommandInitCloudPackage, pkg.Name, created[0].ID))
+	}
+
+	return nil
+}
+
+func (ctx *InitFlags) setPackageID(idx int, id string) error {
+	ctx.Detected[idx].Package.ID = id
+	ctx.Sublime.Packages[idx].ID = id
+
+	return ctx.SaveSublime()
+}
+
+func (ctx *InitFlags) Report() {
+	result := &models.InitResult{
+		Workspace: ctx.Sublime,
+		Files:     ctx.Files,
+		Packages:  ctx.Detected,
+	}
+
+	render(result, func() {
+		tabular := table.NewWriter()
+		tabular.SetStyle(table.StyleBold)
+		tabular.AppendHeader(table.Row{"File", "Status"})
+
+		for _, file := range ctx.Files {
+			tabular.AppendRow(table.Row{file.Path, file.Status})
+		}
+
+		packages := table.NewWriter()
+		packages.SetStyle(table.StyleBold)
+		packages.AppendHeader(table.Row{"Package", "Path", "Template", "Version", "ID"})
+
+		for _, detected := range ctx.Detected {
+			packages.AppendRow(table.Row{filepath.Join(detected.Package.Scope, detected.Package.Name), detected.Path, detected.Template, detected.Version, detected.Package.ID})
+		}
+
+		fmt.Println(tabular.Render())
+		fmt.Println(packages.Render())
+	})
+
+	utils.SuccessOut(utils.MessageCommandInitSuccess)
+}
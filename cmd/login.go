@@ -22,8 +22,10 @@ THE SOFTWARE.
 package cmd
 
 import (
+	"bufio"
 	"encoding/base64"
 	"fmt"
+	"io"
 	"regexp"
 	"strconv"
 	"strings"
@@ -36,36 +38,38 @@ import (
 )
 
 type LoginFlags struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email         string `json:"email"`
+	Password      string `json:"password"`
+	PasswordStdin bool   `json:"password_stdin"`
 }
 
 func init() {
 	loginFlags := &LoginFlags{}
 	loginCmd := NewLoginCmd(loginFlags)
 
-	//loginCmd.Flags().StringVar(&loginFlags.Email, "email", "", "Email")
-	//loginCmd.MarkFlagRequired("email")
-
-	//loginCmd.Flags().StringVar(&loginFlags.Password, "password", "", "Password")
-	//loginCmd.MarkFlagRequired("password")
+	loginCmd.Flags().StringVar(&loginFlags.Email, utils.CommandFlagLoginEmail, "", utils.MessageCommandLoginEmail)
+	loginCmd.Flags().BoolVar(&loginFlags.PasswordStdin, utils.CommandFlagLoginPasswordStdin, false, utils.MessageCommandLoginPasswordStdin)
 
 	rootCommand.AddCommand(loginCmd)
 }
 
 func NewLoginCmd(cmdLogin *LoginFlags) *cobra.Command {
 	return &cobra.Command{
-		Use:   utils.CommandLogin,
-		Short: utils.MessageCommandLoginShort,
-		Long:  utils.MessageCommandLoginLong,
-		Run: func(cmd *cobra.Command, _ []string) {
-			cmdLogin.Run(cmd)
-			cmdLogin.LoginAuthor()
+		Use:         utils.CommandLogin,
+		Annotations: map[string]string{authorAnnotation: authorNone},
+		Short:       utils.MessageCommandLoginShort,
+		Long:        utils.MessageCommandLoginLong,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if err := cmdLogin.Run(cmd); err != nil {
+				return err
+			}
+
+			return cmdLogin.LoginAuthor()
 		},
 	}
 }
 
-func (ctx *LoginFlags) Run(cmd *cobra.Command) {
+func (ctx *LoginFlags) Run(cmd *cobra.Command) error {
 	emailContent := models.PromptContent{
 		Error: utils.MessageErrorCommandLoginEmailPrompt,
 		Label: utils.MessageCommandLoginEmailPrompt,
@@ -78,33 +82,49 @@ func (ctx *LoginFlags) Run(cmd *cobra.Command) {
 		Mask:  '*',
 	}
 
-	email, err := models.PromptGetInput(emailContent, 3)
-	if err != nil {
-		utils.ErrorOut(err.Error(), utils.ErrorPromptInvalid)
+	if ctx.Email == "" {
+		email, err := models.PromptGetInput(emailContent, 3)
+		if err != nil {
+			return utils.NewCliError(err.Error(), utils.ErrorPromptInvalid)
+		}
+		ctx.Email = email
+	}
+
+	// The password is never a flag value, so it does not end on the shell
+	// history or the process list.
+	if ctx.PasswordStdin {
+		password, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+		password = strings.TrimRight(password, "\r\n")
+		if (err != nil && err != io.EOF) || password == "" {
+			return utils.NewCliError(utils.MessageErrorCommandLoginPasswordPrompt, utils.ErrorPromptInvalid)
+		}
+		ctx.Password = password
+
+		return nil
 	}
+
 	password, err := models.PromptGetInput(passwordContent, 8)
 	if err != nil {
-		utils.ErrorOut(err.Error(), utils.ErrorPromptInvalid)
+		return utils.NewCliError(err.Error(), utils.ErrorPromptInvalid)
 	}
-
-	ctx.Email = email
 	ctx.Password = password
+
+	return nil
 }
 
-func (ctx *LoginFlags) LoginAuthor() {
+func (ctx *LoginFlags) LoginAuthor() error {
 	config := core.GetConfig()
 	app := core.GetApp()
 
-	go config.Progress.Render()
-
-	config.UpdateProgress(utils.MessageCommandLoginProgressInit, 2)
+	config.Progress.Start(3)
+	config.Progress.Step(utils.MessageCommandLoginProgressInit)
 	supabase := api.NewSupabase(utils.ApiUrl, utils.ApiKey, utils.ApiKey, "production")
-	author, err := supabase.Login(ctx.Email, ctx.Password)
+	author, err := supabase.Login(commandContext(), ctx.Email, ctx.Password)
 	if err != nil {
-		ctx.CommandError(err.Error(), utils.ErrorInvalidAuthor)
+		return ctx.CommandError(err.Error(), utils.ErrorInvalidAuthor)
 	}
 
-	config.UpdateProgress(utils.MessageCommandLoginAuthor, 2)
+	config.Progress.Step(utils.MessageCommandLoginAuthor)
 
 	tokenStrings := strings.Split(author.Token, ".")
 	claimString, _ := base64.StdEncoding.DecodeString(tokenStrings[1])
@@ -115,12 +135,11 @@ func (ctx *LoginFlags) LoginAuthor() {
 
 	author.Expires = expires
 
-	user, err := supabase.GetUser(author.Token)
+	user, err := supabase.GetUser(commandContext(), author.Token)
 	if err != nil {
-		ctx.CommandError(err.Error(), utils.ErrorInvalidAuthor)
+		return ctx.CommandError(err.Error(), utils.ErrorInvalidAuthor)
 	}
 
-	config.UpdateProgress(utils.MessageCommandLoginAuthor, 2)
 	err = app.UpdateAuthorMetadata(&models.AuthorFileProps{
 		Expire:   author.Expires,
 		Token:    author.Token,
@@ -131,16 +150,28 @@ func (ctx *LoginFlags) LoginAuthor() {
 		ID:       user.ID,
 	})
 	if err != nil {
-		ctx.CommandError(err.Error(), utils.ErrorInvalidAuthor)
+		return ctx.CommandError(err.Error(), utils.ErrorInvalidAuthor)
 	}
 
-	config.UpdateProgress(utils.MessageCommandLoginSuccess, 2)
-	config.TerminateProgress()
+	config.Progress.Step(utils.MessageCommandLoginSuccess)
+	config.Progress.Done()
+
+	utils.GetOutput().SetResult(&models.AuthorResult{
+		ID:       user.ID,
+		Name:     user.UserMetadata.Name,
+		Username: user.UserMetadata.Author,
+		Email:    user.Email,
+		Expires:  author.Expires,
+		File:     config.AuthorFile(),
+	})
+
+	return nil
 }
 
-func (ctx *LoginFlags) CommandError(message string, errorType utils.ErrorType) {
+func (ctx *LoginFlags) CommandError(message string, errorType utils.ErrorType) error {
 	config := core.GetConfig()
 
-	config.TerminateErrorProgress(fmt.Sprintf("Error: %s", errorType))
-	utils.ErrorOut(message, errorType)
+	config.Progress.Fail(fmt.Sprintf("Error: %s", errorType))
+
+	return utils.NewCliError(message, errorType)
 }
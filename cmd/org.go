@@ -0,0 +1,369 @@
+/*
+Copyright © 2022 Websublime.dev organization@websublime.dev
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gosimple/slug"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+	"github.com/websublime/sublime-cli/api"
+	"github.com/websublime/sublime-cli/core"
+	"github.com/websublime/sublime-cli/models"
+	"github.com/websublime/sublime-cli/utils"
+)
+
+type OrgFlags struct {
+	Organization string `json:"organization"`
+	Role         string `json:"role"`
+}
+
+func init() {
+	orgFlags := &OrgFlags{}
+	orgCmd := NewOrgCmd()
+
+	membersCmd := NewOrgMembersCmd(orgFlags)
+	inviteCmd := NewOrgInviteCmd(orgFlags)
+	removeCmd := NewOrgRemoveMemberCmd(orgFlags)
+
+	inviteCmd.Flags().StringVar(&orgFlags.Role, utils.CommandFlagOrgRole, string(utils.RoleMember), utils.MessageCommandOrgRole)
+
+	for _, cmd := range []*cobra.Command{membersCmd, inviteCmd, removeCmd} {
+		cmd.Flags().StringVar(&orgFlags.Organization, utils.CommandFlagOrgOrganization, "", utils.MessageCommandOrgOrganization)
+	}
+
+	orgCmd.AddCommand(NewOrgCreateCmd(orgFlags), NewOrgListCmd(orgFlags), NewOrgUseCmd(orgFlags), membersCmd, inviteCmd, removeCmd)
+	rootCommand.AddCommand(orgCmd)
+}
+
+func NewOrgCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   utils.CommandOrg,
+		Short: utils.MessageCommandOrgShort,
+		Long:  utils.MessageCommandOrgLong,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cmd.Help()
+		},
+	}
+}
+
+func NewOrgCreateCmd(cmdOrg *OrgFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   fmt.Sprintf("%s <name>", utils.CommandOrgCreate),
+		Short: utils.MessageCommandOrgCreateShort,
+		Args:  cobra.ExactArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			// The organization name is also the id of its bucket, which
+			// deploys upload to, so it must already be a slug.
+			if args[0] != slug.Make(args[0]) {
+				return utils.NewCliError(fmt.Sprintf(utils.MessageErrorCommandOrgName, args[0], slug.Make(args[0])), utils.ErrorInvalidOrganization)
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmdOrg.Create(cmd, args[0])
+		},
+	}
+}
+
+func NewOrgListCmd(cmdOrg *OrgFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   utils.CommandOrgList,
+		Short: utils.MessageCommandOrgListShort,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdOrg.List(cmd)
+		},
+	}
+}
+
+func NewOrgUseCmd(cmdOrg *OrgFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   fmt.Sprintf("%s <name>", utils.CommandOrgUse),
+		Short: utils.MessageCommandOrgUseShort,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmdOrg.Organization = args[0]
+			return cmdOrg.Use(cmd)
+		},
+	}
+}
+
+func NewOrgMembersCmd(cmdOrg *OrgFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   utils.CommandOrgMembers,
+		Short: utils.MessageCommandOrgMembersShort,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdOrg.Members(cmd)
+		},
+	}
+}
+
+func NewOrgInviteCmd(cmdOrg *OrgFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   fmt.Sprintf("%s <email>", utils.CommandOrgInvite),
+		Short: utils.MessageCommandOrgInviteShort,
+		Args:  cobra.ExactArgs(1),
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			if !utils.IsOrganizationRole(cmdOrg.Role) {
+				return utils.NewCliError(fmt.Sprintf(utils.MessageErrorCommandOrgRole, cmdOrg.Role), utils.ErrorInvalidFlag)
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmdOrg.Invite(cmd, args[0])
+		},
+	}
+}
+
+func NewOrgRemoveMemberCmd(cmdOrg *OrgFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   fmt.Sprintf("%s <email|user-id>", utils.CommandOrgRemoveMember),
+		Short: utils.MessageCommandOrgRemoveMemberShort,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmdOrg.RemoveMember(cmd, args[0])
+		},
+	}
+}
+
+// ResolveOrganization finds the --organization (or the default one) among
+// the organizations of the author.
+func (ctx *OrgFlags) ResolveOrganization(cmd *cobra.Command, supabase *api.Supabase) (models.OrganizationByUserResponse, error) {
+	app := core.GetApp()
+	config := core.GetConfig()
+
+	if ctx.Organization == "" {
+		ctx.Organization = config.DefaultOrganization()
+	}
+
+	if ctx.Organization == "" {
+		return models.OrganizationByUserResponse{}, utils.NewCliError(utils.MessageErrorCommandOrgMissing, utils.ErrorInvalidOrganization)
+	}
+
+	organizations, err := supabase.GetOrganizationByUser(cmd.Context(), app.Author.ID)
+	if err != nil {
+		return models.OrganizationByUserResponse{}, utils.NewCliError(err.Error(), utils.ErrorInvalidCloudOperation)
+	}
+
+	for _, org := range organizations {
+		if org.Organization.Name == ctx.Organization {
+			return org, nil
+		}
+	}
+
+	return models.OrganizationByUserResponse{}, utils.NewCliError(fmt.Sprintf(utils.MessageErrorCommandOrgMember, ctx.Organization), utils.ErrorInvalidOrganization)
+}
+
+func (ctx *OrgFlags) RequireOwner(org models.OrganizationByUserResponse) error {
+	if org.Role != string(utils.RoleOwner) {
+		return utils.NewCliError(fmt.Sprintf(utils.MessageErrorCommandOrgOwner, org.Organization.Name), utils.ErrorInvalidOrganization)
+	}
+
+	return nil
+}
+
+func (ctx *OrgFlags) Create(cmd *cobra.Command, name string) error {
+	app := core.GetApp()
+
+	supabase := api.NewSupabase(utils.ApiUrl, utils.ApiKey, app.Author.Token, "production")
+	org, err := supabase.CreateOrganization(cmd.Context(), name)
+	if err != nil {
+		return utils.NewCliError(err.Error(), utils.ErrorInvalidCloudOperation)
+	}
+
+	if _, err := supabase.CreateWorkspaceBucket(cmd.Context(), org.Name, true); err != nil {
+		_, _ = supabase.DeleteOrganizationByID(cmd.Context(), org.ID)
+		return utils.NewCliError(err.Error(), utils.ErrorInvalidCloudOperation)
+	}
+
+	utils.GetOutput().SetResult(&models.OrganizationCreateResult{Organization: org, Bucket: org.Name})
+	utils.SuccessOut(fmt.Sprintf(utils.MessageCommandOrgCreated, org.Name, org.Name))
+	utils.InfoOut(fmt.Sprintf(utils.MessageCommandOrgNextStep, org.Name))
+
+	return nil
+}
+
+func (ctx *OrgFlags) List(cmd *cobra.Command) error {
+	app := core.GetApp()
+	config := core.GetConfig()
+
+	supabase := api.NewSupabase(utils.ApiUrl, utils.ApiKey, app.Author.Token, "production")
+	organizations, err := supabase.GetOrganizationByUser(cmd.Context(), app.Author.ID)
+	if err != nil {
+		return utils.NewCliError(err.Error(), utils.ErrorInvalidCloudOperation)
+	}
+
+	defaultOrg := config.DefaultOrganization()
+	result := []models.OrganizationResult{}
+
+	for _, org := range organizations {
+		result = append(result, models.OrganizationResult{
+			Organization: org.Organization,
+			Role:         org.Role,
+			Selected:     org.Organization.Name == defaultOrg,
+		})
+	}
+
+	render(result, func() {
+		tabular := table.NewWriter()
+		tabular.SetStyle(table.StyleBold)
+		tabular.AppendHeader(table.Row{"", "ID", "Name", "Role", "Created"})
+
+		for _, org := range result {
+			selected := ""
+			if org.Selected {
+				selected = "*"
+			}
+
+			tabular.AppendRow(table.Row{selected, org.ID, org.Name, org.Role, org.CreatedAt})
+		}
+
+		fmt.Println(tabular.Render())
+	})
+
+	return nil
+}
+
+func (ctx *OrgFlags) Use(cmd *cobra.Command) error {
+	app := core.GetApp()
+	config := core.GetConfig()
+
+	supabase := api.NewSupabase(utils.ApiUrl, utils.ApiKey, app.Author.Token, "production")
+	org, err := ctx.ResolveOrganization(cmd, supabase)
+	if err != nil {
+		return err
+	}
+
+	if err := config.SetDefaultOrganization(org.Organization.Name); err != nil {
+		return utils.NewCliError(err.Error(), utils.ErrorCreateFile)
+	}
+
+	utils.GetOutput().SetResult(&models.OrganizationResult{Organization: org.Organization, Role: org.Role, Selected: true})
+	utils.SuccessOut(fmt.Sprintf(utils.MessageCommandOrgUsed, org.Organization.Name))
+
+	return nil
+}
+
+func (ctx *OrgFlags) Members(cmd *cobra.Command) error {
+	app := core.GetApp()
+
+	supabase := api.NewSupabase(utils.ApiUrl, utils.ApiKey, app.Author.Token, "production")
+	org, err := ctx.ResolveOrganization(cmd, supabase)
+	if err != nil {
+		return err
+	}
+
+	members, err := supabase.GetOrganizationMembers(cmd.Context(), org.Organization.ID)
+	if err != nil {
+		return utils.NewCliError(err.Error(), utils.ErrorInvalidCloudOperation)
+	}
+
+	render(members, func() {
+		tabular := table.NewWriter()
+		tabular.SetStyle(table.StyleBold)
+		tabular.AppendHeader(table.Row{"User ID", "Name", "Username", "Email", "Role", "Joined"})
+
+		for _, member := range members {
+			tabular.AppendRow(table.Row{member.UserID, member.Name, member.Username, member.Email, member.Role, member.CreatedAt})
+		}
+
+		tabular.AppendFooter(table.Row{fmt.Sprintf("Organization: %s", org.Organization.Name)})
+
+		fmt.Println(tabular.Render())
+	})
+
+	return nil
+}
+
+func (ctx *OrgFlags) Invite(cmd *cobra.Command, email string) error {
+	app := core.GetApp()
+
+	supabase := api.NewSupabase(utils.ApiUrl, utils.ApiKey, app.Author.Token, "production")
+	org, err := ctx.ResolveOrganization(cmd, supabase)
+	if err != nil {
+		return err
+	}
+
+	if err := ctx.RequireOwner(org); err != nil {
+		return err
+	}
+
+	member, err := supabase.InviteOrganizationMember(cmd.Context(), org.Organization.ID, email, ctx.Role)
+	if err != nil {
+		return utils.NewCliError(err.Error(), utils.ErrorInvalidCloudOperation)
+	}
+
+	utils.GetOutput().SetResult(member)
+	utils.SuccessOut(fmt.Sprintf(utils.MessageCommandOrgInvited, member.Email, org.Organization.Name, member.Role))
+
+	return nil
+}
+
+func (ctx *OrgFlags) RemoveMember(cmd *cobra.Command, member string) error {
+	app := core.GetApp()
+
+	supabase := api.NewSupabase(utils.ApiUrl, utils.ApiKey, app.Author.Token, "production")
+	org, err := ctx.ResolveOrganization(cmd, supabase)
+	if err != nil {
+		return err
+	}
+
+	if err := ctx.RequireOwner(org); err != nil {
+		return err
+	}
+
+	members, err := supabase.GetOrganizationMembers(cmd.Context(), org.Organization.ID)
+	if err != nil {
+		return utils.NewCliError(err.Error(), utils.ErrorInvalidCloudOperation)
+	}
+
+	userID := ""
+	for _, candidate := range members {
+		if candidate.UserID == member || strings.EqualFold(candidate.Email, member) {
+			userID = candidate.UserID
+			break
+		}
+	}
+
+	if userID == "" {
+		return utils.NewCliError(fmt.Sprintf(utils.MessageErrorCommandOrgNoUser, member, org.Organization.Name), utils.ErrorInvalidOrganization)
+	}
+
+	// Owners can not remove themselves (the same rule as the cloud policy),
+	// so the caller always stays as an owner of the organization.
+	if userID == app.Author.ID {
+		return utils.NewCliError(fmt.Sprintf(utils.MessageErrorCommandOrgRemoveSelf, org.Organization.Name), utils.ErrorInvalidOrganization)
+	}
+
+	if _, err := supabase.RemoveOrganizationMember(cmd.Context(), org.Organization.ID, userID); err != nil {
+		return utils.NewCliError(err.Error(), utils.ErrorInvalidCloudOperation)
+	}
+
+	utils.GetOutput().SetResult(&models.OrganizationUser{OrganizationID: org.Organization.ID, UserID: userID})
+	utils.SuccessOut(fmt.Sprintf(utils.MessageCommandOrgRemoved, member, org.Organization.Name))
+
+	return nil
+}
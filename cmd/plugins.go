@@ -0,0 +1,156 @@
+/*
+Copyright © 2022 Websublime.dev organization@websublime.dev
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/websublime/sublime-cli/core"
+	"github.com/websublime/sublime-cli/models"
+	"github.com/websublime/sublime-cli/utils"
+)
+
+const pluginAnnotation = "plugin"
+
+// PluginExit is the exit code of a failed plugin, the cli exits with it.
+type PluginExit int
+
+func (code PluginExit) Error() string {
+	return fmt.Sprintf("exit status %d", int(code))
+}
+
+func NewPluginCmd(plugin models.Plugin) *cobra.Command {
+	return &cobra.Command{
+		Use:                plugin.Name,
+		Short:              fmt.Sprintf(utils.MessageCommandPluginShort, plugin.Path),
+		Annotations:        map[string]string{pluginAnnotation: plugin.Path},
+		DisableFlagParsing: true,
+		// Plugins print their own output, the structured output of the cli
+		// is not flushed after them.
+		PersistentPostRun: func(cmd *cobra.Command, _ []string) {},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunPlugin(plugin, args)
+		},
+	}
+}
+
+// registerPlugins adds the sublime-<name> executables as commands, unless
+// args run a builtin command. Plugins parse their own flags, so the global
+// flags before the plugin name are parsed here.
+func registerPlugins(args []string) error {
+	name, idx := commandName(args)
+
+	if name != "" {
+		if command, _, err := rootCommand.Find([]string{name}); err == nil && command != rootCommand {
+			return nil
+		}
+	}
+
+	builtin := map[string]bool{"help": true, "completion": true}
+	for _, command := range rootCommand.Commands() {
+		builtin[command.Name()] = true
+	}
+
+	for _, plugin := range core.GetConfig().DiscoverPlugins() {
+		if !builtin[plugin.Name] {
+			rootCommand.AddCommand(NewPluginCmd(plugin))
+		}
+	}
+
+	if command, _, err := rootCommand.Find(args); err == nil && isPluginCommand(command) && idx > 0 {
+		if err := rootCommand.PersistentFlags().Parse(args[:idx]); err != nil {
+			return utils.NewCliError(fmt.Sprintf("%s %s", utils.MessageErrorCommandExecution, err.Error()), utils.ErrorCmdExecution)
+		}
+
+		rootCommand.SetArgs(args[idx:])
+	}
+
+	return nil
+}
+
+// commandName is the first argument that is not a global flag (or its value)
+// and its position on args.
+func commandName(args []string) (string, int) {
+	for idx := 0; idx < len(args); idx++ {
+		arg := args[idx]
+
+		if arg == "--" {
+			return "", -1
+		}
+
+		if !strings.HasPrefix(arg, "-") {
+			return arg, idx
+		}
+
+		if strings.Contains(arg, "=") {
+			continue
+		}
+
+		flag := rootCommand.PersistentFlags().Lookup(strings.TrimLeft(arg, "-"))
+		if flag != nil && flag.Value.Type() != "bool" {
+			idx++
+		}
+	}
+
+	return "", -1
+}
+
+func isPluginCommand(command *cobra.Command) bool {
+	return command.Annotations[pluginAnnotation] != ""
+}
+
+// RunPlugin runs the plugin with the context of the cli on the environment,
+// a failed plugin is a PluginExit of its exit code. Being logged in is
+// optional for plugins.
+func RunPlugin(plugin models.Plugin, args []string) error {
+	app := core.GetApp()
+	if app.Author == nil {
+		_ = app.InitAuthor()
+	}
+
+	command := exec.Command(plugin.Path, args...)
+	command.Stdin = os.Stdin
+	command.Stdout = os.Stdout
+	command.Stderr = os.Stderr
+	command.Env = append(os.Environ(), app.ContextEnv(Version, core.GetConfig().PluginTrusted(plugin))...)
+
+	if err := command.Run(); err != nil {
+		var exitError *exec.ExitError
+		if errors.As(err, &exitError) {
+			code := exitError.ExitCode()
+			if code < 0 {
+				code = utils.ExitCode(utils.ErrorUnknown)
+			}
+
+			return PluginExit(code)
+		}
+
+		return utils.NewCliError(fmt.Sprintf(utils.MessageErrorPluginRun, plugin.Name, err.Error()), utils.ErrorCmdExecution)
+	}
+
+	return nil
+}
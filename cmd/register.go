@@ -39,6 +39,7 @@ type RegisterFlags struct {
 	Email    string `json:"email,omitempty"`
 	Password string `json:"password,omitempty"`
 	HomeDir  string `json:"-"`
+	RcFile   string `json:"-"`
 }
 
 func init() {
@@ -50,17 +51,21 @@ func init() {
 
 func NewRegisterCommand(cmdReg *RegisterFlags) *cobra.Command {
 	return &cobra.Command{
-		Use:   utils.CommandRegister,
-		Short: utils.MessageCommandRegisterShort,
-		Long:  utils.MessageCommandRegisterLong,
-		Run: func(cmd *cobra.Command, _ []string) {
-			cmdReg.Run(cmd)
-			cmdReg.RegisterAuthor()
+		Use:         utils.CommandRegister,
+		Annotations: map[string]string{authorAnnotation: authorNone},
+		Short:       utils.MessageCommandRegisterShort,
+		Long:        utils.MessageCommandRegisterLong,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if err := cmdReg.Run(cmd); err != nil {
+				return err
+			}
+
+			return cmdReg.RegisterAuthor()
 		},
 	}
 }
 
-func (ctx *RegisterFlags) Run(cmd *cobra.Command) {
+func (ctx *RegisterFlags) Run(cmd *cobra.Command) error {
 	nameContent := models.PromptContent{
 		Error: utils.MessageErrorCommandRegisterNamePrompt,
 		Label: utils.MessageCommandRegisterNamePrompt,
@@ -87,59 +92,60 @@ func (ctx *RegisterFlags) Run(cmd *cobra.Command) {
 
 	name, err := models.PromptGetInput(nameContent, 3)
 	if err != nil {
-		utils.ErrorOut(err.Error(), utils.ErrorPromptInvalid)
+		return utils.NewCliError(err.Error(), utils.ErrorPromptInvalid)
 	}
 	username, err := models.PromptGetInput(userContent, 3)
 	if err != nil {
-		utils.ErrorOut(err.Error(), utils.ErrorPromptInvalid)
+		return utils.NewCliError(err.Error(), utils.ErrorPromptInvalid)
 	}
 	email, err := models.PromptGetInput(emailContent, 3)
 	if err != nil {
-		utils.ErrorOut(err.Error(), utils.ErrorPromptInvalid)
+		return utils.NewCliError(err.Error(), utils.ErrorPromptInvalid)
 	}
 	password, err := models.PromptGetInput(passwordContent, 8)
 	if err != nil {
-		utils.ErrorOut(err.Error(), utils.ErrorPromptInvalid)
+		return utils.NewCliError(err.Error(), utils.ErrorPromptInvalid)
 	}
 
 	ctx.Email = email
 	ctx.Name = name
 	ctx.Password = password
 	ctx.Username = username
+
+	return nil
 }
 
-func (ctx *RegisterFlags) RegisterAuthor() {
+func (ctx *RegisterFlags) RegisterAuthor() error {
 	config := core.GetConfig()
 
-	go config.Progress.Render()
-
-	config.UpdateProgress(utils.MessageCommandRegisterProgressInit, 2)
+	config.Progress.Start(4)
+	config.Progress.Step(utils.MessageCommandRegisterProgressInit)
 
 	supabase := api.NewSupabase(utils.ApiUrl, utils.ApiKey, utils.ApiKey, "production")
-	author, err := supabase.RegisterAuthor(ctx.Name, ctx.Username, ctx.Email, ctx.Password)
+	author, err := supabase.RegisterAuthor(commandContext(), ctx.Name, ctx.Username, ctx.Email, ctx.Password)
 	if err != nil {
-		ctx.CommandError(err.Error(), utils.ErrorInvalidAuthor)
+		return ctx.CommandError(err.Error(), utils.ErrorInvalidAuthor)
 	}
 
-	config.UpdateProgress(utils.MessageCommandRegisterProgressAuthor, 2)
-	ctx.HomeDir = filepath.Join(config.HomeDir, ".sublime")
-	if err := os.Mkdir(ctx.HomeDir, 0755); err != nil {
-		ctx.CommandError(utils.MessageErrorCommandRegisterHomeDir, utils.ErrorCreateDirectory)
+	config.Progress.Step(utils.MessageCommandRegisterProgressAuthor)
+	ctx.HomeDir = filepath.Dir(config.AuthorFile())
+	if err := os.MkdirAll(ctx.HomeDir, 0755); err != nil {
+		return ctx.CommandError(utils.MessageErrorCommandRegisterHomeDir, utils.ErrorCreateDirectory)
 	}
 
-	config.UpdateProgress(utils.MessageCommandRegisterProgressAuthor, 2)
 	rcJson, err := FileTemplates.ReadFile("templates/rc-template.json")
 	if err != nil {
-		ctx.CommandError(utils.MessageErrorCommandRegisterReadTemplate, utils.ErrorInvalidTemplate)
+		return ctx.CommandError(utils.MessageErrorCommandRegisterReadTemplate, utils.ErrorInvalidTemplate)
 	}
 
-	config.UpdateProgress(utils.MessageCommandRegisterLocalAuthor, 2)
-	rcFile, err := os.Create(filepath.Join(ctx.HomeDir, "rc.json"))
+	config.Progress.Step(utils.MessageCommandRegisterLocalAuthor)
+	rcFile, err := os.OpenFile(config.AuthorFile(), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
 	if err != nil {
-		ctx.CommandError(utils.MessageErrorCommandRegisterReadTemplate, utils.ErrorInvalidTemplate)
+		return ctx.CommandError(utils.MessageErrorCommandRegisterReadTemplate, utils.ErrorInvalidTemplate)
 	}
+	ctx.RcFile = rcFile.Name()
 
-	config.UpdateProgress(utils.MessageCommandRegisterProgressDone, 2)
+	config.Progress.Step(utils.MessageCommandRegisterProgressDone)
 	_, err = rcFile.WriteString(utils.ProcessString(string(rcJson), &models.AuthorFileProps{
 		Name:     author.UserMetadata.Name,
 		Username: author.UserMetadata.Author,
@@ -148,20 +154,31 @@ func (ctx *RegisterFlags) RegisterAuthor() {
 		ID:       author.ID,
 	}, "{{", "}}"))
 	if err != nil {
-		ctx.CommandError(utils.MessageErrorCommandRegisterWriteTemplate, utils.ErrorInvalidTemplate)
+		return ctx.CommandError(utils.MessageErrorCommandRegisterWriteTemplate, utils.ErrorInvalidTemplate)
 	}
 
-	config.TerminateProgress()
+	config.Progress.Done()
+
+	utils.GetOutput().SetResult(&models.AuthorResult{
+		ID:       author.ID,
+		Name:     author.UserMetadata.Name,
+		Username: author.UserMetadata.Author,
+		Email:    author.Email,
+		File:     ctx.RcFile,
+	})
 	utils.InfoOut(utils.MessageCommandRegisterNextStep)
+
+	return nil
 }
 
-func (ctx *RegisterFlags) CommandError(message string, errorType utils.ErrorType) {
+func (ctx *RegisterFlags) CommandError(message string, errorType utils.ErrorType) error {
 	config := core.GetConfig()
 
-	if ctx.HomeDir != "" {
-		os.RemoveAll(ctx.HomeDir)
+	if ctx.RcFile != "" {
+		os.Remove(ctx.RcFile)
 	}
 
-	config.TerminateErrorProgress(fmt.Sprintf("Error: %s", errorType))
-	utils.ErrorOut(message, errorType)
+	config.Progress.Fail(fmt.Sprintf("Error: %s", errorType))
+
+	return utils.NewCliError(message, errorType)
 }
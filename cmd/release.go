@@ -0,0 +1,258 @@
+/*
+Copyright © 2022 Websublime.dev organization@websublime.dev
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+	"github.com/websublime/sublime-cli/api"
+	"github.com/websublime/sublime-cli/core"
+	"github.com/websublime/sublime-cli/models"
+	"github.com/websublime/sublime-cli/utils"
+)
+
+type ReleaseFlags struct {
+	Cloud   bool                         `json:"cloud"`
+	Sublime *models.SublimeJsonFileProps `json:"-"`
+}
+
+func init() {
+	releaseFlags := &ReleaseFlags{}
+	releaseCmd := NewReleaseCmd()
+
+	applyCmd := NewReleaseApplyCmd(releaseFlags)
+	applyCmd.Flags().BoolVar(&releaseFlags.Cloud, utils.CommandFlagReleaseCloud, false, utils.MessageCommandReleaseCloud)
+
+	releaseCmd.AddCommand(NewReleasePlanCmd(releaseFlags), applyCmd)
+	rootCommand.AddCommand(releaseCmd)
+}
+
+func NewReleaseCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:         utils.CommandRelease,
+		Annotations: map[string]string{authorAnnotation: authorCloud},
+		Short:       utils.MessageCommandReleaseShort,
+		Long:        utils.MessageCommandReleaseLong,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cmd.Help()
+		},
+	}
+}
+
+func NewReleasePlanCmd(cmdRelease *ReleaseFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   utils.CommandReleasePlan,
+		Short: utils.MessageCommandReleasePlanShort,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdRelease.ReadWorkspace()
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdRelease.Plan(cmd)
+		},
+	}
+}
+
+func NewReleaseApplyCmd(cmdRelease *ReleaseFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   utils.CommandReleaseApply,
+		Short: utils.MessageCommandReleaseApplyShort,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdRelease.ReadWorkspace()
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdRelease.Apply(cmd)
+		},
+	}
+}
+
+func (ctx *ReleaseFlags) ReadWorkspace() error {
+	sublime, err := core.GetApp().ReadSublime()
+	if err != nil {
+		return utils.NewCliError(err.Error(), utils.ErrorInvalidWorkspace)
+	}
+
+	ctx.Sublime = sublime
+
+	return nil
+}
+
+// ReadRelease reads the conventional commits of the packages and plans their
+// version bumps, like the changesets of "changeset version".
+func (ctx *ReleaseFlags) ReadRelease(graph *core.WorkspaceGraph) (*core.GitRepo, []models.ReleaseCommit, []models.Changeset, []models.VersionBump, error) {
+	config := core.GetConfig()
+
+	repo, err := core.OpenGitRepo(config.RootDir)
+	if err != nil {
+		return nil, nil, nil, nil, utils.NewCliError(err.Error(), utils.ErrorInvalidGit)
+	}
+
+	commits, changesets, err := config.ReadReleaseCommits(commandContext(), repo, graph)
+	if err != nil {
+		return nil, nil, nil, nil, utils.NewCliError(err.Error(), utils.ErrorInvalidGit)
+	}
+
+	return repo, commits, changesets, core.PlanVersions(graph, changesets, config.ReadChangesetConfig()), nil
+}
+
+func (ctx *ReleaseFlags) Plan(cmd *cobra.Command) error {
+	graph := core.GetConfig().ReadWorkspaceGraph(ctx.Sublime.Packages)
+	_, commits, _, bumps, err := ctx.ReadRelease(graph)
+	if err != nil {
+		return err
+	}
+
+	result := &models.ReleasePlanResult{Commits: commits, Bumps: bumps}
+
+	if len(bumps) == 0 {
+		utils.InfoOut(utils.MessageCommandReleaseNone)
+		utils.GetOutput().SetResult(result)
+		return nil
+	}
+
+	render(result, func() {
+		tabular := table.NewWriter()
+		tabular.SetStyle(table.StyleBold)
+		tabular.AppendHeader(table.Row{"Commit", "Package", "Since", "Type", "Bump", "Description"})
+
+		for _, commit := range commits {
+			tabular.AppendRow(table.Row{commit.Hash, commit.Package, commit.Since, commit.Type, commit.Bump, commit.Description})
+		}
+
+		fmt.Println(tabular.Render())
+		fmt.Println(versionBumpsTable(bumps))
+	})
+
+	return nil
+}
+
+func (ctx *ReleaseFlags) Apply(cmd *cobra.Command) error {
+	config := core.GetConfig()
+	graph := config.ReadWorkspaceGraph(ctx.Sublime.Packages)
+	repo, _, changesets, bumps, err := ctx.ReadRelease(graph)
+	if err != nil {
+		return err
+	}
+
+	result := &models.ReleaseApplyResult{Bumps: bumps, Files: []string{}, Tags: []string{}}
+
+	if len(bumps) == 0 {
+		utils.InfoOut(utils.MessageCommandReleaseNone)
+		utils.GetOutput().SetResult(result)
+		return nil
+	}
+
+	if !repo.HasAuthor() {
+		return utils.NewCliError(utils.MessageErrorGitAuthor, utils.ErrorInvalidGit)
+	}
+
+	// Checked before the versions are written, CommitFiles checks it again.
+	staged, err := repo.StagedFiles()
+	if err != nil {
+		return utils.NewCliError(err.Error(), utils.ErrorInvalidGit)
+	}
+	if len(staged) > 0 {
+		return utils.NewCliError(fmt.Sprintf(utils.MessageErrorGitStaged, strings.Join(staged, ", ")), utils.ErrorInvalidGit)
+	}
+
+	files, err := config.ApplyVersions(graph, changesets, bumps, config.ReadChangesetConfig())
+	if err != nil {
+		return utils.NewCliError(err.Error(), utils.ErrorCreateFile)
+	}
+	result.Files = files
+
+	paths := []string{}
+	for _, file := range files {
+		path, err := filepath.Rel(repo.Dir, filepath.Join(config.RootDir, file))
+		if err != nil {
+			return utils.NewCliError(err.Error(), utils.ErrorInvalidGit)
+		}
+		paths = append(paths, filepath.ToSlash(path))
+	}
+
+	message := utils.MessageCommandReleaseCommit + "\n"
+	for _, bump := range bumps {
+		result.Tags = append(result.Tags, fmt.Sprintf("%s@%s", bump.Name, bump.NewVersion))
+		message += fmt.Sprintf("\n- %s@%s", bump.Name, bump.NewVersion)
+	}
+
+	result.Commit, err = repo.CommitFiles(message+"\n", paths)
+	if err != nil {
+		return utils.NewCliError(err.Error(), utils.ErrorInvalidGit)
+	}
+
+	for _, tag := range result.Tags {
+		if err := repo.CreateTag(tag, result.Commit); err != nil {
+			return utils.NewCliError(err.Error(), utils.ErrorInvalidGit)
+		}
+		utils.InfoOut(fmt.Sprintf(utils.MessageCommandReleaseTagged, tag))
+	}
+
+	if ctx.Cloud {
+		if err := ctx.UpdatePackageVersions(cmd, graph, result); err != nil {
+			return err
+		}
+	}
+
+	render(result, func() {
+		fmt.Println(versionBumpsTable(bumps))
+	})
+
+	utils.SuccessOut(fmt.Sprintf(utils.MessageCommandReleaseApplied, len(bumps), result.Commit[:7]))
+
+	return nil
+}
+
+// UpdatePackageVersions updates the cloud version of the released packages,
+// failing after the loop when any of them could not be updated.
+func (ctx *ReleaseFlags) UpdatePackageVersions(cmd *cobra.Command, graph *core.WorkspaceGraph, result *models.ReleaseApplyResult) error {
+	app := core.GetApp()
+	supabase := api.NewSupabase(utils.ApiUrl, utils.ApiKey, app.Author.Token, "production")
+	failed := 0
+
+	for _, bump := range result.Bumps {
+		node := graph.Find(bump.Name)
+		if node == nil || node.Package.ID == "" {
+			utils.WarningOut(fmt.Sprintf(utils.MessageCommandReleaseNoCloudID, bump.Name))
+			continue
+		}
+
+		if _, err := supabase.UpdateWorkspacePackageVersion(cmd.Context(), node.Package.ID, bump.NewVersion); err != nil {
+			utils.WarningOut(fmt.Sprintf("%s: %s", bump.Name, err.Error()))
+			failed++
+			continue
+		}
+
+		result.Versions = append(result.Versions, models.PackageVersionResult{Name: bump.Name, Version: bump.NewVersion})
+		utils.SuccessOut(fmt.Sprintf(utils.MessageCommandActionVersionUpdate, bump.Name, bump.NewVersion))
+	}
+
+	if failed > 0 {
+		utils.GetOutput().SetResult(result)
+		return utils.NewCliError(fmt.Sprintf(utils.MessageErrorCommandReleaseVersions, failed), utils.ErrorInvalidCloudOperation)
+	}
+
+	return nil
+}
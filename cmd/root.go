@@ -22,9 +22,13 @@ THE SOFTWARE.
 package cmd
 
 import (
+	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"regexp"
 	"strconv"
@@ -41,8 +45,16 @@ import (
 )
 
 type RootFlags struct {
-	ConfigFile string `json:"config_file"`
-	Root       string `json:"root"`
+	ConfigFile     string        `json:"config_file"`
+	Root           string        `json:"root"`
+	Verbose        bool          `json:"verbose"`
+	Timeout        time.Duration `json:"timeout"`
+	CommandTimeout time.Duration `json:"command_timeout"`
+	Retries        int           `json:"retries"`
+	Profile        string        `json:"profile"`
+	Output         string        `json:"output"`
+	Quiet          bool          `json:"quiet"`
+	Progress       string        `json:"progress"`
 }
 
 // rootCmd represents the base command when called without any subcommands
@@ -50,37 +62,143 @@ var rootCommand = NewRootCommand()
 
 func NewRootCommand() *cobra.Command {
 	return &cobra.Command{
-		Use:   utils.CommandRoot,
-		Short: utils.MessageCommandRootShort,
-		Run: func(cmd *cobra.Command, args []string) {
+		Use:           utils.CommandRoot,
+		Short:         utils.MessageCommandRootShort,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
-				cmd.Help()
+				return cmd.Help()
 			}
+
+			return nil
+		},
+		PersistentPostRun: func(cmd *cobra.Command, _ []string) {
+			utils.GetOutput().Flush()
 		},
 	}
 }
 
 func Execute() {
-	if err := rootCommand.Execute(); err != nil {
-		utils.ErrorOut(utils.MessageErrorCommandExecution, utils.ErrorCmdExecution)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	err := registerPlugins(os.Args[1:])
+	if err == nil {
+		err = rootCommand.ExecuteContext(ctx)
+	}
+
+	if err == nil {
+		return
+	}
+
+	// Commands return their errors, the cli only exits here.
+	var pluginExit PluginExit
+	if errors.As(err, &pluginExit) {
+		os.Exit(int(pluginExit))
+	}
+
+	var cliError *utils.CliError
+	if errors.As(err, &cliError) {
+		utils.ErrorOut(cliError.Message, cliError.Type)
+	}
+
+	utils.ErrorOut(fmt.Sprintf("%s %s", utils.MessageErrorCommandExecution, err.Error()), utils.ErrorCmdExecution)
+}
+
+// commandContext returns the context of the running command, cancelled on interrupt.
+func commandContext() context.Context {
+	if ctx := rootCommand.Context(); ctx != nil {
+		return ctx
 	}
+
+	return context.Background()
 }
 
 func init() {
 	rootFlags := &RootFlags{}
 
-	cobra.OnInitialize(func() {
-		banner()
-		executeAuthorValidation()
-		executeTokenExpirationValidation()
-		initializeCommand(rootFlags)
-	})
+	// Set here as initialize refers to rootCommand.
+	rootCommand.PersistentPreRunE = func(cmd *cobra.Command, _ []string) error {
+		// Flags and args are valid, errors from now on are not usage errors.
+		cmd.SilenceUsage = true
+
+		return initialize(rootFlags)
+	}
 
 	rootCommand.PersistentFlags().StringVar(&rootFlags.ConfigFile, utils.CommandFlagConfig, "", utils.MessageCommandConfigUsage)
 	rootCommand.PersistentFlags().StringVar(&rootFlags.Root, utils.CommandFlagRoot, "", utils.MessageCommandRootUsage)
+	rootCommand.PersistentFlags().BoolVar(&rootFlags.Verbose, utils.CommandFlagVerbose, false, utils.MessageCommandVerboseUsage)
+	rootCommand.PersistentFlags().DurationVar(&rootFlags.Timeout, utils.CommandFlagTimeout, time.Minute, utils.MessageCommandTimeoutUsage)
+	rootCommand.PersistentFlags().DurationVar(&rootFlags.CommandTimeout, utils.CommandFlagCommandTimeout, 0, utils.MessageCommandCommandTimeoutUsage)
+	rootCommand.PersistentFlags().IntVar(&rootFlags.Retries, utils.CommandFlagRetries, 2, utils.MessageCommandRetriesUsage)
+	rootCommand.PersistentFlags().StringVar(&rootFlags.Profile, utils.CommandFlagProfile, "", utils.MessageCommandProfileUsage)
+	rootCommand.PersistentFlags().StringVar(&rootFlags.Output, utils.CommandFlagOutput, string(utils.OutputTable), utils.MessageCommandOutputUsage)
+	rootCommand.PersistentFlags().BoolVar(&rootFlags.Quiet, utils.CommandFlagQuiet, false, utils.MessageCommandQuietUsage)
+	rootCommand.PersistentFlags().StringVar(&rootFlags.Progress, utils.CommandFlagProgress, string(utils.ProgressAuto), utils.MessageCommandProgressUsage)
+}
+
+// initialize prepares the output, the config and the author before a
+// command runs.
+func initialize(rootFlags *RootFlags) error {
+	if err := initializeOutput(rootFlags); err != nil {
+		return err
+	}
+
+	banner()
+
+	if err := initializeCommand(rootFlags); err != nil {
+		return err
+	}
+
+	if err := executeAuthorValidation(); err != nil {
+		return err
+	}
+
+	return executeTokenExpirationValidation()
+}
+
+// initializeOutput applies --output and --quiet before anything is printed.
+func initializeOutput(rootFlags *RootFlags) error {
+	output := utils.GetOutput()
+
+	if command, _, err := rootCommand.Find(os.Args[1:]); err == nil {
+		output.SetCommand(command.CommandPath())
+		core.GetConfig().Command = strings.Join(strings.Fields(command.CommandPath())[1:], "-")
+	}
+
+	if !utils.IsOutputFormat(rootFlags.Output) {
+		return utils.NewCliError(fmt.Sprintf(utils.MessageErrorOutputFormat, rootFlags.Output), utils.ErrorInvalidFlag)
+	}
+
+	output.Format = utils.OutputFormat(rootFlags.Output)
+	output.Quiet = rootFlags.Quiet
+
+	if !utils.IsProgressMode(rootFlags.Progress) {
+		return utils.NewCliError(fmt.Sprintf(utils.MessageErrorProgressMode, rootFlags.Progress), utils.ErrorInvalidFlag)
+	}
+
+	// Progress is not printed with --quiet. With structured output only an
+	// explicit renderer is used, on stderr to keep stdout parsable.
+	mode := utils.ProgressMode(rootFlags.Progress)
+	var writer io.Writer = os.Stdout
+
+	switch {
+	case rootFlags.Quiet, utils.IsStructured() && mode == utils.ProgressAuto:
+		writer = io.Discard
+	case utils.IsStructured():
+		writer = os.Stderr
+	}
+
+	core.GetConfig().Progress = core.NewProgressReporter(mode, writer)
+
+	return nil
 }
 
 func banner() {
+	if utils.IsQuiet() {
+		return
+	}
+
 	banner := `
 	
 ███████╗██╗   ██╗██████╗ ██╗     ██╗███╗   ███╗███████╗     ██████╗██╗     ██╗
@@ -97,18 +215,33 @@ func banner() {
 	color.Blue.Println(fmt.Sprintf("Version: %s", Version))
 }
 
-func initializeCommand(rootFlags *RootFlags) {
+func initializeCommand(rootFlags *RootFlags) error {
 	config := core.GetConfig()
 
+	if err := core.ConfigError(); err != nil {
+		return utils.NewCliError(err.Error(), utils.ErrorMissingDirectory)
+	}
+
 	if rootFlags.Root != "" {
-		config.SetRootDir(rootFlags.Root)
+		if err := config.SetRootDir(rootFlags.Root); err != nil {
+			return utils.NewCliError(err.Error(), utils.ErrorMissingDirectory)
+		}
+	}
+
+	config.Verbose = rootFlags.Verbose
+	config.Timeout = rootFlags.Timeout
+	config.CommandTimeout = rootFlags.CommandTimeout
+	config.Retries = rootFlags.Retries
+
+	if _, err := config.ResolveEndpoint(rootFlags.Profile); err != nil {
+		return utils.NewCliError(err.Error(), utils.ErrorInvalidFlag)
 	}
 
 	if rootFlags.ConfigFile != "" {
 		viper.SetConfigFile(rootFlags.ConfigFile)
+		config.ConfigFile = rootFlags.ConfigFile
 	} else {
 		viper.AddConfigPath(config.RootDir)
-		viper.SetConfigType("json")
 		viper.SetConfigName(".sublime")
 	}
 
@@ -117,11 +250,24 @@ func initializeCommand(rootFlags *RootFlags) {
 	if err := viper.ReadInConfig(); err == nil {
 		configFile := viper.ConfigFileUsed()
 
-		config.SetRootDir(filepath.Dir(configFile))
+		if err := config.SetRootDir(filepath.Dir(configFile)); err != nil {
+			return utils.NewCliError(err.Error(), utils.ErrorMissingDirectory)
+		}
 	}
+
+	return nil
 }
 
-func executeAuthorValidation() {
+// Annotation of commands on the author they need, inherited by subcommands.
+const (
+	authorAnnotation = "author"
+	// authorNone commands never need a logged in author.
+	authorNone = "none"
+	// authorCloud commands need one only with --cloud.
+	authorCloud = "cloud"
+)
+
+func executeAuthorValidation() error {
 	flags := os.Args[1:]
 
 	if !isCommandExclude(flags) {
@@ -129,16 +275,18 @@ func executeAuthorValidation() {
 
 		err := app.InitAuthor()
 		if err != nil {
-			utils.ErrorOut(err.Error(), utils.ErrorInvalidAuthor)
+			return utils.NewCliError(err.Error(), utils.ErrorInvalidAuthor)
 		}
 
 		if app.Author.Token == "" {
-			utils.ErrorOut(utils.MessageErrorAuthorTokenMissing, utils.ErrorInvalidAuthor)
+			return utils.NewCliError(utils.MessageErrorAuthorTokenMissing, utils.ErrorInvalidAuthor)
 		}
 	}
+
+	return nil
 }
 
-func executeTokenExpirationValidation() {
+func executeTokenExpirationValidation() error {
 	flags := os.Args[1:]
 
 	if !isCommandExclude(flags) {
@@ -151,9 +299,9 @@ func executeTokenExpirationValidation() {
 			utils.InfoOut(utils.MessageCommandRootTokenExpire)
 
 			supabase := api.NewSupabase(utils.ApiUrl, utils.ApiKey, utils.ApiKey, "production")
-			refresh, err := supabase.RefreshToken(app.Author.Refresh)
+			refresh, err := supabase.RefreshToken(commandContext(), app.Author.Refresh)
 			if err != nil {
-				utils.ErrorOut(err.Error(), utils.ErrorInvalidToken)
+				return utils.NewCliError(err.Error(), utils.ErrorInvalidToken)
 			}
 
 			tokenStrings := strings.Split(refresh.Token, ".")
@@ -165,9 +313,9 @@ func executeTokenExpirationValidation() {
 
 			refresh.Expires = expires
 
-			user, err := supabase.GetUser(refresh.Token)
+			user, err := supabase.GetUser(commandContext(), refresh.Token)
 			if err != nil {
-				utils.ErrorOut(err.Error(), utils.ErrorInvalidAuthor)
+				return utils.NewCliError(err.Error(), utils.ErrorInvalidAuthor)
 			}
 
 			err = app.UpdateAuthorMetadata(&models.AuthorFileProps{
@@ -180,20 +328,39 @@ func executeTokenExpirationValidation() {
 				ID:       user.ID,
 			})
 			if err != nil {
-				utils.ErrorOut(err.Error(), utils.ErrorInvalidAuthor)
+				return utils.NewCliError(err.Error(), utils.ErrorInvalidAuthor)
 			}
 		}
 	}
+
+	return nil
 }
 
+// isCommandExclude reports if the command of flags runs without a logged
+// in author. It is decided on the resolved command (annotated by itself or
+// a parent), so names given as flag values do not skip the validation.
 func isCommandExclude(flags []string) bool {
 	if len(flags) == 0 {
 		return true
 	}
 
-	if utils.Contains(flags, "action") || utils.Contains(flags, "login") || utils.Contains(flags, "register") {
+	command, _, err := rootCommand.Find(flags)
+	if err != nil || command == rootCommand || isPluginCommand(command) {
 		return true
-	} else {
-		return false
 	}
+
+	if command.Name() == "help" || command.Name() == cobra.ShellCompRequestCmd || utils.Contains(flags, "--help") || utils.Contains(flags, "-h") {
+		return true
+	}
+
+	for parent := command; parent != nil; parent = parent.Parent() {
+		switch parent.Annotations[authorAnnotation] {
+		case authorNone:
+			return true
+		case authorCloud:
+			return !utils.Present(flags, "--"+utils.CommandFlagReleaseCloud)
+		}
+	}
+
+	return false
 }
@@ -29,7 +29,6 @@ import (
 
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 	"github.com/websublime/sublime-cli/core"
 	"github.com/websublime/sublime-cli/models"
 	"github.com/websublime/sublime-cli/utils"
@@ -47,28 +46,34 @@ func NewStatusCmd(cmdStatus *StatusCommand) *cobra.Command {
 	return &cobra.Command{
 		Use:   utils.CommandStatus,
 		Short: utils.MessageCommandStatusShort,
-		Run: func(cmd *cobra.Command, _ []string) {
-			cmdStatus.Run(cmd)
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdStatus.Run(cmd)
 		},
 	}
 }
 
-func (ctx *StatusCommand) Run(cmd *cobra.Command) {
+func (ctx *StatusCommand) Run(cmd *cobra.Command) error {
 	app := core.GetApp()
-	sublime := models.SublimeViperProps{}
+	sublime := &models.SublimeJsonFileProps{}
 
-	err := viper.Unmarshal(&sublime)
-	if err != nil {
-		utils.ErrorOut(err.Error(), utils.ErrorInvalidWorkspace)
+	if core.GetConfig().HasSublime() {
+		workspace, err := app.ReadSublime()
+		if err != nil {
+			return utils.NewCliError(err.Error(), utils.ErrorInvalidWorkspace)
+		}
+		sublime = workspace
 	}
 
-	tabular := table.NewWriter()
-	tabular.SetStyle(table.StyleBold)
-	tabular.AppendHeader(table.Row{fmt.Sprintf("Project: %s", sublime.Namespace)})
-	tabular.AppendRow(table.Row{"ID", sublime.ID})
-	tabular.AppendRow(table.Row{"Repo", fmt.Sprintf("https://github.com/%s", sublime.Repo)})
-	tabular.AppendRow(table.Row{"Issues", fmt.Sprintf("https://github.com/%s/issues", sublime.Repo)})
-	tabular.AppendRow(table.Row{"Docs", fmt.Sprintf("https://websublime.dev/organization/%s/%s", app.Organization, sublime.Name)})
+	result := &models.StatusResult{
+		Name:         sublime.Name,
+		Namespace:    sublime.Namespace,
+		ID:           sublime.ID,
+		Repo:         sublime.Repo,
+		Organization: sublime.Organization,
+		Docs:         fmt.Sprintf("https://websublime.dev/organization/%s/%s", app.Organization, sublime.Name),
+		Endpoint:     endpointDescription(),
+		Packages:     []models.StatusPackage{},
+	}
 
 	for _, pkg := range sublime.Packages {
 		var libType = "libs"
@@ -81,25 +86,62 @@ func (ctx *StatusCommand) Run(cmd *cobra.Command) {
 
 		pkgJson, err := os.ReadFile(filepath.Join(pkgDir, "package.json"))
 		if err != nil {
-			utils.ErrorOut(err.Error(), utils.ErrorReadFile)
+			return utils.NewCliError(err.Error(), utils.ErrorReadFile)
 		}
 
 		packageJson := models.PackageJson{}
 
 		err = json.Unmarshal(pkgJson, &packageJson)
 		if err != nil {
-			utils.ErrorOut(err.Error(), utils.ErrorReadFile)
+			return utils.NewCliError(err.Error(), utils.ErrorReadFile)
+		}
+
+		result.Packages = append(result.Packages, models.StatusPackage{
+			ID:          pkg.ID,
+			Name:        pkg.Name,
+			Type:        pkg.Type,
+			Version:     packageJson.Version,
+			Description: packageJson.Description,
+		})
+	}
+
+	render(result, func() {
+		tabular := table.NewWriter()
+		tabular.SetStyle(table.StyleBold)
+		tabular.AppendHeader(table.Row{fmt.Sprintf("Project: %s", result.Namespace)})
+		tabular.AppendRow(table.Row{"ID", result.ID})
+		tabular.AppendRow(table.Row{"Repo", fmt.Sprintf("https://github.com/%s", result.Repo)})
+		tabular.AppendRow(table.Row{"Issues", fmt.Sprintf("https://github.com/%s/issues", result.Repo)})
+		tabular.AppendRow(table.Row{"Docs", result.Docs})
+		tabular.AppendRow(table.Row{"Endpoint", result.Endpoint})
+
+		for _, pkg := range result.Packages {
+			rowConfigAutoMerge := table.RowConfig{AutoMerge: true}
+			tabular.AppendSeparator()
+			tabular.AppendRow(table.Row{"Package name", pkg.Name}, rowConfigAutoMerge)
+			tabular.AppendRow(table.Row{"Package type", pkg.Type}, rowConfigAutoMerge)
+			tabular.AppendRow(table.Row{"Package Version", pkg.Version}, rowConfigAutoMerge)
+			tabular.AppendRow(table.Row{"Package Description", pkg.Description}, rowConfigAutoMerge)
 		}
 
-		rowConfigAutoMerge := table.RowConfig{AutoMerge: true}
-		tabular.AppendSeparator()
-		tabular.AppendRow(table.Row{"Package name", pkg.Name}, rowConfigAutoMerge)
-		tabular.AppendRow(table.Row{"Package type", pkg.Type}, rowConfigAutoMerge)
-		tabular.AppendRow(table.Row{"Package Version", packageJson.Version}, rowConfigAutoMerge)
-		tabular.AppendRow(table.Row{"Package Description", packageJson.Description}, rowConfigAutoMerge)
+		tabular.AppendFooter(table.Row{fmt.Sprintf("Packages on organization: %s", result.Organization)})
+
+		fmt.Println(tabular.Render())
+	})
+
+	return nil
+}
+
+func endpointDescription() string {
+	endpoint := core.GetConfig().Endpoint
+	if endpoint == nil {
+		return utils.ApiUrl
 	}
 
-	tabular.AppendFooter(table.Row{fmt.Sprintf("Packages on organization: %s", sublime.Organization)})
+	url := endpoint.Url
+	if url == "" {
+		url = "(not configured)"
+	}
 
-	fmt.Println(tabular.Render())
+	return fmt.Sprintf("%s [%s, profile: %s]", url, endpoint.UrlSource, endpoint.Profile)
 }
@@ -0,0 +1,397 @@
+/*
+Copyright © 2022 Websublime.dev organization@websublime.dev
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+	"github.com/websublime/sublime-cli/api"
+	"github.com/websublime/sublime-cli/core"
+	"github.com/websublime/sublime-cli/models"
+	"github.com/websublime/sublime-cli/utils"
+)
+
+type SyncFlags struct {
+	Check    bool                         `json:"check"`
+	DryRun   bool                         `json:"dryRun"`
+	Orphans  string                       `json:"orphans"`
+	Sublime  *models.SublimeJsonFileProps `json:"-"`
+	Supabase *api.Supabase                `json:"-"`
+}
+
+func init() {
+	syncFlags := &SyncFlags{}
+	syncCmd := NewSyncCmd(syncFlags)
+
+	syncCmd.Flags().BoolVar(&syncFlags.Check, utils.CommandFlagSyncCheck, false, utils.MessageCommandSyncCheck)
+	syncCmd.Flags().BoolVar(&syncFlags.DryRun, utils.CommandFlagSyncDryRun, false, utils.MessageCommandSyncDryRun)
+	syncCmd.Flags().StringVar(&syncFlags.Orphans, utils.CommandFlagSyncOrphans, string(utils.OrphanAsk), utils.MessageCommandSyncOrphans)
+
+	rootCommand.AddCommand(syncCmd)
+}
+
+func NewSyncCmd(cmdSync *SyncFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   utils.CommandSync,
+		Short: utils.MessageCommandSyncShort,
+		Long:  utils.MessageCommandSyncLong,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			app := core.GetApp()
+
+			sublime, err := app.ReadSublime()
+			if err != nil {
+				return utils.NewCliError(err.Error(), utils.ErrorInvalidWorkspace)
+			}
+			cmdSync.Sublime = sublime
+
+			if cmdSync.Sublime.ID == "" {
+				return utils.NewCliError(utils.MessageErrorCommandSyncWorkspace, utils.ErrorInvalidWorkspace)
+			}
+
+			if cmdSync.Check && cmdSync.DryRun {
+				return utils.NewCliError(utils.MessageErrorCommandSyncCheckDryRun, utils.ErrorInvalidFlag)
+			}
+
+			switch utils.OrphanPolicy(cmdSync.Orphans) {
+			case utils.OrphanAsk, utils.OrphanAdopt, utils.OrphanDelete, utils.OrphanSkip:
+			default:
+				return utils.NewCliError(fmt.Sprintf(utils.MessageErrorCommandSyncOrphans, cmdSync.Orphans), utils.ErrorInvalidFlag)
+			}
+
+			cmdSync.Supabase = api.NewSupabase(utils.ApiUrl, utils.ApiKey, app.Author.Token, "production")
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if cmdSync.Check {
+				return cmdSync.Run(cmd)
+			}
+
+			return cmdSync.Reconcile(cmd)
+		},
+	}
+}
+
+// Run only reports the drift, exiting with error when any is found.
+func (ctx *SyncFlags) Run(cmd *cobra.Command) error {
+	_, drifts, err := ctx.Drift(cmd)
+	if err != nil {
+		return err
+	}
+
+	utils.GetOutput().SetResult(&models.SyncResult{
+		Workspace: ctx.Sublime.Name,
+		InSync:    len(drifts) == 0,
+		Drift:     drifts,
+	})
+
+	if len(drifts) == 0 {
+		utils.SuccessOut(fmt.Sprintf(utils.MessageCommandSyncInSync, ctx.Sublime.Name))
+		return nil
+	}
+
+	ctx.Report(drifts)
+	return utils.NewCliError(fmt.Sprintf(utils.MessageCommandSyncSummary, len(drifts)), utils.ErrorWorkspaceDrift)
+}
+
+func (ctx *SyncFlags) Drift(cmd *cobra.Command) ([]models.Package, []models.PackageDrift, error) {
+	app := core.GetApp()
+
+	packages, err := ctx.Supabase.GetPackagesByWorkspace(cmd.Context(), ctx.Sublime.ID)
+	if err != nil {
+		return nil, nil, utils.NewCliError(err.Error(), utils.ErrorInvalidCloudOperation)
+	}
+
+	return packages, app.WorkspaceDrift(ctx.Sublime.Packages, packages), nil
+}
+
+func (ctx *SyncFlags) Report(drifts []models.PackageDrift) {
+	if utils.IsStructured() {
+		return
+	}
+
+	tabular := table.NewWriter()
+	tabular.SetStyle(table.StyleBold)
+	tabular.AppendHeader(table.Row{"Kind", "Package", "ID", "Field", "Local", "Cloud"})
+
+	for _, drift := range drifts {
+		tabular.AppendRow(table.Row{drift.Kind, drift.Package, drift.ID, drift.Field, drift.Local, drift.Cloud})
+	}
+
+	tabular.AppendFooter(table.Row{fmt.Sprintf("Workspace: %s", ctx.Sublime.Name)})
+
+	fmt.Println(tabular.Render())
+}
+
+// Reconcile turns the drift into a plan, applies it (unless --dry-run) and
+// reports what could not be fixed automatically.
+func (ctx *SyncFlags) Reconcile(cmd *cobra.Command) error {
+	cloud, drifts, err := ctx.Drift(cmd)
+	if err != nil {
+		return err
+	}
+
+	result := &models.SyncResult{
+		Workspace: ctx.Sublime.Name,
+		InSync:    len(drifts) == 0,
+		DryRun:    ctx.DryRun,
+		Drift:     drifts,
+		Actions:   []models.SyncAction{},
+		Conflicts: []models.PackageDrift{},
+	}
+	utils.GetOutput().SetResult(result)
+
+	if len(drifts) == 0 {
+		utils.SuccessOut(fmt.Sprintf(utils.MessageCommandSyncInSync, ctx.Sublime.Name))
+		return nil
+	}
+
+	actions, conflicts := ctx.Plan(cloud, drifts)
+	result.Actions = actions
+	result.Conflicts = conflicts
+
+	if len(actions) > 0 {
+		ctx.PrintPlan(actions)
+	}
+
+	if !ctx.DryRun && len(actions) > 0 {
+		if err := ctx.Apply(cmd, actions); err != nil {
+			return err
+		}
+		result.Applied = true
+		utils.SuccessOut(fmt.Sprintf(utils.MessageCommandSyncApplied, len(actions), ctx.Sublime.Name))
+	}
+
+	if ctx.DryRun {
+		utils.InfoOut(utils.MessageCommandSyncDryRunOk)
+	}
+
+	if len(conflicts) > 0 {
+		ctx.PrintConflicts(conflicts)
+		return utils.NewCliError(fmt.Sprintf(utils.MessageCommandSyncConflict, len(conflicts)), utils.ErrorWorkspaceDrift)
+	}
+
+	return nil
+}
+
+func (ctx *SyncFlags) Plan(cloud []models.Package, drifts []models.PackageDrift) ([]models.SyncAction, []models.PackageDrift) {
+	actions := []models.SyncAction{}
+	conflicts := []models.PackageDrift{}
+
+	for _, drift := range drifts {
+		switch drift.Kind {
+		case utils.DriftMissingID, utils.DriftStaleID:
+			if drift.Cloud != "" {
+				actions = append(actions, models.SyncAction{Action: utils.SyncLink, Package: drift.Package, ID: drift.Cloud, Detail: fmt.Sprintf("id %s", drift.Cloud)})
+			} else {
+				actions = append(actions, models.SyncAction{Action: utils.SyncCreate, Package: drift.Package})
+			}
+		case utils.DriftField:
+			if drift.Field == "description" {
+				actions = append(actions, models.SyncAction{Action: utils.SyncDescription, Package: drift.Package, ID: drift.ID, Detail: drift.Local})
+				continue
+			}
+
+			drift.Reason = utils.MessageErrorCommandSyncFieldChanged
+			conflicts = append(conflicts, drift)
+		case utils.DriftVersion:
+			if drift.Cloud != "" && utils.CompareVersions(drift.Local, drift.Cloud) < 0 {
+				drift.Reason = utils.MessageErrorCommandSyncNewerCloud
+				conflicts = append(conflicts, drift)
+				continue
+			}
+
+			actions = append(actions, models.SyncAction{Action: utils.SyncVersion, Package: drift.Package, ID: drift.ID, Detail: drift.Local})
+		case utils.DriftMissingManifest:
+			drift.Reason = utils.MessageErrorCommandSyncNoManifest
+			conflicts = append(conflicts, drift)
+		case utils.DriftOrphan:
+			switch ctx.OrphanDecision(drift) {
+			case utils.OrphanAdopt:
+				actions = append(actions, models.SyncAction{Action: utils.SyncAdopt, Package: drift.Package, ID: drift.ID})
+			case utils.OrphanDelete:
+				actions = append(actions, models.SyncAction{Action: utils.SyncDelete, Package: drift.Package, ID: drift.ID})
+			default:
+				drift.Reason = utils.MessageErrorCommandSyncOrphanSkip
+				conflicts = append(conflicts, drift)
+			}
+		}
+	}
+
+	return actions, conflicts
+}
+
+// OrphanDecision applies --orphans. With "ask" the author is prompted, unless
+// there is no terminal or it is a dry run, where orphans are skipped.
+func (ctx *SyncFlags) OrphanDecision(drift models.PackageDrift) utils.OrphanPolicy {
+	policy := utils.OrphanPolicy(ctx.Orphans)
+	if policy != utils.OrphanAsk {
+		return policy
+	}
+
+	if ctx.DryRun || !utils.IsInteractive() || utils.IsStructured() {
+		return utils.OrphanSkip
+	}
+
+	options := []utils.OrphanPolicy{utils.OrphanAdopt, utils.OrphanDelete, utils.OrphanSkip}
+	index, _, err := models.PromptGetSelect(models.PromptSelectContent{
+		Label: fmt.Sprintf(utils.MessageCommandSyncOrphan, drift.Package, drift.ID),
+		Items: []string{string(utils.OrphanAdopt), string(utils.OrphanDelete), string(utils.OrphanSkip)},
+	})
+	if err != nil || index >= len(options) {
+		return utils.OrphanSkip
+	}
+
+	return options[index]
+}
+
+func (ctx *SyncFlags) Apply(cmd *cobra.Command, actions []models.SyncAction) error {
+	app := core.GetApp()
+	config := core.GetConfig()
+
+	packages := ctx.Sublime.Packages
+	cloud, err := ctx.Supabase.GetPackagesByWorkspace(cmd.Context(), ctx.Sublime.ID)
+	if err != nil {
+		return utils.NewCliError(err.Error(), utils.ErrorInvalidCloudOperation)
+	}
+
+	local := func(name string) *models.SublimePackages {
+		for idx := range packages {
+			if packages[idx].Name == name {
+				return &packages[idx]
+			}
+		}
+		return nil
+	}
+
+	// Local changes are written even when a cloud call fails halfway, so ids
+	// of packages already created are never lost.
+	save := func() error {
+		if err := app.UpdatePackages(packages); err != nil {
+			return utils.NewCliError(err.Error(), utils.ErrorCreateFile)
+		}
+
+		return nil
+	}
+
+	fail := func(action models.SyncAction, err error) error {
+		if err := save(); err != nil {
+			return err
+		}
+
+		return utils.NewCliError(fmt.Sprintf("%s %s: %s", action.Action, action.Package, err.Error()), utils.ErrorInvalidCloudOperation)
+	}
+
+	for _, action := range actions {
+		switch action.Action {
+		case utils.SyncLink:
+			local(action.Package).ID = action.ID
+		case utils.SyncCreate:
+			pkg := local(action.Package)
+
+			dependencies := map[string]string{}
+			if manifest, err := core.ReadPackageManifest(filepath.Join(config.PackageDir(*pkg), "package.json")); err == nil {
+				dependencies = manifest.AllDependencies()
+			}
+			template := core.DetectTemplate(config.PackageDir(*pkg), dependencies)
+
+			created, err := ctx.Supabase.CreateWorkspacePackage(cmd.Context(), pkg.Name, pkg.Description, pkg.Type, template, ctx.Sublime.ID)
+			if err == nil && len(created) == 0 {
+				err = &api.ApiError{StatusCode: http.StatusNotFound, Message: "package was not returned by the cloud"}
+			}
+			if err != nil {
+				return fail(action, err)
+			}
+			pkg.ID = created[0].ID
+
+			if packageJson, err := config.ReadPackageJson(*pkg); err == nil && packageJson.Version != created[0].Version {
+				if _, err := ctx.Supabase.UpdateWorkspacePackageVersion(cmd.Context(), pkg.ID, packageJson.Version); err != nil {
+					return fail(action, err)
+				}
+			}
+		case utils.SyncDescription:
+			if _, err := ctx.Supabase.UpdateWorkspacePackage(cmd.Context(), action.ID, &models.Package{Description: action.Detail}); err != nil {
+				return fail(action, err)
+			}
+		case utils.SyncVersion:
+			if _, err := ctx.Supabase.UpdateWorkspacePackageVersion(cmd.Context(), action.ID, action.Detail); err != nil {
+				return fail(action, err)
+			}
+		case utils.SyncAdopt:
+			for _, row := range cloud {
+				if row.ID == action.ID {
+					packages = append(packages, models.SublimePackages{
+						ID:          row.ID,
+						Name:        row.Name,
+						Scope:       fmt.Sprintf("@%s", ctx.Sublime.Organization),
+						Type:        row.Type,
+						Description: row.Description,
+					})
+				}
+			}
+		case utils.SyncDelete:
+			if _, err := ctx.Supabase.DeletePackageByID(cmd.Context(), action.ID); err != nil {
+				return fail(action, err)
+			}
+		}
+	}
+
+	return save()
+}
+
+func (ctx *SyncFlags) PrintPlan(actions []models.SyncAction) {
+	if utils.IsStructured() {
+		return
+	}
+
+	tabular := table.NewWriter()
+	tabular.SetStyle(table.StyleBold)
+	tabular.AppendHeader(table.Row{"Action", "Package", "ID", "Detail"})
+
+	for _, action := range actions {
+		tabular.AppendRow(table.Row{action.Action, action.Package, action.ID, action.Detail})
+	}
+
+	tabular.AppendFooter(table.Row{fmt.Sprintf(utils.MessageCommandSyncPlan, ctx.Sublime.Name)})
+
+	fmt.Println(tabular.Render())
+}
+
+func (ctx *SyncFlags) PrintConflicts(conflicts []models.PackageDrift) {
+	if utils.IsStructured() {
+		return
+	}
+
+	tabular := table.NewWriter()
+	tabular.SetStyle(table.StyleBold)
+	tabular.AppendHeader(table.Row{"Kind", "Package", "ID", "Field", "Local", "Cloud", "Reason"})
+
+	for _, drift := range conflicts {
+		tabular.AppendRow(table.Row{drift.Kind, drift.Package, drift.ID, drift.Field, drift.Local, drift.Cloud, drift.Reason})
+	}
+
+	fmt.Println(tabular.Render())
+}
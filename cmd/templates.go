@@ -21,7 +21,40 @@ THE SOFTWARE.
 */
 package cmd
 
-import "embed"
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+
+	"github.com/websublime/sublime-cli/core"
+	"github.com/websublime/sublime-cli/models"
+	"github.com/websublime/sublime-cli/utils"
+)
 
 //go:embed templates/*
 var FileTemplates embed.FS
+
+// BuiltinTemplateManifest is the manifest of templates without a
+// sublime-template.json, its sources are read from the embedded templates.
+func BuiltinTemplateManifest(template utils.TemplateType) (*models.TemplateManifest, fs.FS, error) {
+	files, err := fs.Sub(FileTemplates, "templates")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	name := fmt.Sprintf("manifests/%s.json", template)
+	data, err := fs.ReadFile(files, name)
+	if err != nil {
+		name = fmt.Sprintf("manifests/%s.json", utils.Typescript)
+		if data, err = fs.ReadFile(files, name); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	manifest, err := core.ParseTemplateManifest(data, name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return manifest, files, nil
+}
@@ -0,0 +1,218 @@
+/*
+Copyright © 2022 Websublime.dev organization@websublime.dev
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+	"github.com/websublime/sublime-cli/api"
+	"github.com/websublime/sublime-cli/core"
+	"github.com/websublime/sublime-cli/models"
+	"github.com/websublime/sublime-cli/utils"
+)
+
+type TokenFlags struct {
+	Workspace string        `json:"workspace"`
+	Name      string        `json:"name"`
+	Scopes    []string      `json:"scopes"`
+	Expires   time.Duration `json:"expires"`
+}
+
+func init() {
+	tokenFlags := &TokenFlags{}
+	tokenCmd := NewTokenCmd()
+
+	createCmd := NewTokenCreateCmd(tokenFlags)
+	createCmd.Flags().StringVar(&tokenFlags.Name, utils.CommandFlagTokenName, "github-actions", utils.MessageCommandTokenName)
+	createCmd.Flags().StringSliceVar(&tokenFlags.Scopes, utils.CommandFlagTokenScope, []string{string(utils.ScopeUpload), string(utils.ScopeVersion)}, utils.MessageCommandTokenScope)
+	createCmd.Flags().DurationVar(&tokenFlags.Expires, utils.CommandFlagTokenExpires, 0, utils.MessageCommandTokenExpires)
+
+	listCmd := NewTokenListCmd(tokenFlags)
+
+	for _, cmd := range []*cobra.Command{createCmd, listCmd} {
+		cmd.Flags().StringVar(&tokenFlags.Workspace, utils.CommandFlagTokenWorkspace, "", utils.MessageCommandTokenWorkspace)
+	}
+
+	tokenCmd.AddCommand(createCmd, listCmd, NewTokenRevokeCmd(tokenFlags))
+	rootCommand.AddCommand(tokenCmd)
+}
+
+func NewTokenCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   utils.CommandToken,
+		Short: utils.MessageCommandTokenShort,
+		Long:  utils.MessageCommandTokenLong,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cmd.Help()
+		},
+	}
+}
+
+func NewTokenCreateCmd(cmdToken *TokenFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   utils.CommandTokenCreate,
+		Short: utils.MessageCommandTokenCreateShort,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			if err := cmdToken.ResolveWorkspace(); err != nil {
+				return err
+			}
+
+			for _, scope := range cmdToken.Scopes {
+				if !utils.IsDeployScope(scope) {
+					return utils.NewCliError(fmt.Sprintf(utils.MessageErrorCommandTokenScope, scope), utils.ErrorInvalidFlag)
+				}
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdToken.Create(cmd)
+		},
+	}
+}
+
+func NewTokenListCmd(cmdToken *TokenFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   utils.CommandTokenList,
+		Short: utils.MessageCommandTokenListShort,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdToken.ResolveWorkspace()
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdToken.List(cmd)
+		},
+	}
+}
+
+func NewTokenRevokeCmd(cmdToken *TokenFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   fmt.Sprintf("%s <token-id>", utils.CommandTokenRevoke),
+		Short: utils.MessageCommandTokenRevokeShort,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmdToken.Revoke(cmd, args[0])
+		},
+	}
+}
+
+// ResolveWorkspace defaults --workspace to the id on .sublime.json.
+func (ctx *TokenFlags) ResolveWorkspace() error {
+	if ctx.Workspace != "" {
+		return nil
+	}
+
+	if sublime, err := core.GetApp().ReadSublime(); err == nil {
+		ctx.Workspace = sublime.ID
+	}
+
+	if ctx.Workspace == "" {
+		return utils.NewCliError(utils.MessageErrorCommandTokenWorkspace, utils.ErrorInvalidWorkspace)
+	}
+
+	return nil
+}
+
+func (ctx *TokenFlags) Create(cmd *cobra.Command) error {
+	app := core.GetApp()
+
+	expiresAt := ""
+	if ctx.Expires > 0 {
+		expiresAt = time.Now().Add(ctx.Expires).UTC().Format(time.RFC3339)
+	}
+
+	supabase := api.NewSupabase(utils.ApiUrl, utils.ApiKey, app.Author.Token, "production")
+	token, secret, err := supabase.CreateDeployToken(cmd.Context(), ctx.Workspace, ctx.Name, ctx.Scopes, expiresAt)
+	if err != nil {
+		return utils.NewCliError(err.Error(), utils.ErrorInvalidCloudOperation)
+	}
+
+	token.TokenHash = ""
+
+	utils.SuccessOut(fmt.Sprintf(utils.MessageCommandTokenCreated, token.ID, strings.Join(token.Scopes, ",")))
+	utils.WarningOut(utils.MessageCommandTokenCopy)
+
+	render(&models.DeployTokenCreateResult{Token: token, Secret: secret}, func() {
+		fmt.Println(secret)
+	})
+
+	return nil
+}
+
+func (ctx *TokenFlags) List(cmd *cobra.Command) error {
+	app := core.GetApp()
+
+	supabase := api.NewSupabase(utils.ApiUrl, utils.ApiKey, app.Author.Token, "production")
+	tokens, err := supabase.GetDeployTokensByWorkspace(cmd.Context(), ctx.Workspace)
+	if err != nil {
+		return utils.NewCliError(err.Error(), utils.ErrorInvalidCloudOperation)
+	}
+
+	result := []models.DeployTokenResult{}
+
+	for _, token := range tokens {
+		status := "active"
+		if token.RevokedAt != "" {
+			status = "revoked"
+		} else if expires, err := time.Parse(time.RFC3339, token.ExpiresAt); err == nil && time.Now().After(expires) {
+			status = "expired"
+		}
+
+		token.TokenHash = ""
+		result = append(result, models.DeployTokenResult{DeployToken: token, Status: status})
+	}
+
+	render(result, func() {
+		tabular := table.NewWriter()
+		tabular.SetStyle(table.StyleBold)
+		tabular.AppendHeader(table.Row{"ID", "Name", "Prefix", "Scopes", "Created", "Expires", "Last used", "Status"})
+
+		for _, token := range result {
+			tabular.AppendRow(table.Row{token.ID, token.Name, token.Prefix, strings.Join(token.Scopes, ","), token.CreatedAt, token.ExpiresAt, token.LastUsedAt, token.Status})
+		}
+
+		tabular.AppendFooter(table.Row{fmt.Sprintf("Workspace: %s", ctx.Workspace)})
+
+		fmt.Println(tabular.Render())
+	})
+
+	return nil
+}
+
+func (ctx *TokenFlags) Revoke(cmd *cobra.Command, tokenID string) error {
+	app := core.GetApp()
+
+	supabase := api.NewSupabase(utils.ApiUrl, utils.ApiKey, app.Author.Token, "production")
+	token, err := supabase.RevokeDeployToken(cmd.Context(), tokenID)
+	if err != nil {
+		return utils.NewCliError(err.Error(), utils.ErrorInvalidCloudOperation)
+	}
+
+	token.TokenHash = ""
+	utils.GetOutput().SetResult(token)
+	utils.SuccessOut(fmt.Sprintf(utils.MessageCommandTokenRevoked, token.ID))
+
+	return nil
+}
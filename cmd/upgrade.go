@@ -0,0 +1,261 @@
+/*
+Copyright © 2022 Websublime.dev organization@websublime.dev
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+	"github.com/websublime/sublime-cli/core"
+	"github.com/websublime/sublime-cli/models"
+	"github.com/websublime/sublime-cli/utils"
+)
+
+type UpgradeFlags struct {
+	DryRun  bool                         `json:"dry-run"`
+	Force   bool                         `json:"force"`
+	Sublime *models.SublimeJsonFileProps `json:"-"`
+}
+
+func init() {
+	upgradeFlags := &UpgradeFlags{}
+	upgradeCmd := NewUpgradeCmd(upgradeFlags)
+
+	upgradeCmd.Flags().BoolVar(&upgradeFlags.DryRun, utils.CommandFlagUpgradeDryRun, false, utils.MessageCommandUpgradeDryRun)
+	upgradeCmd.Flags().BoolVar(&upgradeFlags.Force, utils.CommandFlagUpgradeForce, false, utils.MessageCommandUpgradeForce)
+
+	rootCommand.AddCommand(upgradeCmd)
+}
+
+func NewUpgradeCmd(cmdUpgrade *UpgradeFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   utils.CommandUpgrade,
+		Short: utils.MessageCommandUpgradeShort,
+		Long:  utils.MessageCommandUpgradeLong,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			sublime, err := core.GetApp().ReadSublime()
+			if err != nil {
+				return utils.NewCliError(err.Error(), utils.ErrorInvalidWorkspace)
+			}
+
+			cmdUpgrade.Sublime = sublime
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdUpgrade.Run(cmd)
+		},
+	}
+}
+
+func (ctx *UpgradeFlags) Run(cmd *cobra.Command) error {
+	config := core.GetConfig()
+
+	result := &models.UpgradeResult{Version: Version, DryRun: ctx.DryRun, Files: []models.UpgradeFile{}}
+	recorded := &models.GeneratedFiles{Files: []models.GeneratedFile{}}
+	applied, conflicts := 0, 0
+	manager := core.DetectPackageManager(config.RootDir, ctx.Sublime.PackageManager)
+
+	files, err := ctx.GeneratedFiles()
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		template, err := FileTemplates.ReadFile(file.Template)
+		if err != nil {
+			utils.WarningOut(fmt.Sprintf(utils.MessageErrorCommandUpgradeTemplate, file.Path, file.Template))
+			recorded.Files = append(recorded.Files, file)
+			continue
+		}
+
+		variables := map[string]string{}
+		for name, value := range file.Variables {
+			variables[name] = value
+		}
+		if _, ok := variables["Version"]; ok {
+			variables["Version"] = Version
+		}
+
+		// Workflows follow the current package manager of the workspace.
+		if strings.HasPrefix(file.Template, "templates/workflow-") {
+			for name, value := range manager.Variables() {
+				variables[name] = value
+			}
+		}
+
+		rendered, err := core.RenderTemplateString(string(template), file.Delimiters, variables)
+		if err != nil {
+			return utils.NewCliError(err.Error(), utils.ErrorInvalidTemplate)
+		}
+
+		upgrade, err := core.UpgradeGeneratedFile(commandContext(), config.RootDir, &file, rendered, Version, ctx.Force)
+		if err != nil {
+			return utils.NewCliError(err.Error(), utils.ErrorReadFile)
+		}
+
+		if upgrade.Write && !ctx.DryRun {
+			if _, err := WriteFile(filepath.Join(config.RootDir, filepath.FromSlash(file.Path)), []byte(upgrade.Content), true); err != nil {
+				return utils.NewCliError(err.Error(), utils.ErrorCreateFile)
+			}
+		}
+
+		switch upgrade.Status {
+		case utils.FileConflict:
+			conflicts++
+		case utils.FileUnchanged, utils.FileKept, utils.FileDeleted:
+		default:
+			applied++
+		}
+
+		// Conflicts keep their base until they are merged (or forced).
+		if upgrade.Status != utils.FileConflict {
+			file.Variables = variables
+			file.Version = Version
+		}
+		file.Hash = upgrade.Hash
+
+		recorded.Files = append(recorded.Files, file)
+		result.Files = append(result.Files, upgrade.UpgradeFile)
+	}
+
+	if !ctx.DryRun {
+		if err := core.SaveGeneratedFiles(config.RootDir, recorded); err != nil {
+			return utils.NewCliError(err.Error(), utils.ErrorCreateFile)
+		}
+	}
+
+	render(result, func() {
+		for _, file := range result.Files {
+			if file.Diff != "" {
+				fmt.Println(file.Diff)
+			}
+		}
+
+		tabular := table.NewWriter()
+		tabular.SetStyle(table.StyleBold)
+		tabular.AppendHeader(table.Row{"File", "Template", "Status"})
+
+		for _, file := range result.Files {
+			tabular.AppendRow(table.Row{file.Path, file.Template, file.Status})
+		}
+
+		fmt.Println(tabular.Render())
+	})
+
+	switch {
+	case ctx.DryRun:
+		utils.InfoOut(utils.MessageCommandUpgradeDryRunOk)
+	case applied > 0:
+		utils.SuccessOut(fmt.Sprintf(utils.MessageCommandUpgradeApplied, applied, Version))
+	case conflicts == 0:
+		utils.SuccessOut(fmt.Sprintf(utils.MessageCommandUpgradeUpToDate, Version))
+	}
+
+	if conflicts > 0 {
+		return utils.NewCliError(fmt.Sprintf(utils.MessageCommandUpgradeConflicts, conflicts), utils.ErrorWorkspaceDrift)
+	}
+
+	return nil
+}
+
+// GeneratedFiles are the files recorded on .sublime/generated.json plus,
+// for workspaces generated by older versions, the existing files the
+// embedded templates generate (without a hash, so their base is unknown).
+func (ctx *UpgradeFlags) GeneratedFiles() ([]models.GeneratedFile, error) {
+	config := core.GetConfig()
+
+	generated, err := core.LoadGeneratedFiles(config.RootDir)
+	if err != nil {
+		return nil, utils.NewCliError(err.Error(), utils.ErrorReadFile)
+	}
+
+	files := generated.Files
+
+	known := map[string]bool{}
+	for _, file := range files {
+		known[file.Path] = true
+	}
+
+	legacy := []models.GeneratedFile{}
+	for _, template := range append([]TemplateFile{ChangesetTemplate(ctx.Sublime.Repo)}, WorkflowTemplates(ctx.Sublime.Organization, core.DetectPackageManager(config.RootDir, ctx.Sublime.PackageManager))...) {
+		legacy = append(legacy, models.GeneratedFile{
+			Path:       template.Target,
+			Template:   template.Template,
+			Delimiters: []string{template.Open, template.Close},
+			Variables:  templateVariables(template.Props),
+		})
+	}
+
+	for _, pkg := range ctx.Sublime.Packages {
+		dir := path.Join(packageLibDir(pkg), pkg.Name)
+
+		dependencies := map[string]string{}
+		if manifest, err := core.ReadPackageManifest(filepath.Join(config.RootDir, dir, "package.json")); err == nil {
+			dependencies = manifest.AllDependencies()
+		}
+
+		template := core.DetectTemplate(filepath.Join(config.RootDir, dir), dependencies)
+
+		manifest, _, err := BuiltinTemplateManifest(template)
+		if err != nil {
+			return nil, utils.NewCliError(err.Error(), utils.ErrorInvalidTemplate)
+		}
+
+		variables, err := PackageTemplateVariables(ctx.Sublime, pkg, template)
+		if err != nil {
+			return nil, utils.NewCliError(err.Error(), utils.ErrorInvalidTemplate)
+		}
+
+		for _, file := range manifest.Files {
+			if file.Target == "package.json" {
+				continue
+			}
+
+			legacy = append(legacy, models.GeneratedFile{
+				Path:       path.Join(dir, file.Target),
+				Template:   path.Join("templates", file.Source),
+				Delimiters: []string{"{{", "}}"},
+				Variables:  variables,
+			})
+		}
+	}
+
+	for _, file := range legacy {
+		if known[file.Path] {
+			continue
+		}
+
+		if _, err := os.Stat(filepath.Join(config.RootDir, filepath.FromSlash(file.Path))); err != nil {
+			continue
+		}
+
+		files = append(files, file)
+	}
+
+	return files, nil
+}
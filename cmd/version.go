@@ -26,6 +26,7 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/websublime/sublime-cli/models"
 )
 
 var (
@@ -41,7 +42,11 @@ var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Print the version number of sublime",
 	Long:  `All software has versions. This is Sublime's`,
-	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("Sublime CLI", Version)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		render(&models.VersionResult{Version: Version, BuildTime: BuildTime}, func() {
+			fmt.Println("Sublime CLI", Version)
+		})
+
+		return nil
 	},
 }
@@ -24,11 +24,11 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 
 	"github.com/gosimple/slug"
+	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/spf13/cobra"
 	"github.com/websublime/sublime-cli/api"
 	"github.com/websublime/sublime-cli/core"
@@ -37,11 +37,17 @@ import (
 )
 
 type CreateWorkspace struct {
-	Name         string `json:"name"`
-	Repo         string `json:"repo"`
+	Name           string                   `json:"name"`
+	Repo           string                   `json:"repo"`
+	Organization   string                   `json:"organization"`
+	Description    string                   `json:"description"`
+	Template       string                   `json:"template"`
+	PackageManager utils.PackageManagerType `json:"package_manager"`
+	WorkspaceDir   string                   `json:"-"`
+}
+
+type WorkspaceInfoFlags struct {
 	Organization string `json:"organization"`
-	Description  string `json:"description"`
-	WorkspaceDir string `json:"-"`
 }
 
 func init() {
@@ -49,8 +55,14 @@ func init() {
 	workspaceCmd := NewWorkspaceCmd(createWorkspace)
 
 	workspaceCmd.Flags().StringVar(&createWorkspace.Organization, utils.CommandFlagWorkspaceOrganization, "", utils.MessageCommandWorkspaceOrganization)
-	workspaceCmd.MarkFlagRequired(utils.CommandFlagWorkspaceOrganization)
+	workspaceCmd.Flags().StringVar(&createWorkspace.Template, utils.CommandFlagTemplate, string(utils.Workspace), utils.MessageCommandWorkspaceTemplate)
+	workspaceCmd.Flags().StringVar((*string)(&createWorkspace.PackageManager), utils.CommandFlagPackageManager, string(utils.Yarn), utils.MessageCommandWorkspacePackageManager)
+
+	infoFlags := &WorkspaceInfoFlags{}
+	listCmd := NewWorkspaceListCmd(infoFlags)
+	listCmd.Flags().StringVar(&infoFlags.Organization, utils.CommandFlagWorkspaceOrganization, "", utils.MessageCommandOrgOrganization)
 
+	workspaceCmd.AddCommand(listCmd, NewWorkspaceShowCmd(infoFlags))
 	rootCommand.AddCommand(workspaceCmd)
 }
 
@@ -59,41 +71,73 @@ func NewWorkspaceCmd(cmdWorkspace *CreateWorkspace) *cobra.Command {
 		Use:   utils.CommandWorkspace,
 		Short: utils.MessageCommandWorkspaceShort,
 		Long:  utils.MessageCommandWorkspaceLong,
-		PreRun: func(cmd *cobra.Command, _ []string) {
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
 			app := core.GetApp()
 			organization, err := cmd.Flags().GetString(utils.CommandFlagWorkspaceOrganization)
 			if err != nil {
-				utils.ErrorOut(err.Error(), utils.ErrorInvalidFlag)
+				return utils.NewCliError(err.Error(), utils.ErrorInvalidFlag)
+			}
+
+			if organization == "" {
+				organization = core.GetConfig().DefaultOrganization()
+				cmdWorkspace.Organization = organization
+			}
+
+			if organization == "" {
+				return utils.NewCliError(utils.MessageErrorCommandOrgMissing, utils.ErrorInvalidFlag)
 			}
 
 			if strings.HasPrefix(organization, "@") {
-				utils.ErrorOut(utils.MessageErrorCommandWorkspaceInvalidNamespace, utils.ErrorInvalidFlag)
+				return utils.NewCliError(utils.MessageErrorCommandWorkspaceInvalidNamespace, utils.ErrorInvalidFlag)
+			}
+
+			if !utils.IsPackageManager(string(cmdWorkspace.PackageManager)) {
+				return utils.NewCliError(fmt.Sprintf(utils.MessageErrorPackageManager, cmdWorkspace.PackageManager), utils.ErrorInvalidFlag)
 			}
 
 			supabase := api.NewSupabase(utils.ApiUrl, utils.ApiKey, app.Author.Token, "production")
-			isUserOrganization, err := supabase.ValidateUserOrganization(app.Author.ID, organization)
+			isUserOrganization, err := supabase.ValidateUserOrganization(cmd.Context(), app.Author.ID, organization)
 			if err != nil {
-				utils.ErrorOut(err.Error(), utils.ErrorInvalidOrganization)
+				return utils.NewCliError(err.Error(), utils.ErrorInvalidOrganization)
 			}
 
 			if !isUserOrganization {
-				utils.ErrorOut(utils.MessageErrorCommandWorkspaceInvalidOrganization, utils.ErrorInvalidOrganization)
+				return utils.NewCliError(utils.MessageErrorCommandWorkspaceInvalidOrganization, utils.ErrorInvalidOrganization)
 			}
 
+			return nil
 		},
-		Run: func(cmd *cobra.Command, _ []string) {
-			cmdWorkspace.Run(cmd)
-			cmdWorkspace.CreateWorkTree(cmd)
-			cmdWorkspace.Workflows()
-			cmdWorkspace.InitGit()
-			cmdWorkspace.InitYarn()
-			cmdWorkspace.BuildVitePlugin()
-			cmdWorkspace.CreateCloudWorkspace()
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if err := cmdWorkspace.Run(cmd); err != nil {
+				return err
+			}
+
+			if err := cmdWorkspace.CreateWorkTree(cmd); err != nil {
+				return err
+			}
+
+			if err := cmdWorkspace.Workflows(); err != nil {
+				return err
+			}
+
+			if err := cmdWorkspace.InitGit(); err != nil {
+				return err
+			}
+
+			if err := cmdWorkspace.InitPackageManager(); err != nil {
+				return err
+			}
+
+			if err := cmdWorkspace.BuildVitePlugin(); err != nil {
+				return err
+			}
+
+			return cmdWorkspace.CreateCloudWorkspace()
 		},
 	}
 }
 
-func (ctx *CreateWorkspace) Run(cmd *cobra.Command) {
+func (ctx *CreateWorkspace) Run(cmd *cobra.Command) error {
 	nameContent := models.PromptContent{
 		Error: utils.MessageErrorCommandWorkspaceNamePrompt,
 		Label: utils.MessageCommandWorkspaceNamePrompt,
@@ -114,33 +158,32 @@ func (ctx *CreateWorkspace) Run(cmd *cobra.Command) {
 
 	name, err := models.PromptGetInput(nameContent, 3)
 	if err != nil {
-		utils.ErrorOut(err.Error(), utils.ErrorPromptInvalid)
+		return utils.NewCliError(err.Error(), utils.ErrorPromptInvalid)
 	}
 
 	repo, err := models.PromptGetInput(repoContent, 3)
 	if err != nil {
-		utils.ErrorOut(err.Error(), utils.ErrorPromptInvalid)
+		return utils.NewCliError(err.Error(), utils.ErrorPromptInvalid)
 	}
 
 	description, err := models.PromptGetInput(descriptionContent, 3)
 	if err != nil {
-		utils.ErrorOut(err.Error(), utils.ErrorPromptInvalid)
+		return utils.NewCliError(err.Error(), utils.ErrorPromptInvalid)
 	}
 
 	ctx.Description = description
 	ctx.Name = name
 	ctx.Repo = repo
+
+	return nil
 }
 
-func (ctx *CreateWorkspace) CreateWorkTree(cmd *cobra.Command) {
+func (ctx *CreateWorkspace) CreateWorkTree(cmd *cobra.Command) error {
 	config := core.GetConfig()
-	config.Progress.SetNumTrackersExpected(6)
-	config.Progress.Style().Visibility.Value = false
 	app := core.GetApp()
 
-	go config.Progress.Render()
-
-	config.UpdateProgress(utils.MessageCommandWorkspaceProgressInit, 2)
+	config.Progress.Start(6)
+	config.Progress.Step(utils.MessageCommandWorkspaceProgressInit)
 
 	rootNamespace := strings.Join([]string{fmt.Sprintf("@%s", ctx.Organization), slug.Make(ctx.Name)}, "/")
 	viteNamespace := strings.Join([]string{fmt.Sprintf("@%s", ctx.Organization), "vite"}, "/")
@@ -148,107 +191,107 @@ func (ctx *CreateWorkspace) CreateWorkTree(cmd *cobra.Command) {
 	ctx.WorkspaceDir = filepath.Join(config.RootDir, slug.Make(ctx.Name))
 
 	if err := os.Mkdir(ctx.WorkspaceDir, 0755); err != nil {
-		ctx.CommandError(utils.MessageErrorCommandWorkspaceInvalidDirectory, utils.ErrorCreateDirectory)
+		return ctx.CommandError(utils.MessageErrorCommandWorkspaceInvalidDirectory, utils.ErrorCreateDirectory)
 	}
 
-	gitCmd := exec.Command("git", "clone", "git@github.com:websublime/sublime-workspace-template.git", ctx.WorkspaceDir)
-	_, err := gitCmd.Output()
+	// Templates declared on a .sublime.json of the current dir are also used.
+	sublime, _ := app.ReadSublime()
+
+	source, err := config.ResolveTemplate(ctx.Template, sublime)
 	if err != nil {
-		ctx.CommandError(err.Error(), utils.ErrorInvalidGit)
+		return ctx.CommandError(err.Error(), utils.ErrorInvalidTemplate)
 	}
 
-	packageJson, err := FileTemplates.ReadFile("templates/workspace-package.json")
+	templateDir, err := config.FetchTemplate(commandContext(), source)
 	if err != nil {
-		ctx.CommandError(err.Error(), utils.ErrorInvalidTemplate)
+		return ctx.CommandError(err.Error(), utils.ErrorInvalidTemplate)
 	}
-	vitePackageJson, err := FileTemplates.ReadFile("templates/vite-package.json")
-	if err != nil {
-		ctx.CommandError(err.Error(), utils.ErrorInvalidTemplate)
+
+	if err := core.CopyTemplate(templateDir, ctx.WorkspaceDir); err != nil {
+		return ctx.CommandError(err.Error(), utils.ErrorCreateFile)
 	}
-	tsconfigBaseJson, err := FileTemplates.ReadFile("templates/tsconfig-base.json")
+
+	manager := core.NewPackageManager(ctx.PackageManager)
+
+	packageJson, err := FileTemplates.ReadFile("templates/workspace-package.json")
 	if err != nil {
-		ctx.CommandError(err.Error(), utils.ErrorInvalidTemplate)
+		return ctx.CommandError(err.Error(), utils.ErrorInvalidTemplate)
 	}
-	changesetConfigJson, err := FileTemplates.ReadFile("templates/changeset-config.json")
+	vitePackageJson, err := FileTemplates.ReadFile("templates/vite-package.json")
 	if err != nil {
-		ctx.CommandError(err.Error(), utils.ErrorInvalidTemplate)
+		return ctx.CommandError(err.Error(), utils.ErrorInvalidTemplate)
 	}
-	sublimeConfigJson, err := FileTemplates.ReadFile("templates/sublime.json")
+	tsconfigBaseJson, err := FileTemplates.ReadFile("templates/tsconfig-base.json")
 	if err != nil {
-		ctx.CommandError(err.Error(), utils.ErrorInvalidTemplate)
+		return ctx.CommandError(err.Error(), utils.ErrorInvalidTemplate)
 	}
 	readmeConfigJson, err := FileTemplates.ReadFile("templates/readme.md")
 	if err != nil {
-		ctx.CommandError(err.Error(), utils.ErrorInvalidTemplate)
+		return ctx.CommandError(err.Error(), utils.ErrorInvalidTemplate)
 	}
 
 	pkgJsonFile, err := os.Create(filepath.Join(ctx.WorkspaceDir, "package.json"))
 	if err != nil {
-		ctx.CommandError(err.Error(), utils.ErrorCreateFile)
+		return ctx.CommandError(err.Error(), utils.ErrorCreateFile)
 	}
 	_, err = pkgJsonFile.WriteString(utils.ProcessString(string(packageJson), &models.PackageJsonFileProps{
-		Namespace: rootNamespace,
-		Repo:      ctx.Repo,
-		Username:  app.Author.Username,
-		Email:     app.Author.Email,
+		PackageManagerFileProps: manager.FileProps(),
+		Namespace:               rootNamespace,
+		Repo:                    ctx.Repo,
+		Username:                app.Author.Username,
+		Email:                   app.Author.Email,
 	}, "{{", "}}"))
 	if err != nil {
-		ctx.CommandError(err.Error(), utils.ErrorInvalidTemplate)
+		return ctx.CommandError(err.Error(), utils.ErrorInvalidTemplate)
 	}
 
 	vitePkgJsonFile, err := os.Create(filepath.Join(ctx.WorkspaceDir, "libs/vite/package.json"))
 	if err != nil {
-		ctx.CommandError(err.Error(), utils.ErrorCreateFile)
+		return ctx.CommandError(err.Error(), utils.ErrorCreateFile)
 	}
 	_, err = vitePkgJsonFile.WriteString(utils.ProcessString(string(vitePackageJson), &models.ViteJsonFileProps{
 		Namespace: viteNamespace,
 	}, "{{", "}}"))
 	if err != nil {
-		ctx.CommandError(err.Error(), utils.ErrorInvalidTemplate)
+		return ctx.CommandError(err.Error(), utils.ErrorInvalidTemplate)
 	}
 
 	tsConfigBaseFile, err := os.Create(filepath.Join(ctx.WorkspaceDir, "tsconfig.base.json"))
 	if err != nil {
-		ctx.CommandError(err.Error(), utils.ErrorCreateFile)
+		return ctx.CommandError(err.Error(), utils.ErrorCreateFile)
 	}
 	_, err = tsConfigBaseFile.WriteString(utils.ProcessString(string(tsconfigBaseJson), &models.TsConfigJsonFileProps{
 		Namespace: viteNamespace,
 	}, "{{", "}}"))
 	if err != nil {
-		ctx.CommandError(err.Error(), utils.ErrorInvalidTemplate)
+		return ctx.CommandError(err.Error(), utils.ErrorInvalidTemplate)
 	}
 
-	changesetConfigFile, err := os.Create(filepath.Join(ctx.WorkspaceDir, ".changeset/config.json"))
-	if err != nil {
-		ctx.CommandError(err.Error(), utils.ErrorCreateFile)
-	}
-	_, err = changesetConfigFile.WriteString(utils.ProcessString(string(changesetConfigJson), &models.PackageJsonFileProps{
-		Namespace: ctx.Repo,
-	}, "{{", "}}"))
-	if err != nil {
-		ctx.CommandError(err.Error(), utils.ErrorInvalidTemplate)
+	if _, err := WriteTemplateFile(ctx.WorkspaceDir, ChangesetTemplate(ctx.Repo), true); err != nil {
+		return ctx.CommandError(err.Error(), utils.ErrorInvalidTemplate)
 	}
 
-	sublimeConfigFile, err := os.Create(filepath.Join(ctx.WorkspaceDir, ".sublime.json"))
+	err = core.NewWorkspaceStore(filepath.Join(ctx.WorkspaceDir, ".sublime.json")).Save(&models.SublimeJsonFileProps{
+		Namespace:      rootNamespace,
+		Name:           slug.Make(ctx.Name),
+		Repo:           ctx.Repo,
+		Root:           "./",
+		Organization:   ctx.Organization,
+		ID:             "",
+		Description:    ctx.Description,
+		PackageManager: string(ctx.PackageManager),
+	})
 	if err != nil {
-		ctx.CommandError(err.Error(), utils.ErrorCreateFile)
+		return ctx.CommandError(err.Error(), utils.ErrorCreateFile)
 	}
-	_, err = sublimeConfigFile.WriteString(utils.ProcessString(string(sublimeConfigJson), &models.SublimeJsonFileProps{
-		Namespace:    rootNamespace,
-		Name:         slug.Make(ctx.Name),
-		Repo:         ctx.Repo,
-		Root:         "./",
-		Organization: ctx.Organization,
-		ID:           "",
-		Description:  ctx.Description,
-	}, "{{", "}}"))
-	if err != nil {
-		ctx.CommandError(err.Error(), utils.ErrorInvalidTemplate)
+
+	if err := manager.Configure(ctx.WorkspaceDir); err != nil {
+		return ctx.CommandError(err.Error(), utils.ErrorCreateFile)
 	}
 
 	readmeFile, err := os.Create(filepath.Join(ctx.WorkspaceDir, "README.md"))
 	if err != nil {
-		ctx.CommandError(err.Error(), utils.ErrorCreateFile)
+		return ctx.CommandError(err.Error(), utils.ErrorCreateFile)
 	}
 	_, err = readmeFile.WriteString(utils.ProcessString(string(readmeConfigJson), &models.ReadmeFileProps{
 		Name:         ctx.Name,
@@ -256,166 +299,265 @@ func (ctx *CreateWorkspace) CreateWorkTree(cmd *cobra.Command) {
 		Organization: ctx.Organization,
 	}, "{{", "}}"))
 	if err != nil {
-		ctx.CommandError(err.Error(), utils.ErrorInvalidTemplate)
+		return ctx.CommandError(err.Error(), utils.ErrorInvalidTemplate)
 	}
 
-	config.DoneProgress()
+	return nil
 }
 
-func (ctx *CreateWorkspace) Workflows() {
+func (ctx *CreateWorkspace) Workflows() error {
 	config := core.GetConfig()
-	config.AddTracker()
 
-	app := core.GetApp()
+	config.Progress.Step(utils.MessageCommandWorkspaceProgressWorkflows)
+	for _, workflow := range WorkflowTemplates(ctx.Organization, core.NewPackageManager(ctx.PackageManager)) {
+		if _, err := WriteTemplateFile(ctx.WorkspaceDir, workflow, true); err != nil {
+			return ctx.CommandError(err.Error(), utils.ErrorInvalidTemplate)
+		}
+	}
 
-	go config.Progress.Render()
+	return nil
+}
 
-	config.UpdateProgress(utils.MessageCommandWorkspaceProgressWorkflows, 2)
-	releaseYaml, err := FileTemplates.ReadFile("templates/workflow-release.yaml")
-	if err != nil {
-		ctx.CommandError(err.Error(), utils.ErrorInvalidTemplate)
-	}
-	featureYaml, err := FileTemplates.ReadFile("templates/workflow-feature.yaml")
-	if err != nil {
-		ctx.CommandError(err.Error(), utils.ErrorInvalidTemplate)
-	}
-	artifactYaml, err := FileTemplates.ReadFile("templates/workflow-artifact.yaml")
-	if err != nil {
-		ctx.CommandError(err.Error(), utils.ErrorInvalidTemplate)
-	}
-	snapshotYaml, err := FileTemplates.ReadFile("templates/workflow-snapshot.yaml")
-	if err != nil {
-		ctx.CommandError(err.Error(), utils.ErrorInvalidTemplate)
-	}
+func (ctx *CreateWorkspace) InitGit() error {
+	config := core.GetConfig()
 
-	releaseYamlFile, err := os.Create(filepath.Join(ctx.WorkspaceDir, ".github/workflows/release.yaml"))
-	if err != nil {
-		ctx.CommandError(err.Error(), utils.ErrorCreateFile)
-	}
-	_, err = releaseYamlFile.WriteString(utils.ProcessString(string(releaseYaml), &models.ReleaseYamlFileProps{
-		Username: app.Author.Username,
-		Email:    app.Author.Email,
-		Scope:    fmt.Sprintf("@%s", ctx.Organization),
-	}, "[[", "]]"))
-	if err != nil {
-		ctx.CommandError(err.Error(), utils.ErrorInvalidTemplate)
+	config.Progress.Step(utils.MessageCommandWorkspaceProgressGit)
+	_ = os.RemoveAll(filepath.Join(ctx.WorkspaceDir, ".git"))
+	if _, err := core.InitGitRepo(ctx.WorkspaceDir); err != nil {
+		return ctx.CommandError(err.Error(), utils.ErrorInvalidGit)
 	}
 
-	featureYamlFile, err := os.Create(filepath.Join(ctx.WorkspaceDir, ".github/workflows/feature.yaml"))
-	if err != nil {
-		ctx.CommandError(err.Error(), utils.ErrorCreateFile)
-	}
-	_, err = featureYamlFile.WriteString(utils.ProcessString(string(featureYaml), &models.ArtifactsYamlFileProps{
-		Version: Version,
-	}, "[[", "]]"))
-	if err != nil {
-		ctx.CommandError(err.Error(), utils.ErrorInvalidTemplate)
-	}
+	return nil
+}
 
-	artifactYamlFile, err := os.Create(filepath.Join(ctx.WorkspaceDir, ".github/workflows/artifact.yaml"))
-	if err != nil {
-		ctx.CommandError(err.Error(), utils.ErrorCreateFile)
-	}
-	_, err = artifactYamlFile.WriteString(utils.ProcessString(string(artifactYaml), &models.ArtifactsYamlFileProps{
-		Version: Version,
-	}, "[[", "]]"))
-	if err != nil {
-		ctx.CommandError(err.Error(), utils.ErrorInvalidTemplate)
-	}
+func (ctx *CreateWorkspace) InitPackageManager() error {
+	config := core.GetConfig()
+	manager := core.NewPackageManager(ctx.PackageManager)
 
-	snapshotYamlFile, err := os.Create(filepath.Join(ctx.WorkspaceDir, ".github/workflows/snapshot.yaml"))
-	if err != nil {
-		ctx.CommandError(err.Error(), utils.ErrorCreateFile)
+	config.Progress.Step(fmt.Sprintf(utils.MessageCommandWorkspaceProgressYarn, ctx.PackageManager))
+	if err := manager.Install(commandContext(), ctx.WorkspaceDir); err != nil {
+		return ctx.CommandError(err.Error(), utils.ErrorInvalidYarn)
 	}
-	_, err = snapshotYamlFile.WriteString(utils.ProcessString(string(snapshotYaml), &models.SnapshotsYamlFileProps{
-		Version: Version,
-	}, "[[", "]]"))
-	if err != nil {
-		ctx.CommandError(err.Error(), utils.ErrorInvalidTemplate)
+
+	return nil
+}
+
+func (ctx *CreateWorkspace) BuildVitePlugin() error {
+	config := core.GetConfig()
+	manager := core.NewPackageManager(ctx.PackageManager)
+
+	config.Progress.Step(utils.MessageCommandWorkspaceProgressVite)
+	if err := manager.Run(commandContext(), ctx.WorkspaceDir, "build"); err != nil {
+		return ctx.CommandError(err.Error(), utils.ErrorInvalidBuild)
 	}
 
-	config.DoneProgress()
+	return nil
 }
 
-func (ctx *CreateWorkspace) InitGit() {
+func (ctx *CreateWorkspace) CreateCloudWorkspace() error {
 	config := core.GetConfig()
-	config.AddTracker()
+	app := core.GetApp()
 
-	go config.Progress.Render()
+	config.Progress.Step(utils.MessageCommandWorkspaceProgressCloud)
+	supabase := api.NewSupabase(utils.ApiUrl, utils.ApiKey, app.Author.Token, "production")
+	workspaces, err := supabase.CreateOrganizationWorkspace(commandContext(), ctx.Name, ctx.Repo, ctx.Description, app.OrganizationID)
+	if err != nil {
+		config.Progress.Fail(fmt.Sprintf("Error: %s", utils.ErrorInvalidCloudOperation))
+		return utils.NewCliError(err.Error(), utils.ErrorInvalidCloudOperation)
+	}
 
-	config.UpdateProgress(utils.MessageCommandWorkspaceProgressGit, 2)
-	_ = os.RemoveAll(filepath.Join(ctx.WorkspaceDir, ".git"))
-	_, err := utils.InitGit(ctx.WorkspaceDir)
+	err = app.UpdateWorkspace(&workspaces[0])
 	if err != nil {
-		ctx.CommandError(err.Error(), utils.ErrorInvalidGit)
+		_, _ = supabase.DeleteWorkspaceByID(commandContext(), workspaces[0].ID)
+		config.Progress.Fail(fmt.Sprintf("Error: %s", utils.ErrorInvalidWorkspace))
+		return utils.NewCliError(err.Error(), utils.ErrorInvalidWorkspace)
 	}
 
-	config.DoneProgress()
+	config.Progress.Done()
+	utils.GetOutput().SetResult(&models.WorkspaceCreateResult{Workspace: workspaces[0], Dir: ctx.WorkspaceDir})
+	utils.SuccessOut(utils.MessageCommandWorkspaceSuccess)
+
+	return nil
 }
 
-func (ctx *CreateWorkspace) InitYarn() {
+func (ctx *CreateWorkspace) CommandError(message string, errorType utils.ErrorType) error {
 	config := core.GetConfig()
-	config.AddTracker()
 
-	go config.Progress.Render()
+	if ctx.WorkspaceDir != "" {
+		os.RemoveAll(ctx.WorkspaceDir)
+	}
+
+	config.Progress.Fail(fmt.Sprintf("Error: %s", errorType))
 
-	config.UpdateProgress(utils.MessageCommandWorkspaceProgressYarn, 2)
-	_, err := utils.YarnInstall(ctx.WorkspaceDir)
-	if err != nil {
-		ctx.CommandError(err.Error(), utils.ErrorInvalidYarn)
+	return utils.NewCliError(message, errorType)
+}
+
+func NewWorkspaceListCmd(cmdInfo *WorkspaceInfoFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   utils.CommandWorkspaceList,
+		Short: utils.MessageCommandWorkspaceListShort,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdInfo.List(cmd)
+		},
 	}
+}
 
-	config.DoneProgress()
+func NewWorkspaceShowCmd(cmdInfo *WorkspaceInfoFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   fmt.Sprintf("%s [workspace-id]", utils.CommandWorkspaceShow),
+		Short: utils.MessageCommandWorkspaceShowShort,
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			workspaceID := ""
+			if len(args) > 0 {
+				workspaceID = args[0]
+			}
+
+			return cmdInfo.Show(cmd, workspaceID)
+		},
+	}
 }
 
-func (ctx *CreateWorkspace) BuildVitePlugin() {
-	config := core.GetConfig()
-	config.AddTracker()
+func (ctx *WorkspaceInfoFlags) List(cmd *cobra.Command) error {
+	app := core.GetApp()
 
-	go config.Progress.Render()
+	supabase := api.NewSupabase(utils.ApiUrl, utils.ApiKey, app.Author.Token, "production")
+	orgFlags := &OrgFlags{Organization: ctx.Organization}
+	org, err := orgFlags.ResolveOrganization(cmd, supabase)
+	if err != nil {
+		return err
+	}
 
-	config.UpdateProgress(utils.MessageCommandWorkspaceProgressVite, 2)
-	_, err := utils.YarnBuild(ctx.WorkspaceDir)
+	workspaces, err := supabase.GetWorkspacesByOrganization(cmd.Context(), org.Organization.ID)
 	if err != nil {
-		ctx.CommandError(err.Error(), utils.ErrorInvalidBuild)
+		return utils.NewCliError(err.Error(), utils.ErrorInvalidCloudOperation)
+	}
+
+	current := ""
+	if sublime, err := app.ReadSublime(); err == nil {
+		current = sublime.ID
+	}
+	result := []models.WorkspaceResult{}
+
+	for _, workspace := range workspaces {
+		result = append(result, models.WorkspaceResult{
+			WorkspacesByOrganizationResponse: workspace,
+			Selected:                         workspace.ID == current,
+		})
 	}
 
-	config.DoneProgress()
+	render(result, func() {
+		tabular := table.NewWriter()
+		tabular.SetStyle(table.StyleBold)
+		tabular.AppendHeader(table.Row{"", "ID", "Name", "Repo", "Description", "Created", "Created by"})
+
+		for _, workspace := range result {
+			selected := ""
+			if workspace.Selected {
+				selected = "*"
+			}
+
+			tabular.AppendRow(table.Row{selected, workspace.ID, workspace.Name, workspace.Repo, workspace.Description, workspace.CreatedAt, workspace.CreatedBy})
+		}
+
+		tabular.AppendFooter(table.Row{fmt.Sprintf("Organization: %s", org.Organization.Name)})
+
+		fmt.Println(tabular.Render())
+	})
+
+	return nil
 }
 
-func (ctx *CreateWorkspace) CreateCloudWorkspace() {
-	config := core.GetConfig()
-	config.AddTracker()
+// Show prints the cloud workspace (default to the one on .sublime.json) and
+// its packages, flagging the ones unknown to .sublime.json.
+func (ctx *WorkspaceInfoFlags) Show(cmd *cobra.Command, workspaceID string) error {
 	app := core.GetApp()
+	sublime := &models.SublimeJsonFileProps{}
+
+	if core.GetConfig().HasSublime() {
+		workspace, err := app.ReadSublime()
+		if err != nil {
+			return utils.NewCliError(err.Error(), utils.ErrorInvalidWorkspace)
+		}
+		sublime = workspace
+	}
+
+	if workspaceID == "" {
+		workspaceID = sublime.ID
+	}
 
-	go config.Progress.Render()
+	if workspaceID == "" {
+		return utils.NewCliError(utils.MessageErrorCommandTokenWorkspace, utils.ErrorInvalidWorkspace)
+	}
 
-	config.UpdateProgress(utils.MessageCommandWorkspaceProgressCloud, 2)
 	supabase := api.NewSupabase(utils.ApiUrl, utils.ApiKey, app.Author.Token, "production")
-	workspaces, err := supabase.CreateOrganizationWorkspace(ctx.Name, ctx.Repo, ctx.Description, app.OrganizationID)
+	workspace, err := supabase.GetWorkspaceByID(cmd.Context(), workspaceID)
 	if err != nil {
-		utils.ErrorOut(err.Error(), utils.ErrorInvalidCloudOperation)
+		return utils.NewCliError(err.Error(), utils.ErrorInvalidWorkspace)
 	}
 
-	config.UpdateProgress(utils.MessageCommandWorkspaceProgressCloud, 6)
-	err = app.UpdateWorkspace(&workspaces[0])
+	packages, err := supabase.GetPackagesByWorkspace(cmd.Context(), workspaceID)
 	if err != nil {
-		_, _ = supabase.DeleteWorkspaceByID(workspaces[0].ID)
-		utils.ErrorOut(err.Error(), utils.ErrorInvalidWorkspace)
+		return utils.NewCliError(err.Error(), utils.ErrorInvalidCloudOperation)
 	}
 
-	config.UpdateProgress(utils.MessageCommandWorkspaceProgressCloud, 1)
-	config.TerminateProgress()
-	utils.SuccessOut(utils.MessageCommandWorkspaceSuccess)
-}
+	local := map[string]bool{}
+	if workspaceID == sublime.ID {
+		for _, pkg := range sublime.Packages {
+			local[pkg.ID] = true
+			local[pkg.Name] = true
+		}
+	}
 
-func (ctx *CreateWorkspace) CommandError(message string, errorType utils.ErrorType) {
-	config := core.GetConfig()
+	result := &models.WorkspaceShowResult{
+		Workspace: workspace,
+		Packages:  []models.WorkspacePackageResult{},
+	}
 
-	if ctx.WorkspaceDir != "" {
-		os.RemoveAll(ctx.WorkspaceDir)
+	for _, pkg := range packages {
+		item := models.WorkspacePackageResult{Package: pkg}
+		if workspaceID == sublime.ID {
+			tracked := local[pkg.ID] || local[pkg.Name]
+			item.Local = &tracked
+		}
+
+		result.Packages = append(result.Packages, item)
 	}
 
-	config.TerminateErrorProgress(fmt.Sprintf("Error: %s", errorType))
-	utils.ErrorOut(message, errorType)
+	render(result, func() {
+		tabular := table.NewWriter()
+		tabular.SetStyle(table.StyleBold)
+		tabular.AppendHeader(table.Row{fmt.Sprintf("Workspace: %s", workspace.Name)})
+		tabular.AppendRow(table.Row{"ID", workspace.ID})
+		tabular.AppendRow(table.Row{"Repo", workspace.Repo})
+		tabular.AppendRow(table.Row{"Description", workspace.Description})
+		tabular.AppendRow(table.Row{"Organization", workspace.OrganizationID})
+		tabular.AppendRow(table.Row{"Private", workspace.Private})
+		tabular.AppendRow(table.Row{"Created", workspace.CreatedAt})
+		tabular.AppendRow(table.Row{"Created by", workspace.CreatedBy})
+
+		fmt.Println(tabular.Render())
+
+		packagesTable := table.NewWriter()
+		packagesTable.SetStyle(table.StyleBold)
+		packagesTable.AppendHeader(table.Row{"ID", "Name", "Type", "Template", "Version", "Created", "Local"})
+
+		for _, pkg := range result.Packages {
+			tracked := "-"
+			if pkg.Local != nil {
+				tracked = "no"
+				if *pkg.Local {
+					tracked = "yes"
+				}
+			}
+
+			packagesTable.AppendRow(table.Row{pkg.ID, pkg.Name, pkg.Type, pkg.Template, pkg.Version, pkg.CreatedAt, tracked})
+		}
+
+		packagesTable.AppendFooter(table.Row{fmt.Sprintf("Packages: %d", len(packages))})
+
+		fmt.Println(packagesTable.Render())
+	})
+
+	return nil
 }
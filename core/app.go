@@ -50,7 +50,7 @@ func GetApp() *App {
 
 func (ctx *App) InitAuthor() error {
 	config := GetConfig()
-	rcFile := filepath.Join(config.HomeDir, ".sublime/rc.json")
+	rcFile := config.AuthorFile()
 	rcJson, err := os.ReadFile(rcFile)
 	if err != nil {
 		return errors.New(utils.MessageErrorAuthorFileMissing)
@@ -71,8 +71,8 @@ func (ctx *App) InitAuthor() error {
 func (ctx *App) UpdateAuthorMetadata(author *models.AuthorFileProps) error {
 	config := GetConfig()
 
-	sublimeDir := filepath.Join(config.HomeDir, ".sublime")
-	rcFile := filepath.Join(sublimeDir, "rc.json")
+	rcFile := config.AuthorFile()
+	sublimeDir := filepath.Dir(rcFile)
 	if _, err := os.Stat(rcFile); os.IsNotExist(err) {
 		utils.WarningOut(utils.MessageErrorAuthorFileMissing)
 	}
@@ -88,7 +88,7 @@ func (ctx *App) UpdateAuthorMetadata(author *models.AuthorFileProps) error {
 		}
 	}
 
-	err = os.WriteFile(rcFile, data, 0644)
+	err = os.WriteFile(rcFile, data, 0600)
 	if err != nil {
 		return errors.New(utils.MessageErrorWriteFile)
 	}
@@ -98,102 +98,48 @@ func (ctx *App) UpdateAuthorMetadata(author *models.AuthorFileProps) error {
 
 func (ctx *App) UpdateWorkspace(workspace *models.Workspace) error {
 	config := GetConfig()
-	sublimeFile := filepath.Join(config.RootDir, workspace.Name, ".sublime.json")
-	sublimeJson, err := os.ReadFile(sublimeFile)
-	if err != nil {
-		return errors.New(utils.MessageErrorReadFile)
-	}
-
-	sublimeMetadata := models.SublimeJsonFileProps{}
-
-	err = json.Unmarshal(sublimeJson, &sublimeMetadata)
-	if err != nil {
-		return errors.New(utils.MessageErrorParseFile)
-	}
-
-	sublimeMetadata.ID = workspace.ID
+	store := NewWorkspaceStore(filepath.Join(config.RootDir, workspace.Name, ".sublime.json"))
 
-	data, err := json.MarshalIndent(sublimeMetadata, "", " ")
-	if err != nil {
-		return errors.New(utils.MessageErrorIndentFile)
-	}
-
-	err = os.WriteFile(sublimeFile, data, 0644)
-	if err != nil {
-		return errors.New(utils.MessageErrorWriteFile)
-	}
+	return store.Update(func(sublime *models.SublimeJsonFileProps) error {
+		sublime.ID = workspace.ID
 
-	return nil
+		return nil
+	})
 }
 
 func (ctx *App) UpdatePackage(packages *models.Package) error {
-	config := GetConfig()
-	sublimeFile := filepath.Join(config.RootDir, ".sublime.json")
-	sublimeJson, err := os.ReadFile(sublimeFile)
-	if err != nil {
-		return errors.New(utils.MessageErrorAuthorFileMissing)
-	}
-
-	sublimeMetadata := models.SublimeJsonFileProps{}
-
-	err = json.Unmarshal(sublimeJson, &sublimeMetadata)
-	if err != nil {
-		return errors.New(utils.MessageErrorParseFile)
-	}
-
-	for i := range sublimeMetadata.Packages {
-		if sublimeMetadata.Packages[i].Name == packages.Name {
-			sublimeMetadata.Packages[i].ID = packages.ID
-			break
+	return ctx.UpdateSublime(func(sublime *models.SublimeJsonFileProps) error {
+		for i := range sublime.Packages {
+			if sublime.Packages[i].Name == packages.Name {
+				sublime.Packages[i].ID = packages.ID
+				break
+			}
 		}
-	}
 
-	data, err := json.MarshalIndent(sublimeMetadata, "", " ")
-	if err != nil {
-		return errors.New(utils.MessageErrorIndentFile)
-	}
+		return nil
+	})
+}
 
-	err = os.WriteFile(sublimeFile, data, 0644)
-	if err != nil {
-		return errors.New(utils.MessageErrorWriteFile)
-	}
+// UpdatePackages replaces the packages list on .sublime.json.
+func (ctx *App) UpdatePackages(packages []models.SublimePackages) error {
+	return ctx.UpdateSublime(func(sublime *models.SublimeJsonFileProps) error {
+		sublime.Packages = packages
 
-	return nil
+		return nil
+	})
 }
 
 func (ctx *App) RemoveConfigurationsOnPackageError(packageName string, packageType string) error {
 	config := GetConfig()
 
 	tsFile := filepath.Join(config.RootDir, "tsconfig.base.json")
-	sublimeFile := filepath.Join(config.RootDir, ".sublime.json")
-	sublimeJson, err := os.ReadFile(sublimeFile)
-	if err != nil {
-		return errors.New(utils.MessageErrorAuthorFileMissing)
-	}
-
-	sublimeMetadata := models.SublimeJsonFileProps{}
-
-	err = json.Unmarshal(sublimeJson, &sublimeMetadata)
-	if err != nil {
-		return errors.New(utils.MessageErrorParseFile)
-	}
-
 	tsconfig, err := ctx.GetTsconfig()
 	if err != nil {
 		return err
 	}
 
-	var sublimePackages []models.SublimePackages = []models.SublimePackages{}
 	var configReferences []models.TsConfigReferences = []models.TsConfigReferences{}
 
-	for _, pkg := range sublimeMetadata.Packages {
-		if pkg.Name != packageName {
-			sublimePackages = append(sublimePackages, pkg)
-		}
-	}
-
-	sublimeMetadata.Packages = sublimePackages
-
 	for _, cfg := range tsconfig.References {
 		if cfg.Path != strings.Join([]string{packageType, packageName}, "/") {
 			configReferences = append(configReferences, cfg)
@@ -202,19 +148,26 @@ func (ctx *App) RemoveConfigurationsOnPackageError(packageName string, packageTy
 
 	tsconfig.References = configReferences
 
-	sublimeData, err := json.MarshalIndent(sublimeMetadata, "", " ")
-	if err != nil {
-		return errors.New(utils.MessageErrorIndentFile)
-	}
-
 	tsData, err := json.MarshalIndent(tsconfig, "", " ")
 	if err != nil {
 		return errors.New(utils.MessageErrorIndentFile)
 	}
 
-	err = os.WriteFile(sublimeFile, sublimeData, 0644)
+	err = ctx.UpdateSublime(func(sublime *models.SublimeJsonFileProps) error {
+		sublimePackages := []models.SublimePackages{}
+
+		for _, pkg := range sublime.Packages {
+			if pkg.Name != packageName {
+				sublimePackages = append(sublimePackages, pkg)
+			}
+		}
+
+		sublime.Packages = sublimePackages
+
+		return nil
+	})
 	if err != nil {
-		return errors.New(utils.MessageErrorWriteFile)
+		return err
 	}
 
 	err = os.WriteFile(tsFile, tsData, 0644)
@@ -0,0 +1,436 @@
+/*
+Copyright © 2022 Websublime.dev organization@websublime.dev
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package core
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/websublime/sublime-cli/models"
+	"github.com/websublime/sublime-cli/utils"
+)
+
+// ChangesetDir is the folder of the changesets and their config.
+const ChangesetDir = ".changeset"
+
+var (
+	changesetRelease = regexp.MustCompile(`^\s*["']?([^"':]+)["']?\s*:\s*["']?(major|minor|patch|none)["']?\s*$`)
+	packageVersion   = regexp.MustCompile(`("version"\s*:\s*")([^"]*)(")`)
+
+	changesetAdjectives = []string{"brave", "calm", "clever", "eager", "fancy", "gentle", "happy", "jolly", "kind", "lucky", "mighty", "nice", "proud", "quiet", "silly", "swift"}
+	changesetNouns      = []string{"ants", "bears", "cats", "dogs", "eagles", "foxes", "geese", "hounds", "lions", "moles", "owls", "pumas", "rats", "seals", "tigers", "wolves"}
+	changesetVerbs      = []string{"build", "climb", "dance", "dream", "fly", "hide", "jump", "laugh", "listen", "play", "run", "sing", "sleep", "swim", "talk", "walk"}
+)
+
+// ReadChangesetConfig reads .changeset/config.json, with the changesets
+// defaults for missing fields.
+func (ctx *Config) ReadChangesetConfig() models.ChangesetConfig {
+	config := models.ChangesetConfig{}
+
+	if data, err := os.ReadFile(filepath.Join(ctx.RootDir, ChangesetDir, "config.json")); err == nil {
+		_ = json.Unmarshal(data, &config)
+	}
+
+	if config.BaseBranch == "" {
+		config.BaseBranch = GitDefaultBranch
+	}
+	if config.UpdateInternalDependencies == "" {
+		config.UpdateInternalDependencies = utils.BumpPatch
+	}
+
+	return config
+}
+
+// ReadChangesets reads the pending .changeset/*.md files, by id.
+func (ctx *Config) ReadChangesets() ([]models.Changeset, error) {
+	changesets := []models.Changeset{}
+
+	files, err := filepath.Glob(filepath.Join(ctx.RootDir, ChangesetDir, "*.md"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		if strings.EqualFold(filepath.Base(file), "README.md") {
+			continue
+		}
+
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		changeset, err := ParseChangeset(strings.TrimSuffix(filepath.Base(file), ".md"), string(data))
+		if err != nil {
+			return nil, err
+		}
+
+		changesets = append(changesets, *changeset)
+	}
+
+	return changesets, nil
+}
+
+// ParseChangeset parses the front matter of releases ("@scope/name": minor)
+// and the summary of a changeset file.
+func ParseChangeset(id string, content string) (*models.Changeset, error) {
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return nil, fmt.Errorf(utils.MessageErrorCommandChangesetFrontMatter, id)
+	}
+
+	changeset := &models.Changeset{ID: id, Releases: []models.ChangesetRelease{}}
+
+	for idx := 1; idx < len(lines); idx++ {
+		line := strings.TrimSpace(lines[idx])
+
+		if line == "---" {
+			changeset.Summary = strings.TrimSpace(strings.Join(lines[idx+1:], "\n"))
+			return changeset, nil
+		}
+
+		if line == "" {
+			continue
+		}
+
+		match := changesetRelease.FindStringSubmatch(line)
+		if match == nil {
+			return nil, fmt.Errorf(utils.MessageErrorCommandChangesetParse, id, line)
+		}
+
+		changeset.Releases = append(changeset.Releases, models.ChangesetRelease{Name: strings.TrimSpace(match[1]), Type: utils.BumpType(match[2])})
+	}
+
+	return nil, fmt.Errorf(utils.MessageErrorCommandChangesetFrontMatter, id)
+}
+
+// FormatChangeset is the content of a changeset file, as written by the
+// changesets cli.
+func FormatChangeset(changeset *models.Changeset) string {
+	var content strings.Builder
+
+	content.WriteString("---\n")
+	for _, release := range changeset.Releases {
+		content.WriteString(fmt.Sprintf("\"%s\": %s\n", release.Name, release.Type))
+	}
+	content.WriteString("---\n\n")
+	content.WriteString(strings.TrimSpace(changeset.Summary))
+	content.WriteString("\n")
+
+	return content.String()
+}
+
+// WriteChangeset writes the changeset on .changeset/<id>.md, with a new
+// random id (ex: brave-dogs-jump) when it has none.
+func (ctx *Config) WriteChangeset(changeset *models.Changeset) (string, error) {
+	dir := filepath.Join(ctx.RootDir, ChangesetDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	for changeset.ID == "" {
+		id := strings.Join([]string{randomWord(changesetAdjectives), randomWord(changesetNouns), randomWord(changesetVerbs)}, "-")
+		if _, err := os.Stat(filepath.Join(dir, id+".md")); os.IsNotExist(err) {
+			changeset.ID = id
+		}
+	}
+
+	path := filepath.Join(dir, changeset.ID+".md")
+
+	return path, os.WriteFile(path, []byte(FormatChangeset(changeset)), 0644)
+}
+
+func randomWord(words []string) string {
+	idx, err := rand.Int(rand.Reader, big.NewInt(int64(len(words))))
+	if err != nil {
+		return words[0]
+	}
+
+	return words[idx.Int64()]
+}
+
+// PlanVersions are the version bumps of the changesets: the highest bump of
+// each package, and the bumps of their dependents, like changesets does. A
+// minor or major bump of a peer dependency is a major of the dependent, other
+// bumps are a patch of the dependents whose range is updated. Ranges are
+// updated when the new version leaves them, or when the bump reaches
+// updateInternalDependencies.
+func PlanVersions(graph *WorkspaceGraph, changesets []models.Changeset, config models.ChangesetConfig) []models.VersionBump {
+	bumps := map[string]*models.VersionBump{}
+	pending := []string{}
+
+	bump := func(node *WorkspacePackage) *models.VersionBump {
+		if _, ok := bumps[node.Name]; !ok {
+			bumps[node.Name] = &models.VersionBump{Name: node.Name, Path: node.Path, Version: node.Version, Type: utils.BumpNone, Changesets: []string{}}
+			pending = append(pending, node.Name)
+		}
+
+		return bumps[node.Name]
+	}
+
+	for _, changeset := range changesets {
+		for _, release := range changeset.Releases {
+			node := graph.Find(release.Name)
+			if node == nil || release.Type == utils.BumpNone || utils.Contains(config.Ignore, node.Name) {
+				continue
+			}
+
+			version := bump(node)
+			version.Type = maxBump(version.Type, release.Type)
+			if !utils.Contains(version.Changesets, changeset.ID) {
+				version.Changesets = append(version.Changesets, changeset.ID)
+			}
+		}
+	}
+
+	for len(pending) > 0 {
+		name := pending[0]
+		pending = pending[1:]
+
+		version := bumps[name]
+		newVersion := utils.BumpVersion(version.Version, version.Type)
+
+		for _, dependent := range graph.Dependents(name) {
+			if utils.Contains(config.Ignore, dependent.Name) {
+				continue
+			}
+
+			dependentType := utils.BumpNone
+			if _, peer := dependent.PeerDependencies[name]; peer && bumpRank(version.Type) >= bumpRank(utils.BumpMinor) {
+				dependentType = utils.BumpMajor
+			} else if updatesRange(dependent.Dependencies[name], newVersion, version.Type, config) {
+				dependentType = utils.BumpPatch
+			}
+
+			if dependentType == utils.BumpNone {
+				continue
+			}
+
+			dependentVersion := bump(dependent)
+			if raised := maxBump(dependentVersion.Type, dependentType); raised != dependentVersion.Type {
+				dependentVersion.Type = raised
+
+				// A package already planned is planned again, its dependents
+				// may need a higher bump for its new version.
+				if !utils.Contains(pending, dependent.Name) {
+					pending = append(pending, dependent.Name)
+				}
+			}
+
+			if !utils.Contains(dependentVersion.Dependencies, name) {
+				dependentVersion.Dependencies = append(dependentVersion.Dependencies, name)
+			}
+		}
+	}
+
+	plan := []models.VersionBump{}
+	for _, version := range bumps {
+		version.NewVersion = utils.BumpVersion(version.Version, version.Type)
+		plan = append(plan, *version)
+	}
+
+	sort.Slice(plan, func(i, j int) bool {
+		return plan[i].Name < plan[j].Name
+	})
+
+	return plan
+}
+
+// ApplyVersions writes the new versions on the package.json of the bumped
+// packages, updates the ranges of their dependents (dependencies,
+// devDependencies and peerDependencies), prepends the releases to
+// the CHANGELOG.md of the packages. It returns the changed files, relative to
+// the root.
+func (ctx *Config) ApplyVersions(graph *WorkspaceGraph, changesets []models.Changeset, plan []models.VersionBump, config models.ChangesetConfig) ([]string, error) {
+	files := []string{}
+	versions := map[string]models.VersionBump{}
+	for _, version := range plan {
+		versions[version.Name] = version
+	}
+
+	for _, node := range graph.Packages {
+		path := filepath.Join(ctx.PackageDir(node.Package), "package.json")
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return files, err
+		}
+		content := string(data)
+
+		if version, ok := versions[node.Name]; ok {
+			if loc := packageVersion.FindStringSubmatchIndex(content); loc != nil {
+				content = content[:loc[4]] + version.NewVersion + content[loc[5]:]
+			}
+		}
+
+		// The graph merges the peers into the dependencies, so the ranges
+		// are read from each section of the package.json.
+		manifest := &models.PackageManifest{}
+		if err := json.Unmarshal(data, manifest); err != nil {
+			return files, err
+		}
+
+		for _, dependencies := range []map[string]string{manifest.Dependencies, manifest.DevDependencies, manifest.PeerDependencies} {
+			for name, rangeVersion := range dependencies {
+				version, ok := versions[name]
+				if !ok || !updatesRange(rangeVersion, version.NewVersion, version.Type, config) {
+					continue
+				}
+
+				_, base := utils.SplitRange(rangeVersion)
+				dependency := regexp.MustCompile(`("` + regexp.QuoteMeta(name) + `"\s*:\s*")` + regexp.QuoteMeta(rangeVersion) + `(")`)
+				content = dependency.ReplaceAllString(content, "${1}"+strings.TrimSuffix(rangeVersion, base)+version.NewVersion+"${2}")
+			}
+		}
+
+		if content != string(data) {
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				return files, err
+			}
+			files = append(files, filepath.ToSlash(filepath.Join(node.Path, "package.json")))
+		}
+
+		if version, ok := versions[node.Name]; ok {
+			if err := ctx.prependChangelog(node, version, changesets, versions); err != nil {
+				return files, err
+			}
+			files = append(files, filepath.ToSlash(filepath.Join(node.Path, "CHANGELOG.md")))
+		}
+	}
+
+	return files, nil
+}
+
+// RemoveChangesets deletes the consumed changeset files. It returns the
+// deleted files, relative to the root.
+func (ctx *Config) RemoveChangesets(changesets []models.Changeset) ([]string, error) {
+	files := []string{}
+
+	for _, changeset := range changesets {
+		path := filepath.Join(ctx.RootDir, ChangesetDir, changeset.ID+".md")
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return files, err
+		}
+		files = append(files, filepath.ToSlash(filepath.Join(ChangesetDir, changeset.ID+".md")))
+	}
+
+	return files, nil
+}
+
+// prependChangelog adds the release of version on top of the CHANGELOG.md of
+// the package, in the format of the changesets cli.
+func (ctx *Config) prependChangelog(node *WorkspacePackage, version models.VersionBump, changesets []models.Changeset, versions map[string]models.VersionBump) error {
+	sections := map[utils.BumpType][]string{}
+
+	for _, changeset := range changesets {
+		for _, release := range changeset.Releases {
+			if release.Name != node.Name && release.Name != node.Package.Name {
+				continue
+			}
+
+			lines := strings.Split(strings.TrimSpace(changeset.Summary), "\n")
+			for idx := 1; idx < len(lines); idx++ {
+				if strings.TrimSpace(lines[idx]) != "" {
+					lines[idx] = "  " + lines[idx]
+				}
+			}
+			sections[release.Type] = append(sections[release.Type], "- "+strings.Join(lines, "\n"))
+		}
+	}
+
+	if len(version.Dependencies) > 0 {
+		lines := []string{"- Updated dependencies"}
+		for _, name := range version.Dependencies {
+			lines = append(lines, fmt.Sprintf("  - %s@%s", name, versions[name].NewVersion))
+		}
+		sections[utils.BumpPatch] = append(sections[utils.BumpPatch], strings.Join(lines, "\n"))
+	}
+
+	release := fmt.Sprintf("## %s\n", version.NewVersion)
+	for _, section := range []struct {
+		bump  utils.BumpType
+		title string
+	}{{utils.BumpMajor, "Major Changes"}, {utils.BumpMinor, "Minor Changes"}, {utils.BumpPatch, "Patch Changes"}} {
+		if len(sections[section.bump]) > 0 {
+			release += fmt.Sprintf("\n### %s\n\n%s\n", section.title, strings.Join(sections[section.bump], "\n"))
+		}
+	}
+
+	path := filepath.Join(ctx.PackageDir(node.Package), "CHANGELOG.md")
+	title := fmt.Sprintf("# %s\n", node.Name)
+	changelog := title
+
+	if data, err := os.ReadFile(path); err == nil {
+		changelog = string(data)
+	}
+
+	body := strings.TrimLeft(strings.TrimPrefix(changelog, title), "\n")
+	if body != "" {
+		body = "\n" + body
+	}
+
+	return os.WriteFile(path, []byte(title+"\n"+release+body), 0644)
+}
+
+// updatesRange reports if the range of a dependent is updated to the new
+// version of a dependency: ranges without version (*, workspace:^) never
+// are, ranges left by the new version always are.
+func updatesRange(rangeVersion string, newVersion string, bump utils.BumpType, config models.ChangesetConfig) bool {
+	if _, base := utils.SplitRange(rangeVersion); base == "" {
+		return false
+	}
+
+	if !utils.SatisfiesRange(rangeVersion, newVersion) {
+		return true
+	}
+
+	return bumpRank(bump) >= bumpRank(config.UpdateInternalDependencies)
+}
+
+func maxBump(left utils.BumpType, right utils.BumpType) utils.BumpType {
+	if bumpRank(right) > bumpRank(left) {
+		return right
+	}
+
+	return left
+}
+
+func bumpRank(bump utils.BumpType) int {
+	switch bump {
+	case utils.BumpMajor:
+		return 3
+	case utils.BumpMinor:
+		return 2
+	case utils.BumpPatch:
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,215 @@
+/*
+Copyright © 2022 Websublime.dev organization@websublime.dev
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/websublime/sublime-cli/models"
+	"github.com/websublime/sublime-cli/utils"
+)
+
+func TestPlanVersions(t *testing.T) {
+	graph := &WorkspaceGraph{Packages: []*WorkspacePackage{
+		planPackage("core", nil, nil),
+		planPackage("ui", nil, map[string]string{"core": "^1.0.0"}),
+		planPackage("app", nil, map[string]string{"ui": "^1.0.0"}),
+		planPackage("lib", map[string]string{"core": "1.0.0"}, nil),
+		planPackage("web", map[string]string{"ui": "workspace:^1.0.0"}, nil),
+	}}
+
+	tests := []struct {
+		name       string
+		changesets []models.Changeset
+		config     models.ChangesetConfig
+		plan       []models.VersionBump
+	}{
+		{
+			name:       "patch of a peer dependency",
+			changesets: []models.Changeset{planChangeset("a", "core", utils.BumpPatch)},
+			config:     models.ChangesetConfig{UpdateInternalDependencies: utils.BumpMinor},
+			plan: []models.VersionBump{
+				{Name: "core", Path: "packages/core", Version: "1.0.0", NewVersion: "1.0.1", Type: utils.BumpPatch, Changesets: []string{"a"}},
+				{Name: "lib", Path: "packages/lib", Version: "1.0.0", NewVersion: "1.0.1", Type: utils.BumpPatch, Changesets: []string{}, Dependencies: []string{"core"}},
+			},
+		},
+		{
+			name: "raised bump of a planned package",
+			changesets: []models.Changeset{
+				planChangeset("a", "ui", utils.BumpPatch),
+				planChangeset("b", "core", utils.BumpMinor),
+			},
+			config: models.ChangesetConfig{UpdateInternalDependencies: utils.BumpMajor},
+			plan: []models.VersionBump{
+				{Name: "app", Path: "packages/app", Version: "1.0.0", NewVersion: "2.0.0", Type: utils.BumpMajor, Changesets: []string{}, Dependencies: []string{"ui"}},
+				{Name: "core", Path: "packages/core", Version: "1.0.0", NewVersion: "1.1.0", Type: utils.BumpMinor, Changesets: []string{"b"}},
+				{Name: "lib", Path: "packages/lib", Version: "1.0.0", NewVersion: "1.0.1", Type: utils.BumpPatch, Changesets: []string{}, Dependencies: []string{"core"}},
+				{Name: "ui", Path: "packages/ui", Version: "1.0.0", NewVersion: "2.0.0", Type: utils.BumpMajor, Changesets: []string{"a"}, Dependencies: []string{"core"}},
+				{Name: "web", Path: "packages/web", Version: "1.0.0", NewVersion: "1.0.1", Type: utils.BumpPatch, Changesets: []string{}, Dependencies: []string{"ui"}},
+			},
+		},
+		{
+			name:       "ignored dependents",
+			changesets: []models.Changeset{planChangeset("a", "core", utils.BumpMinor)},
+			config:     models.ChangesetConfig{UpdateInternalDependencies: utils.BumpPatch, Ignore: []string{"ui", "lib"}},
+			plan: []models.VersionBump{
+				{Name: "core", Path: "packages/core", Version: "1.0.0", NewVersion: "1.1.0", Type: utils.BumpMinor, Changesets: []string{"a"}},
+			},
+		},
+		{
+			name: "none bumps",
+			changesets: []models.Changeset{
+				planChangeset("a", "core", utils.BumpNone),
+				planChangeset("b", "missing", utils.BumpMajor),
+			},
+			config: models.ChangesetConfig{UpdateInternalDependencies: utils.BumpPatch},
+			plan:   []models.VersionBump{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			plan := PlanVersions(graph, test.changesets, test.config)
+			if !reflect.DeepEqual(plan, test.plan) {
+				t.Errorf("PlanVersions() = %+v, want %+v", plan, test.plan)
+			}
+		})
+	}
+}
+
+func TestApplyVersions(t *testing.T) {
+	root := t.TempDir()
+	manifests := map[string]string{
+		"core": `{
+  "name": "core",
+  "version": "1.0.0"
+}`,
+		"ui": `{
+  "name": "ui",
+  "version": "1.0.0",
+  "dependencies": {"core": "~1.0.0"},
+  "devDependencies": {"core": "1.0.0"},
+  "peerDependencies": {"core": "^1.0.0"}
+}`,
+		"app": `{
+  "name": "app",
+  "version": "1.0.0",
+  "peerDependencies": {"ui": "^1.0.0", "core": "workspace:*"}
+}`,
+	}
+	for name, manifest := range manifests {
+		if err := os.MkdirAll(filepath.Join(root, "packages", name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(root, "packages", name, "package.json"), []byte(manifest), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	config := &Config{RootDir: root}
+	graph := config.ReadWorkspaceGraph([]models.SublimePackages{
+		{Name: "core", Type: utils.Package},
+		{Name: "ui", Type: utils.Package},
+		{Name: "app", Type: utils.Package},
+	})
+	changesets := []models.Changeset{planChangeset("a", "core", utils.BumpMajor)}
+	changesetConfig := models.ChangesetConfig{UpdateInternalDependencies: utils.BumpPatch}
+
+	plan := PlanVersions(graph, changesets, changesetConfig)
+	if _, err := config.ApplyVersions(graph, changesets, plan, changesetConfig); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		pkg     string
+		section func(*models.PackageManifest) map[string]string
+		want    map[string]string
+	}{
+		{
+			name:    "dependencies",
+			pkg:     "ui",
+			section: func(manifest *models.PackageManifest) map[string]string { return manifest.Dependencies },
+			want:    map[string]string{"core": "~2.0.0"},
+		},
+		{
+			name:    "devDependencies",
+			pkg:     "ui",
+			section: func(manifest *models.PackageManifest) map[string]string { return manifest.DevDependencies },
+			want:    map[string]string{"core": "2.0.0"},
+		},
+		{
+			name:    "peerDependencies shadowing dependencies",
+			pkg:     "ui",
+			section: func(manifest *models.PackageManifest) map[string]string { return manifest.PeerDependencies },
+			want:    map[string]string{"core": "^2.0.0"},
+		},
+		{
+			name:    "peerDependencies of a major bumped dependent",
+			pkg:     "app",
+			section: func(manifest *models.PackageManifest) map[string]string { return manifest.PeerDependencies },
+			want:    map[string]string{"ui": "^2.0.0", "core": "workspace:*"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			manifest, err := ReadPackageManifest(filepath.Join(root, "packages", test.pkg, "package.json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ranges := test.section(manifest); !reflect.DeepEqual(ranges, test.want) {
+				t.Errorf("%s ranges = %v, want %v", test.pkg, ranges, test.want)
+			}
+		})
+	}
+}
+
+func planPackage(name string, dependencies map[string]string, peers map[string]string) *WorkspacePackage {
+	node := &WorkspacePackage{
+		Name:             name,
+		Version:          "1.0.0",
+		Path:             "packages/" + name,
+		Dependencies:     map[string]string{},
+		DevDependencies:  map[string]string{},
+		PeerDependencies: map[string]string{},
+	}
+
+	for _, ranges := range []map[string]string{dependencies, peers} {
+		for dependency, version := range ranges {
+			node.Dependencies[dependency] = version
+		}
+	}
+
+	for dependency, version := range peers {
+		node.PeerDependencies[dependency] = version
+	}
+
+	return node
+}
+
+func planChangeset(id string, name string, bump utils.BumpType) models.Changeset {
+	return models.Changeset{ID: id, Releases: []models.ChangesetRelease{{Name: name, Type: bump}}}
+}
@@ -22,113 +22,85 @@ THE SOFTWARE.
 package core
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
-	"github.com/jedib0t/go-pretty/v6/progress"
 	"github.com/websublime/sublime-cli/utils"
 )
 
-var config = NewConfig()
+var config, errConfig = NewConfig()
 
 type Config struct {
-	RootDir  string            `json:"root,omitempty"`
-	HomeDir  string            `json:"home,omitempty"`
-	Progress progress.Writer   `json:"-"`
-	Tracker  *progress.Tracker `json:"-"`
+	RootDir        string           `json:"root,omitempty"`
+	HomeDir        string           `json:"home,omitempty"`
+	ConfigFile     string           `json:"config,omitempty"`
+	Command        string           `json:"command,omitempty"`
+	Verbose        bool             `json:"verbose,omitempty"`
+	Timeout        time.Duration    `json:"timeout,omitempty"`
+	CommandTimeout time.Duration    `json:"command_timeout,omitempty"`
+	Retries        int              `json:"retries,omitempty"`
+	Profile        string           `json:"profile,omitempty"`
+	Endpoint       *Endpoint        `json:"endpoint,omitempty"`
+	LogFile        string           `json:"log_file,omitempty"`
+	Progress       ProgressReporter `json:"-"`
+	log            *os.File
+	logOnce        sync.Once
 }
 
-func NewConfig() *Config {
+// NewConfig is the config of the current directory. The config is returned
+// with the error when the current or home directory are unknown.
+func NewConfig() (*Config, error) {
+	ctx := &Config{
+		Timeout:  time.Minute,
+		Retries:  2,
+		Progress: NewProgressReporter(utils.ProgressAuto, os.Stdout),
+	}
+
 	dir, err := os.Getwd()
 	if err != nil {
-		utils.ErrorOut(utils.MessageErrorCurrentDirectory, utils.ErrorMissingDirectory)
+		return ctx, errors.New(utils.MessageErrorCurrentDirectory)
 	}
+	ctx.RootDir = dir
 
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
-		utils.ErrorOut(utils.MessageErrorHomeDirectory, utils.ErrorMissingDirectory)
-	}
-
-	pg := progress.NewWriter()
-	pg.SetAutoStop(false)
-	pg.SetTrackerLength(25)
-	pg.SetMessageWidth(50)
-	pg.SetNumTrackersExpected(1)
-	pg.SetTrackerPosition(progress.PositionRight)
-	pg.SetStyle(progress.StyleBlocks)
-	pg.SetUpdateFrequency(time.Nanosecond)
-	pg.Style().Colors = progress.StyleColorsExample
-	pg.Style().Visibility.Time = false
-
-	units := &progress.UnitsDefault
-	tracker := progress.Tracker{
-		Total:   100,
-		Message: "",
-		Units:   *units,
+		return ctx, errors.New(utils.MessageErrorHomeDirectory)
 	}
+	ctx.HomeDir = homeDir
 
-	pg.AppendTracker(&tracker)
-
-	return &Config{
-		RootDir:  dir,
-		HomeDir:  homeDir,
-		Progress: pg,
-		Tracker:  &tracker,
-	}
+	return ctx, nil
 }
 
 func GetConfig() *Config {
 	return config
 }
 
-func (ctx *Config) SetRootDir(path string) {
-	dir, err := os.Getwd()
-	if err != nil {
-		utils.ErrorOut(utils.MessageErrorCurrentDirectory, utils.ErrorMissingDirectory)
-	}
-
-	if filepath.IsAbs(path) {
-		ctx.RootDir = path
-	} else {
-		ctx.RootDir = filepath.Join(dir, path)
-	}
-}
-
-func (ctx *Config) UpdateProgress(message string, increment int64) {
-	ctx.Tracker.UpdateMessage(message)
-
-	for idx := int64(1); idx <= increment; idx++ {
-		ctx.Tracker.Increment(idx * 10)
-	}
-
-	time.Sleep(time.Microsecond * 100)
-}
-
-func (ctx *Config) TerminateProgress() {
-	ctx.Tracker.MarkAsDone()
-	ctx.Progress.Stop()
+// ConfigError is the error of NewConfig for the config of GetConfig, commands
+// fail with it before they run.
+func ConfigError() error {
+	return errConfig
 }
 
-func (ctx *Config) DoneProgress() {
-	ctx.Tracker.MarkAsDone()
+// LockDir is where the workspace config locks are kept (~/.sublime/cache/locks).
+func (ctx *Config) LockDir() string {
+	return filepath.Join(ctx.HomeDir, ".sublime", "cache", "locks")
 }
 
-func (ctx *Config) AddTracker() {
-	units := &progress.UnitsDefault
-	tracker := progress.Tracker{
-		Total:   100,
-		Message: "",
-		Units:   *units,
+func (ctx *Config) SetRootDir(path string) error {
+	if filepath.IsAbs(path) {
+		ctx.RootDir = path
+		return nil
 	}
 
-	ctx.Progress.AppendTracker(&tracker)
+	dir, err := os.Getwd()
+	if err != nil {
+		return errors.New(utils.MessageErrorCurrentDirectory)
+	}
 
-	ctx.Tracker = &tracker
-}
+	ctx.RootDir = filepath.Join(dir, path)
 
-func (ctx *Config) TerminateErrorProgress(message string) {
-	ctx.Tracker.UpdateMessage(message)
-	ctx.Tracker.MarkAsErrored()
-	time.Sleep(time.Microsecond * 100)
+	return nil
 }
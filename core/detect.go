@@ -0,0 +1,142 @@
+/*
+Copyright © 2022 Websublime.dev organization@websublime.dev
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package core
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/websublime/sublime-cli/models"
+	"github.com/websublime/sublime-cli/utils"
+)
+
+var viteConfigFiles = []string{"vite.config.ts", "vite.config.js", "vite.config.mjs", "vite.config.mts"}
+
+// DetectPackages finds the packages of an existing monorepo: every folder
+// with a package.json on libs/ (libraries) and packages/ (packages). The
+// workspace vite plugin (libs/vite) is not a package.
+func (ctx *Config) DetectPackages(scope string) ([]models.DetectedPackage, error) {
+	detected := []models.DetectedPackage{}
+
+	folders := map[string]utils.PackageType{"libs": utils.Library, "packages": utils.Package}
+	for folder, packageType := range folders {
+		entries, err := os.ReadDir(filepath.Join(ctx.RootDir, folder))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				continue
+			}
+
+			path := filepath.Join(folder, entry.Name())
+			packageJson, err := ReadPackageManifest(filepath.Join(ctx.RootDir, path, "package.json"))
+			if err != nil {
+				continue
+			}
+
+			if folder == "libs" && entry.Name() == "vite" && strings.HasSuffix(packageJson.Name, "/vite") {
+				continue
+			}
+
+			detected = append(detected, models.DetectedPackage{
+				Package: models.SublimePackages{
+					Name:        entry.Name(),
+					Scope:       scope,
+					Type:        packageType,
+					Description: packageJson.Description,
+				},
+				Template: DetectTemplate(filepath.Join(ctx.RootDir, path), packageJson.AllDependencies()),
+				Version:  packageJson.Version,
+				Path:     path,
+			})
+		}
+	}
+
+	sort.Slice(detected, func(i, j int) bool { return detected[i].Path < detected[j].Path })
+
+	return detected, nil
+}
+
+// DetectTemplate guesses the template of a package from its vite config and
+// its dependencies, defaulting to typescript.
+func DetectTemplate(dir string, dependencies map[string]string) utils.TemplateType {
+	for _, file := range viteConfigFiles {
+		content, err := os.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			continue
+		}
+
+		switch {
+		case strings.Contains(string(content), "@vitejs/plugin-vue"):
+			return utils.Vue
+		case strings.Contains(string(content), "vite-plugin-solid"):
+			return utils.Solid
+		case strings.Contains(string(content), "@preact/preset-vite"):
+			return utils.Preact
+		case strings.Contains(string(content), "@vitejs/plugin-react"):
+			return utils.React
+		case strings.Contains(string(content), "@sveltejs/vite-plugin-svelte"):
+			return utils.Svelte
+		}
+	}
+
+	switch {
+	case dependencies["vue"] != "":
+		return utils.Vue
+	case dependencies["solid-js"] != "":
+		return utils.Solid
+	case dependencies["preact"] != "":
+		return utils.Preact
+	case dependencies["react"] != "":
+		return utils.React
+	case dependencies["svelte"] != "":
+		return utils.Svelte
+	case dependencies["lit"] != "":
+		return utils.Lit
+	}
+
+	return utils.Typescript
+}
+
+// ReadPackageManifest reads the package.json fields used for detection.
+func ReadPackageManifest(path string) (*models.PackageManifest, error) {
+	packageJson := &models.PackageManifest{}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, packageJson); err != nil {
+		return nil, err
+	}
+
+	return packageJson, nil
+}
@@ -0,0 +1,250 @@
+/*
+Copyright © 2022 Websublime.dev organization@websublime.dev
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/websublime/sublime-cli/models"
+	"github.com/websublime/sublime-cli/utils"
+)
+
+var (
+	toolVersion     = regexp.MustCompile(`(\d+)\.(\d+)(?:\.(\d+))?`)
+	workflowVersion = regexp.MustCompile(`sublime-cli/releases/download/([^/\s]+)/`)
+)
+
+// CheckTool runs "name --version" and compares it with minimum. Missing or
+// older tools are errors, or warnings when not required.
+func (ctx *Config) CheckTool(c context.Context, name string, minimum string, required bool, hint string) models.DoctorCheck {
+	check := models.DoctorCheck{Group: utils.DoctorGroupEnvironment, Name: name, Status: utils.DoctorOk}
+
+	status := utils.DoctorError
+	if !required {
+		status = utils.DoctorWarning
+	}
+
+	if _, err := exec.LookPath(name); err != nil {
+		check.Status, check.Message, check.Hint = status, fmt.Sprintf(utils.MessageCommandDoctorMissing, name), hint
+		return check
+	}
+
+	stdout, err := NewProcess(ctx.RootDir, name, "--version").Run(c)
+	if err != nil {
+		check.Status, check.Message, check.Hint = status, err.Error(), hint
+		return check
+	}
+
+	match := toolVersion.FindStringSubmatch(stdout)
+	if match == nil {
+		check.Status, check.Message, check.Hint = utils.DoctorWarning, strings.TrimSpace(stdout), hint
+		return check
+	}
+
+	patch := match[3]
+	if patch == "" {
+		patch = "0"
+	}
+	version := fmt.Sprintf("%s.%s.%s", match[1], match[2], patch)
+
+	check.Message = fmt.Sprintf(utils.MessageCommandDoctorVersion, version, minimum)
+	if utils.CompareVersions(version, minimum) < 0 {
+		check.Status, check.Hint = status, hint
+	}
+
+	return check
+}
+
+// CheckAuthorFile checks the author file of the profile can be read, is only
+// readable by its owner and has a token not expired yet. The author is nil
+// when the file can not be read.
+func (ctx *Config) CheckAuthorFile(now time.Time) (*models.AuthorFileProps, []models.DoctorCheck) {
+	path := ctx.AuthorFile()
+	check := func(name string) models.DoctorCheck {
+		return models.DoctorCheck{Group: utils.DoctorGroupAuthor, Name: name, Status: utils.DoctorOk}
+	}
+
+	file := check(filepath.Base(path))
+
+	info, err := os.Stat(path)
+	if err != nil {
+		file.Status, file.Message, file.Hint = utils.DoctorError, utils.MessageCommandDoctorNoAuthor, utils.MessageCommandDoctorHintLogin
+		return nil, []models.DoctorCheck{file}
+	}
+
+	author := &models.AuthorFileProps{}
+
+	data, err := os.ReadFile(path)
+	if err == nil {
+		err = json.Unmarshal(data, author)
+	}
+	if err != nil {
+		file.Status, file.Message, file.Hint = utils.DoctorError, fmt.Sprintf("%s: %s", path, err.Error()), utils.MessageCommandDoctorHintLogin
+		return nil, []models.DoctorCheck{file}
+	}
+
+	file.Message = fmt.Sprintf(utils.MessageCommandDoctorAuthorFile, path)
+	checks := []models.DoctorCheck{file}
+
+	// Windows has no unix permissions to check.
+	if runtime.GOOS != "windows" {
+		permissions := check("permissions")
+		permissions.Message = fmt.Sprintf(utils.MessageCommandDoctorPermissions, filepath.Base(path), info.Mode().Perm())
+
+		if info.Mode().Perm()&0077 != 0 {
+			permissions.Status, permissions.Hint = utils.DoctorWarning, fmt.Sprintf(utils.MessageCommandDoctorHintPermissions, path)
+		}
+
+		checks = append(checks, permissions)
+	}
+
+	token := check("token")
+	expires := time.Unix(author.Expire, 0)
+
+	switch {
+	case author.Token == "":
+		token.Status, token.Message, token.Hint = utils.DoctorError, utils.MessageErrorAuthorTokenMissing, utils.MessageCommandDoctorHintLogin
+	case now.After(expires):
+		token.Status, token.Message, token.Hint = utils.DoctorWarning, fmt.Sprintf(utils.MessageCommandDoctorTokenExpired, expires.Format(time.RFC3339)), utils.MessageCommandDoctorHintToken
+	default:
+		token.Message = fmt.Sprintf(utils.MessageCommandDoctorTokenValid, expires.Format(time.RFC3339))
+	}
+
+	return author, append(checks, token)
+}
+
+// CheckPackages checks the folder and package.json of each package of
+// .sublime.json exist, and that the package.json is named @scope/name.
+func (ctx *Config) CheckPackages(packages []models.SublimePackages) []models.DoctorCheck {
+	checks := []models.DoctorCheck{}
+
+	for _, pkg := range packages {
+		name := fmt.Sprintf("%s/%s", pkg.Scope, pkg.Name)
+		dir := ctx.PackageDir(pkg)
+		rel, _ := filepath.Rel(ctx.RootDir, dir)
+
+		check := models.DoctorCheck{Group: utils.DoctorGroupWorkspace, Name: name, Status: utils.DoctorOk, Message: filepath.ToSlash(rel)}
+
+		manifest, err := ReadPackageManifest(filepath.Join(dir, "package.json"))
+		switch {
+		case err != nil:
+			missing := filepath.Join(rel, "package.json")
+			if info, statErr := os.Stat(dir); statErr != nil || !info.IsDir() {
+				missing = rel
+			}
+
+			check.Status, check.Message, check.Hint = utils.DoctorError, fmt.Sprintf(utils.MessageCommandDoctorPackageDir, filepath.ToSlash(missing)), utils.MessageCommandDoctorHintPackage
+		case manifest.Name != name:
+			check.Status, check.Message = utils.DoctorWarning, fmt.Sprintf(utils.MessageCommandDoctorPackageName, manifest.Name, name)
+		}
+
+		checks = append(checks, check)
+	}
+
+	return checks
+}
+
+// CheckTsconfig checks each package has a reference on tsconfig.base.json and
+// each reference points to a package of .sublime.json.
+func (ctx *Config) CheckTsconfig(packages []models.SublimePackages) models.DoctorCheck {
+	check := models.DoctorCheck{Group: utils.DoctorGroupWorkspace, Name: "tsconfig.base.json", Status: utils.DoctorOk}
+
+	tsconfig, err := GetApp().GetTsconfig()
+	if err != nil {
+		check.Status, check.Message, check.Hint = utils.DoctorWarning, fmt.Sprintf(utils.MessageCommandDoctorMissing, "tsconfig.base.json"), utils.MessageCommandDoctorHintTsconfig
+		return check
+	}
+
+	expected := []string{}
+	for _, pkg := range packages {
+		rel, _ := filepath.Rel(ctx.RootDir, ctx.PackageDir(pkg))
+		expected = append(expected, filepath.ToSlash(rel))
+	}
+
+	references := []string{}
+	problems := []string{}
+
+	for _, reference := range tsconfig.References {
+		path := strings.TrimSuffix(strings.TrimPrefix(filepath.ToSlash(reference.Path), "./"), "/")
+		references = append(references, path)
+
+		if !utils.Contains(expected, path) {
+			problems = append(problems, fmt.Sprintf(utils.MessageCommandDoctorTsconfigStale, reference.Path))
+		}
+	}
+
+	for _, path := range expected {
+		if !utils.Contains(references, path) {
+			problems = append(problems, fmt.Sprintf(utils.MessageCommandDoctorTsconfigMissing, path))
+		}
+	}
+
+	check.Message = fmt.Sprintf(utils.MessageCommandDoctorTsconfigValid, len(tsconfig.References))
+	if len(problems) > 0 {
+		check.Status, check.Message, check.Hint = utils.DoctorWarning, strings.Join(problems, ", "), utils.MessageCommandDoctorHintTsconfig
+	}
+
+	return check
+}
+
+// CheckWorkflows compares the cli release downloaded by the workflows of
+// .github/workflows with the running version. Workflows not downloading the
+// cli are left out.
+func (ctx *Config) CheckWorkflows(version string) []models.DoctorCheck {
+	checks := []models.DoctorCheck{}
+
+	files, _ := filepath.Glob(filepath.Join(ctx.RootDir, ".github", "workflows", "*.y*ml"))
+	sort.Strings(files)
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+
+		match := workflowVersion.FindStringSubmatch(string(data))
+		if match == nil {
+			continue
+		}
+
+		rel, _ := filepath.Rel(ctx.RootDir, file)
+		check := models.DoctorCheck{Group: utils.DoctorGroupWorkspace, Name: filepath.ToSlash(rel), Status: utils.DoctorOk, Message: fmt.Sprintf(utils.MessageCommandDoctorWorkflowVersion, match[1])}
+
+		if utils.CompareVersions(match[1], version) != 0 {
+			check.Status, check.Message, check.Hint = utils.DoctorWarning, fmt.Sprintf(utils.MessageCommandDoctorWorkflowOutdated, match[1], version), utils.MessageCommandDoctorHintWorkflow
+		}
+
+		checks = append(checks, check)
+	}
+
+	return checks
+}
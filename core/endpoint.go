@@ -0,0 +1,200 @@
+/*
+Copyright © 2022 Websublime.dev organization@websublime.dev
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/websublime/sublime-cli/models"
+	"github.com/websublime/sublime-cli/utils"
+)
+
+const (
+	EnvApiUrl    = "SUBLIME_API_URL"
+	EnvApiKey    = "SUBLIME_API_KEY"
+	EnvApiSecret = "SUBLIME_API_SECRET"
+	EnvProfile   = "SUBLIME_PROFILE"
+
+	DefaultProfile = "default"
+)
+
+// Endpoint is the effective api endpoint and where each value came from.
+type Endpoint struct {
+	Profile   string `json:"profile"`
+	Url       string `json:"url"`
+	UrlSource string `json:"url_source"`
+	KeySource string `json:"key_source"`
+}
+
+// HomeConfigFile is the path of the user config (~/.sublime/config.json).
+func (ctx *Config) HomeConfigFile() string {
+	return filepath.Join(ctx.HomeDir, ".sublime", "config.json")
+}
+
+// AuthorFile is the path of the author rc file for the active profile.
+// The default profile keeps the historical rc.json name.
+func (ctx *Config) AuthorFile() string {
+	if ctx.Profile == "" || ctx.Profile == DefaultProfile {
+		return filepath.Join(ctx.HomeDir, ".sublime", "rc.json")
+	}
+
+	return filepath.Join(ctx.HomeDir, ".sublime", fmt.Sprintf("rc.%s.json", ctx.Profile))
+}
+
+func (ctx *Config) LoadHomeConfig() (*models.HomeConfig, error) {
+	home := &models.HomeConfig{
+		Profiles: map[string]models.HomeProfile{},
+	}
+
+	data, err := os.ReadFile(ctx.HomeConfigFile())
+	if errors.Is(err, os.ErrNotExist) {
+		return home, nil
+	}
+	if err != nil {
+		return home, err
+	}
+
+	if err := json.Unmarshal(data, home); err != nil {
+		return home, fmt.Errorf("%s: %s", ctx.HomeConfigFile(), utils.MessageErrorParseFile)
+	}
+
+	if home.Profiles == nil {
+		home.Profiles = map[string]models.HomeProfile{}
+	}
+
+	return home, nil
+}
+
+func (ctx *Config) SaveHomeConfig(home *models.HomeConfig) error {
+	data, err := json.MarshalIndent(home, "", " ")
+	if err != nil {
+		return errors.New(utils.MessageErrorIndentFile)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(ctx.HomeConfigFile()), 0755); err != nil {
+		return errors.New(utils.MessageErrorCommandRegisterHomeDir)
+	}
+
+	if err := os.WriteFile(ctx.HomeConfigFile(), data, 0600); err != nil {
+		return errors.New(utils.MessageErrorWriteFile)
+	}
+
+	return nil
+}
+
+// ResolveEndpoint sets utils.ApiUrl and ApiKey from (highest first):
+// environment variables, the selected profile of the home config, the home
+// config defaults and finally the values linked at build time. The service
+// role secret is never resolved here, only read from SUBLIME_API_SECRET.
+func (ctx *Config) ResolveEndpoint(profile string) (*Endpoint, error) {
+	home, err := ctx.LoadHomeConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if profile == "" {
+		profile = os.Getenv(EnvProfile)
+	}
+	if profile == "" {
+		profile = home.Profile
+	}
+	if profile == "" {
+		profile = DefaultProfile
+	}
+
+	selected, ok := home.Profiles[profile]
+	if !ok && profile != DefaultProfile {
+		return nil, fmt.Errorf(utils.MessageErrorProfileMissing, profile, ctx.HomeConfigFile())
+	}
+
+	ctx.Profile = profile
+
+	endpoint := &Endpoint{
+		Profile:   profile,
+		UrlSource: "build",
+		KeySource: "build",
+	}
+
+	pick := func(current string, source *string, candidates ...[2]string) string {
+		for _, candidate := range candidates {
+			if candidate[0] != "" {
+				*source = candidate[1]
+				return candidate[0]
+			}
+		}
+		return current
+	}
+
+	profileSource := fmt.Sprintf("profile:%s", profile)
+
+	utils.ApiUrl = pick(utils.ApiUrl, &endpoint.UrlSource,
+		[2]string{os.Getenv(EnvApiUrl), "env:" + EnvApiUrl},
+		[2]string{selected.ApiUrl, profileSource},
+		[2]string{home.ApiUrl, "home"},
+	)
+	utils.ApiKey = pick(utils.ApiKey, &endpoint.KeySource,
+		[2]string{os.Getenv(EnvApiKey), "env:" + EnvApiKey},
+		[2]string{selected.ApiKey, profileSource},
+		[2]string{home.ApiKey, "home"},
+	)
+
+	endpoint.Url = utils.ApiUrl
+	ctx.Endpoint = endpoint
+
+	return endpoint, nil
+}
+
+// DefaultOrganization is the organization selected with "sublime org use"
+// for the active profile.
+func (ctx *Config) DefaultOrganization() string {
+	home, err := ctx.LoadHomeConfig()
+	if err != nil {
+		return ""
+	}
+
+	if ctx.Profile != "" && ctx.Profile != DefaultProfile {
+		return home.Profiles[ctx.Profile].Organization
+	}
+
+	return home.Organization
+}
+
+func (ctx *Config) SetDefaultOrganization(name string) error {
+	home, err := ctx.LoadHomeConfig()
+	if err != nil {
+		return err
+	}
+
+	if ctx.Profile != "" && ctx.Profile != DefaultProfile {
+		profile := home.Profiles[ctx.Profile]
+		profile.Organization = name
+		home.Profiles[ctx.Profile] = profile
+	} else {
+		home.Organization = name
+	}
+
+	return ctx.SaveHomeConfig(home)
+}
@@ -0,0 +1,252 @@
+/*
+Copyright © 2022 Websublime.dev organization@websublime.dev
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package core
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"github.com/websublime/sublime-cli/models"
+	"github.com/websublime/sublime-cli/utils"
+)
+
+var (
+	cliDownloadPattern = regexp.MustCompile(`(sublime-cli/releases/download/)[^/\s]+`)
+	cliArchivePattern  = regexp.MustCompile(`(sublime-)v?[0-9][^\s/]*?(-linux-amd64)`)
+)
+
+// FileUpgrade is the outcome of upgrading a generated file. Content is
+// written when Write is true and Hash is the base to record for the file.
+type FileUpgrade struct {
+	models.UpgradeFile
+	Content string
+	Write   bool
+	Hash    string
+}
+
+// GeneratedFilesPath is where the files generated on the workspace root are
+// recorded (.sublime/generated.json).
+func GeneratedFilesPath(root string) string {
+	return filepath.Join(root, ".sublime", "generated.json")
+}
+
+// LoadGeneratedFiles reads the generated files of the workspace root, none
+// when the file does not exist (workspaces of older versions).
+func LoadGeneratedFiles(root string) (*models.GeneratedFiles, error) {
+	generated := &models.GeneratedFiles{Files: []models.GeneratedFile{}}
+
+	data, err := os.ReadFile(GeneratedFilesPath(root))
+	if errors.Is(err, os.ErrNotExist) {
+		return generated, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, generated); err != nil {
+		return nil, errors.New(utils.MessageErrorParseFile)
+	}
+
+	return generated, nil
+}
+
+func SaveGeneratedFiles(root string, generated *models.GeneratedFiles) error {
+	sort.Slice(generated.Files, func(i, j int) bool { return generated.Files[i].Path < generated.Files[j].Path })
+
+	data, err := json.MarshalIndent(generated, "", " ")
+	if err != nil {
+		return errors.New(utils.MessageErrorIndentFile)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(GeneratedFilesPath(root)), 0755); err != nil {
+		return err
+	}
+
+	return writeFileAtomic(GeneratedFilesPath(root), append(data, '\n'), 0644)
+}
+
+// RecordGeneratedFiles adds (or replaces, by path) files to the generated
+// files of the workspace root.
+func RecordGeneratedFiles(root string, files ...models.GeneratedFile) error {
+	generated, err := LoadGeneratedFiles(root)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if current := FindGeneratedFile(generated, file.Path); current != nil {
+			*current = file
+		} else {
+			generated.Files = append(generated.Files, file)
+		}
+	}
+
+	return SaveGeneratedFiles(root, generated)
+}
+
+func FindGeneratedFile(generated *models.GeneratedFiles, path string) *models.GeneratedFile {
+	for idx := range generated.Files {
+		if generated.Files[idx].Path == path {
+			return &generated.Files[idx]
+		}
+	}
+
+	return nil
+}
+
+func HashContent(data []byte) string {
+	sum := sha256.Sum256(data)
+
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// BumpCliVersion points the sublime release downloads of a workflow to
+// version.
+func BumpCliVersion(content string, version string) string {
+	content = cliDownloadPattern.ReplaceAllString(content, "${1}"+version)
+
+	return cliArchivePattern.ReplaceAllString(content, "${1}"+version+"${2}")
+}
+
+// UpgradeGeneratedFile three-way merges rendered, the file rendered by this
+// version, with the current file. The base is the content originally
+// generated: the current file or a version of it on the git history with the
+// recorded hash. Changes of both on the same lines are conflicts, the other
+// changes are applied. Without a base only the cli version is bumped and the
+// other changes are reported as a conflict. With force the file is replaced.
+func UpgradeGeneratedFile(c context.Context, root string, file *models.GeneratedFile, rendered string, version string, force bool) (*FileUpgrade, error) {
+	upgrade := &FileUpgrade{
+		UpgradeFile: models.UpgradeFile{Path: file.Path, Template: file.Template},
+		Hash:        HashContent([]byte(rendered)),
+	}
+
+	data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(file.Path)))
+	if errors.Is(err, os.ErrNotExist) {
+		if file.Hash != "" && !force {
+			upgrade.Status = utils.FileDeleted
+			upgrade.Hash = file.Hash
+			return upgrade, nil
+		}
+
+		upgrade.Status = utils.FileCreated
+		upgrade.Content = rendered
+		upgrade.Write = true
+		upgrade.Diff = UnifiedDiff("/dev/null", file.Path, "", rendered)
+		return upgrade, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	current := string(data)
+	if current == rendered {
+		upgrade.Status = utils.FileUnchanged
+		return upgrade, nil
+	}
+
+	base, found := generatedBase(c, root, file, current, rendered)
+
+	switch {
+	case force:
+		upgrade.Status = utils.FileOverwritten
+		upgrade.Content = rendered
+	case found && current == base:
+		upgrade.Status = utils.FileUpdated
+		upgrade.Content = rendered
+	case found && rendered == base:
+		upgrade.Status = utils.FileKept
+		return upgrade, nil
+	case found:
+		merged, conflict := Merge3(base, current, rendered)
+		if !conflict {
+			upgrade.Status = utils.FileMerged
+			upgrade.Content = merged
+			break
+		}
+
+		// The changes not conflicting are applied, the base is kept so the
+		// next upgrade merges the conflicting ones again.
+		partial, _ := MergeOurs(base, current, rendered)
+
+		upgrade.Status = utils.FileConflict
+		upgrade.Diff = UnifiedDiff(file.Path, file.Path, current, merged)
+		upgrade.Content = BumpCliVersion(partial, version)
+		upgrade.Hash = file.Hash
+	default:
+		upgrade.Content = BumpCliVersion(current, version)
+		if upgrade.Content == rendered {
+			upgrade.Status = utils.FileUpdated
+			break
+		}
+
+		upgrade.Status = utils.FileConflict
+		upgrade.Diff = UnifiedDiff(file.Path, file.Path, current, rendered)
+		upgrade.Hash = file.Hash
+	}
+
+	upgrade.Write = upgrade.Content != current
+	if upgrade.Diff == "" {
+		upgrade.Diff = UnifiedDiff(file.Path, file.Path, current, upgrade.Content)
+	}
+
+	return upgrade, nil
+}
+
+// generatedBase finds the content originally generated for file, by its
+// recorded hash, on the current file, the new render (the template did not
+// change) or the last commits changing the file.
+func generatedBase(c context.Context, root string, file *models.GeneratedFile, current string, rendered string) (string, bool) {
+	if file.Hash == "" {
+		return "", false
+	}
+
+	for _, content := range []string{current, rendered} {
+		if HashContent([]byte(content)) == file.Hash {
+			return content, true
+		}
+	}
+
+	repo, err := OpenGitRepo(root)
+	if err != nil {
+		return "", false
+	}
+
+	history, err := repo.FileHistory(c, filepath.Join(root, file.Path), 50)
+	if err != nil {
+		return "", false
+	}
+
+	for _, content := range history {
+		if HashContent([]byte(content)) == file.Hash {
+			return content, true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,230 @@
+/*
+Copyright © 2022 Websublime.dev organization@websublime.dev
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/websublime/sublime-cli/models"
+	"github.com/websublime/sublime-cli/utils"
+)
+
+const workflowPath = ".github/workflows/ci.yml"
+
+func workflow(version string, steps ...string) string {
+	return "name: ci\n" +
+		"run: curl https://github.com/websublime/sublime-cli/releases/download/" + version + "/sublime-" + version + "-linux-amd64.tar.gz\n" +
+		strings.Join(steps, "")
+}
+
+// commitWorkflow commits content as the generated workflow of a new repo on
+// root, so the upgrade finds the base on the git history.
+func commitWorkflow(t *testing.T, root string, content string) {
+	t.Helper()
+
+	repo, err := InitGitRepo(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := repo.repo.Config()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.User.Name = "Sublime"
+	cfg.User.Email = "sublime@websublime.dev"
+	if err := repo.repo.SetConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	writeWorkflow(t, root, content)
+	if _, err := repo.CommitFiles("ci: add workflow", []string{workflowPath}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeWorkflow(t *testing.T, root string, content string) {
+	t.Helper()
+
+	file := filepath.Join(root, filepath.FromSlash(workflowPath))
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUpgradeGeneratedFile(t *testing.T) {
+	base := workflow("v0.1.0", "step: one\n", "step: two\n", "step: three\n")
+	rendered := workflow("v0.2.0", "step: one\n", "step: two\n", "step: three\n")
+
+	tests := []struct {
+		name     string
+		base     string
+		hash     string
+		current  *string
+		rendered string
+		force    bool
+		status   utils.FileStatus
+		content  string
+		write    bool
+		keepHash bool
+		diff     string
+	}{
+		{
+			name:     "deleted file stays deleted",
+			hash:     HashContent([]byte(base)),
+			rendered: rendered,
+			status:   utils.FileDeleted,
+			keepHash: true,
+		},
+		{
+			name:     "missing file without hash is created",
+			rendered: rendered,
+			status:   utils.FileCreated,
+			content:  rendered,
+			write:    true,
+		},
+		{
+			name:     "unchanged",
+			hash:     HashContent([]byte(rendered)),
+			current:  &rendered,
+			rendered: rendered,
+			status:   utils.FileUnchanged,
+		},
+		{
+			name:     "legacy file only differing on the version is updated",
+			current:  &base,
+			rendered: rendered,
+			status:   utils.FileUpdated,
+			content:  rendered,
+			write:    true,
+		},
+		{
+			name:     "legacy file with edits only bumps the version",
+			current:  stringPointer(workflow("v0.1.0", "step: mine\n")),
+			rendered: rendered,
+			status:   utils.FileConflict,
+			content:  workflow("v0.2.0", "step: mine\n"),
+			write:    true,
+			keepHash: true,
+		},
+		{
+			name:     "untouched file is updated",
+			hash:     HashContent([]byte(base)),
+			current:  &base,
+			rendered: workflow("v0.2.0", "step: one\n", "step: 2\n", "step: three\n"),
+			status:   utils.FileUpdated,
+			content:  workflow("v0.2.0", "step: one\n", "step: 2\n", "step: three\n"),
+			write:    true,
+		},
+		{
+			name:     "edits are kept when the template did not change",
+			hash:     HashContent([]byte(rendered)),
+			current:  stringPointer(workflow("v0.2.0", "step: mine\n")),
+			rendered: rendered,
+			status:   utils.FileKept,
+			keepHash: true,
+		},
+		{
+			name:     "force overwrites edits",
+			hash:     HashContent([]byte(base)),
+			current:  stringPointer(workflow("v0.1.0", "step: mine\n")),
+			rendered: rendered,
+			force:    true,
+			status:   utils.FileOverwritten,
+			content:  rendered,
+			write:    true,
+		},
+		{
+			name:     "clean merge with the base on the git history",
+			base:     base,
+			hash:     HashContent([]byte(base)),
+			current:  stringPointer(workflow("v0.1.0", "step: one\n", "step: two\n", "step: mine\n")),
+			rendered: rendered,
+			status:   utils.FileMerged,
+			content:  workflow("v0.2.0", "step: one\n", "step: two\n", "step: mine\n"),
+			write:    true,
+		},
+		{
+			name:     "conflict writes the changes not conflicting",
+			base:     base,
+			hash:     HashContent([]byte(base)),
+			current:  stringPointer("name: ci\nrun: ./install.sh\nstep: one\nstep: two\nstep: three\n"),
+			rendered: workflow("v0.2.0", "step: one\n", "step: 2\n", "step: three\n"),
+			status:   utils.FileConflict,
+			content:  "name: ci\nrun: ./install.sh\nstep: one\nstep: 2\nstep: three\n",
+			write:    true,
+			keepHash: true,
+			diff:     "+<<<<<<< current\n run: ./install.sh\n+=======\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root := t.TempDir()
+			if test.base != "" {
+				commitWorkflow(t, root, test.base)
+			}
+			if test.current != nil {
+				writeWorkflow(t, root, *test.current)
+			}
+
+			file := &models.GeneratedFile{Path: workflowPath, Template: "workspace", Hash: test.hash}
+			upgrade, err := UpgradeGeneratedFile(context.Background(), root, file, test.rendered, "v0.2.0", test.force)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if upgrade.Status != test.status {
+				t.Errorf("status = %s, want %s", upgrade.Status, test.status)
+			}
+			if upgrade.Content != test.content {
+				t.Errorf("content = %q, want %q", upgrade.Content, test.content)
+			}
+			if upgrade.Write != test.write {
+				t.Errorf("write = %t, want %t", upgrade.Write, test.write)
+			}
+
+			hash := HashContent([]byte(test.rendered))
+			if test.keepHash {
+				hash = test.hash
+			}
+			if upgrade.Hash != hash {
+				t.Errorf("hash = %q, want %q", upgrade.Hash, hash)
+			}
+
+			if !strings.Contains(upgrade.Diff, test.diff) {
+				t.Errorf("diff = %q, want it to contain %q", upgrade.Diff, test.diff)
+			}
+		})
+	}
+}
+
+func stringPointer(value string) *string {
+	return &value
+}
@@ -0,0 +1,688 @@
+/*
+Copyright © 2022 Websublime.dev organization@websublime.dev
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/websublime/sublime-cli/utils"
+)
+
+// GitDefaultBranch is the branch of new workspaces, and the base of the
+// changed packages of "sublime action" on branches.
+const GitDefaultBranch = "main"
+
+// GitRepo is a git repository read in process, so read only operations don't
+// need the git binary.
+type GitRepo struct {
+	Dir  string
+	repo *git.Repository
+}
+
+// GitTag is a tag and the commit it points to.
+type GitTag struct {
+	Name   string
+	Commit string
+	Date   time.Time
+}
+
+// GitCommit is a commit and the files it changed from its parent.
+type GitCommit struct {
+	Hash    string
+	Message string
+	Date    time.Time
+	Files   []string
+}
+
+// OpenGitRepo opens the repository of dir, or of one of its parents.
+func OpenGitRepo(dir string) (*GitRepo, error) {
+	repo, err := git.PlainOpenWithOptions(dir, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		if errors.Is(err, git.ErrRepositoryNotExists) {
+			return nil, fmt.Errorf(utils.MessageErrorGitRepo, dir)
+		}
+
+		return nil, err
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, err
+	}
+
+	return &GitRepo{Dir: worktree.Filesystem.Root(), repo: repo}, nil
+}
+
+// InitGitRepo creates a repository on dir, on the GitDefaultBranch.
+func InitGitRepo(dir string) (*GitRepo, error) {
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		return nil, err
+	}
+
+	head := plumbing.NewSymbolicReference(plumbing.HEAD, plumbing.NewBranchReferenceName(GitDefaultBranch))
+	if err := repo.Storer.SetReference(head); err != nil {
+		return nil, err
+	}
+
+	return &GitRepo{Dir: dir, repo: repo}, nil
+}
+
+// CloneGitRepo clones link on dir. The ref (branch or tag) is cloned
+// shallow, commits need a full clone. Https urls are authenticated with
+// token, ssh urls with the ssh agent.
+func CloneGitRepo(c context.Context, link string, dir string, ref string, token string) error {
+	config := GetConfig()
+
+	if config.CommandTimeout > 0 {
+		var cancel context.CancelFunc
+		c, cancel = context.WithTimeout(c, config.CommandTimeout)
+		defer cancel()
+	}
+
+	output := config.ProcessOutput()
+	fmt.Fprintf(output, "$ git clone %s %s\n", redactURL(link), ref)
+
+	options := &git.CloneOptions{URL: link, Depth: 1, SingleBranch: true, Progress: output}
+	if token != "" {
+		options.Auth = &http.BasicAuth{Username: "x-access-token", Password: token}
+	}
+
+	names := []plumbing.ReferenceName{""}
+	if ref != "" {
+		names = []plumbing.ReferenceName{plumbing.NewBranchReferenceName(ref), plumbing.NewTagReferenceName(ref)}
+	}
+
+	for _, name := range names {
+		options.ReferenceName = name
+
+		_, err := git.PlainCloneContext(c, dir, false, options)
+		if err == nil {
+			return nil
+		}
+
+		os.RemoveAll(dir)
+
+		if !errors.Is(err, git.NoMatchingRefSpecError{}) && !errors.Is(err, plumbing.ErrReferenceNotFound) {
+			return cloneError(link, err)
+		}
+	}
+
+	if ref == "" {
+		return cloneError(link, plumbing.ErrReferenceNotFound)
+	}
+
+	options.ReferenceName, options.Depth, options.SingleBranch = "", 0, false
+
+	repo, err := git.PlainCloneContext(c, dir, false, options)
+	if err != nil {
+		os.RemoveAll(dir)
+		return cloneError(link, err)
+	}
+
+	hash, err := repo.ResolveRevision(plumbing.Revision(ref))
+	if err != nil {
+		os.RemoveAll(dir)
+		return fmt.Errorf(utils.MessageErrorGitRef, ref)
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+
+	return worktree.Checkout(&git.CheckoutOptions{Hash: *hash, Force: true})
+}
+
+// CheckGitRemote lists the refs of link, like "git ls-remote", to check the
+// access to it. Https urls are authenticated with token, ssh urls with the
+// ssh agent.
+func CheckGitRemote(c context.Context, link string, token string) error {
+	remote := git.NewRemote(memory.NewStorage(), &gitconfig.RemoteConfig{Name: "origin", URLs: []string{link}})
+
+	options := &git.ListOptions{}
+	if token != "" {
+		options.Auth = &http.BasicAuth{Username: "x-access-token", Password: token}
+	}
+
+	if _, err := remote.ListContext(c, options); err != nil && !errors.Is(err, transport.ErrEmptyRemoteRepository) {
+		return errors.New(redactURL(err.Error()))
+	}
+
+	return nil
+}
+
+func cloneError(link string, err error) error {
+	return fmt.Errorf(utils.MessageErrorGitClone, redactURL(link), redactURL(err.Error()))
+}
+
+func redactURL(text string) string {
+	return urlCredentials.ReplaceAllString(text, "$1***@")
+}
+
+// IsShallow is true on clones with a limited history (ex: actions/checkout
+// without fetch-depth: 0).
+func (ctx *GitRepo) IsShallow() bool {
+	shallow, err := ctx.repo.Storer.Shallow()
+
+	return err == nil && len(shallow) > 0
+}
+
+// Commit resolves ref (HEAD, HEAD^, branch, origin/branch, tag or hash) like
+// "git rev-parse". Tags are resolved first, as package tags (@scope/name@1.2.3)
+// are not valid revisions.
+func (ctx *GitRepo) Commit(ref string) (*object.Commit, error) {
+	if tag, err := ctx.repo.Tag(ref); err == nil {
+		if annotated, err := ctx.repo.TagObject(tag.Hash()); err == nil {
+			return annotated.Commit()
+		}
+
+		return ctx.repo.CommitObject(tag.Hash())
+	}
+
+	hash, err := ctx.repo.ResolveRevision(plumbing.Revision(ref))
+	if err != nil {
+		if errors.Is(err, plumbing.ErrReferenceNotFound) || errors.Is(err, plumbing.ErrObjectNotFound) || errors.Is(err, io.EOF) {
+			return nil, ctx.refError(ref)
+		}
+
+		return nil, err
+	}
+
+	commit, err := ctx.repo.CommitObject(*hash)
+	if err != nil {
+		return nil, ctx.refError(ref)
+	}
+
+	return commit, nil
+}
+
+func (ctx *GitRepo) refError(ref string) error {
+	if ctx.IsShallow() {
+		return fmt.Errorf(utils.MessageErrorGitShallow, ref)
+	}
+
+	return fmt.Errorf(utils.MessageErrorGitRef, ref)
+}
+
+// ChangedFiles are the paths, relative to the repository root, that differ
+// between the trees of from and to, like "git diff --name-only from to".
+func (ctx *GitRepo) ChangedFiles(c context.Context, from string, to string) ([]string, error) {
+	fromCommit, err := ctx.Commit(from)
+	if err != nil {
+		return nil, err
+	}
+
+	toCommit, err := ctx.Commit(to)
+	if err != nil {
+		return nil, err
+	}
+
+	return diffFiles(c, fromCommit, toCommit)
+}
+
+// BranchChanges are the files changed on HEAD since it forked from base, like
+// "git diff --name-only base...HEAD", and the uncommitted ones. Without a
+// merge base (shallow clones) the diff is from base.
+func (ctx *GitRepo) BranchChanges(c context.Context, base string) ([]string, error) {
+	files := []string{}
+
+	if count, err := ctx.CommitsCount("HEAD"); err == nil && count > 0 {
+		head, err := ctx.Commit("HEAD")
+		if err != nil {
+			return nil, err
+		}
+
+		fork, err := ctx.Commit(base)
+		if err != nil {
+			return nil, err
+		}
+
+		if bases, err := fork.MergeBase(head); err == nil && len(bases) > 0 {
+			fork = bases[0]
+		}
+
+		if files, err = diffFiles(c, fork, head); err != nil {
+			return nil, err
+		}
+	}
+
+	worktree, err := ctx.repo.Worktree()
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := worktree.Status()
+	if err != nil {
+		return nil, err
+	}
+
+	for name, file := range status {
+		if (file.Staging != git.Unmodified || file.Worktree != git.Unmodified) && !utils.Contains(files, name) {
+			files = append(files, name)
+		}
+	}
+
+	sort.Strings(files)
+
+	return files, nil
+}
+
+func diffFiles(c context.Context, from *object.Commit, to *object.Commit) ([]string, error) {
+	fromTree, err := from.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	toTree, err := to.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := object.DiffTreeWithOptions(c, fromTree, toTree, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []string{}
+	for _, change := range changes {
+		for _, name := range []string{change.From.Name, change.To.Name} {
+			if name != "" && !utils.Contains(files, name) {
+				files = append(files, name)
+			}
+		}
+	}
+
+	sort.Strings(files)
+
+	return files, nil
+}
+
+// CommitsCount is the number of commits reachable from ref, 0 when ref does
+// not exist yet (no commits). Shallow clones count the fetched commits.
+func (ctx *GitRepo) CommitsCount(ref string) (int, error) {
+	head, err := ctx.repo.ResolveRevision(plumbing.Revision(ref))
+	if err != nil {
+		if errors.Is(err, plumbing.ErrReferenceNotFound) {
+			return 0, nil
+		}
+
+		return 0, err
+	}
+
+	shallow := map[plumbing.Hash]bool{}
+	if hashes, err := ctx.repo.Storer.Shallow(); err == nil {
+		for _, hash := range hashes {
+			shallow[hash] = true
+		}
+	}
+
+	seen := map[plumbing.Hash]bool{}
+	pending := []plumbing.Hash{*head}
+
+	for len(pending) > 0 {
+		hash := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+
+		if seen[hash] {
+			continue
+		}
+		seen[hash] = true
+
+		// The parents of shallow commits were not fetched.
+		if shallow[hash] {
+			continue
+		}
+
+		commit, err := ctx.repo.CommitObject(hash)
+		if err != nil {
+			return 0, err
+		}
+
+		pending = append(pending, commit.ParentHashes...)
+	}
+
+	return len(seen), nil
+}
+
+// CommitsSince are the commits reachable from HEAD and not from since (every
+// commit when since is empty), newest first. Merge commits are left out, as
+// their changes are on the merged commits. Shallow clones fail when the
+// history stops before since.
+func (ctx *GitRepo) CommitsSince(c context.Context, since string) ([]GitCommit, error) {
+	head, err := ctx.Commit("HEAD")
+	if err != nil {
+		return nil, err
+	}
+
+	shallow := map[plumbing.Hash]bool{}
+	if hashes, err := ctx.repo.Storer.Shallow(); err == nil {
+		for _, hash := range hashes {
+			shallow[hash] = true
+		}
+	}
+
+	seen := map[plumbing.Hash]bool{}
+	if since != "" {
+		from, err := ctx.Commit(since)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := ctx.walkCommits([]plumbing.Hash{from.Hash}, seen, shallow, func(*object.Commit) error { return nil }); err != nil {
+			return nil, err
+		}
+	}
+
+	commits := []GitCommit{}
+	err = ctx.walkCommits([]plumbing.Hash{head.Hash}, seen, shallow, func(commit *object.Commit) error {
+		if err := c.Err(); err != nil {
+			return err
+		}
+
+		// The parent of a shallow commit was not fetched, so its changes
+		// and the commits before it are unknown.
+		if shallow[commit.Hash] {
+			return errors.New(utils.MessageErrorGitHistory)
+		}
+
+		if commit.NumParents() > 1 {
+			return nil
+		}
+
+		var parent *object.Tree
+		if commit.NumParents() == 1 {
+			from, err := commit.Parent(0)
+			if err != nil {
+				return err
+			}
+
+			if parent, err = from.Tree(); err != nil {
+				return err
+			}
+		}
+
+		tree, err := commit.Tree()
+		if err != nil {
+			return err
+		}
+
+		changes, err := object.DiffTreeWithOptions(c, parent, tree, nil)
+		if err != nil {
+			return err
+		}
+
+		files := []string{}
+		for _, change := range changes {
+			for _, name := range []string{change.From.Name, change.To.Name} {
+				if name != "" && !utils.Contains(files, name) {
+					files = append(files, name)
+				}
+			}
+		}
+
+		commits = append(commits, GitCommit{Hash: commit.Hash.String(), Message: commit.Message, Date: commit.Committer.When, Files: files})
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(commits, func(i, j int) bool {
+		return commits[i].Date.After(commits[j].Date)
+	})
+
+	return commits, nil
+}
+
+// walkCommits calls visit on the commits reachable from hashes that are not
+// on seen yet, without going past shallow commits.
+func (ctx *GitRepo) walkCommits(hashes []plumbing.Hash, seen map[plumbing.Hash]bool, shallow map[plumbing.Hash]bool, visit func(*object.Commit) error) error {
+	pending := append([]plumbing.Hash{}, hashes...)
+
+	for len(pending) > 0 {
+		hash := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+
+		if seen[hash] {
+			continue
+		}
+		seen[hash] = true
+
+		commit, err := ctx.repo.CommitObject(hash)
+		if err != nil {
+			return err
+		}
+
+		if err := visit(commit); err != nil {
+			return err
+		}
+
+		if !shallow[hash] {
+			pending = append(pending, commit.ParentHashes...)
+		}
+	}
+
+	return nil
+}
+
+// HasAuthor reports if the git config (repository, global or system) has the
+// user.name and user.email needed to commit and tag.
+func (ctx *GitRepo) HasAuthor() bool {
+	cfg, err := ctx.repo.ConfigScoped(gitconfig.SystemScope)
+	if err != nil {
+		return false
+	}
+
+	return (cfg.User.Name != "" && cfg.User.Email != "") || (cfg.Author.Name != "" && cfg.Author.Email != "")
+}
+
+// StagedFiles are the files (relative to the repository root) with changes
+// on the index.
+func (ctx *GitRepo) StagedFiles() ([]string, error) {
+	worktree, err := ctx.repo.Worktree()
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := worktree.Status()
+	if err != nil {
+		return nil, err
+	}
+
+	staged := []string{}
+	for file, entry := range status {
+		if entry.Staging != git.Unmodified && entry.Staging != git.Untracked {
+			staged = append(staged, file)
+		}
+	}
+	sort.Strings(staged)
+
+	return staged, nil
+}
+
+// CommitFiles stages files (relative to the repository root) and commits
+// them, with the author of the git config. It returns the commit hash. As
+// the whole index is committed, it fails when other files are staged.
+func (ctx *GitRepo) CommitFiles(message string, files []string) (string, error) {
+	worktree, err := ctx.repo.Worktree()
+	if err != nil {
+		return "", err
+	}
+
+	staged, err := ctx.StagedFiles()
+	if err != nil {
+		return "", err
+	}
+
+	others := []string{}
+	for _, file := range staged {
+		if !utils.Contains(files, file) {
+			others = append(others, file)
+		}
+	}
+	if len(others) > 0 {
+		return "", fmt.Errorf(utils.MessageErrorGitStaged, strings.Join(others, ", "))
+	}
+
+	for _, file := range files {
+		if _, err := worktree.Add(file); err != nil {
+			return "", err
+		}
+	}
+
+	hash, err := worktree.Commit(message, &git.CommitOptions{})
+	if err != nil {
+		if errors.Is(err, git.ErrMissingAuthor) {
+			return "", errors.New(utils.MessageErrorGitAuthor)
+		}
+
+		return "", err
+	}
+
+	return hash.String(), nil
+}
+
+// Tags are the tags of the repository, newest commit first. Tags of commits
+// that were not fetched are skipped.
+func (ctx *GitRepo) Tags() ([]GitTag, error) {
+	refs, err := ctx.repo.Tags()
+	if err != nil {
+		return nil, err
+	}
+
+	tags := []GitTag{}
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		var commit *object.Commit
+
+		if tag, err := ctx.repo.TagObject(ref.Hash()); err == nil {
+			commit, err = tag.Commit()
+			if err != nil {
+				return nil
+			}
+		} else if commit, err = ctx.repo.CommitObject(ref.Hash()); err != nil {
+			return nil
+		}
+
+		tags = append(tags, GitTag{Name: ref.Name().Short(), Commit: commit.Hash.String(), Date: commit.Committer.When})
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool {
+		if tags[i].Date.Equal(tags[j].Date) {
+			return tags[i].Name > tags[j].Name
+		}
+
+		return tags[i].Date.After(tags[j].Date)
+	})
+
+	return tags, nil
+}
+
+// RemoteRepo is the short name (org/repo) of a github remote, or its url.
+func (ctx *GitRepo) RemoteRepo(name string) (string, error) {
+	remote, err := ctx.repo.Remote(name)
+	if err != nil {
+		return "", err
+	}
+
+	urls := remote.Config().URLs
+	if len(urls) == 0 {
+		return "", git.ErrRemoteNotFound
+	}
+
+	link := strings.TrimSuffix(strings.TrimSpace(urls[0]), ".git")
+	for _, prefix := range []string{"git@github.com:", "https://github.com/", "ssh://git@github.com/"} {
+		if strings.HasPrefix(link, prefix) {
+			return strings.TrimPrefix(link, prefix), nil
+		}
+	}
+
+	return link, nil
+}
+
+// FileHistory is the content of path (on disk) on the last commits changing
+// it, newest first, up to max versions. It stops on the first commit not
+// fetched by shallow clones.
+func (ctx *GitRepo) FileHistory(c context.Context, path string, max int) ([]string, error) {
+	absolute, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := filepath.Rel(ctx.Dir, absolute)
+	if err != nil {
+		return nil, err
+	}
+	name = filepath.ToSlash(name)
+
+	commits, err := ctx.repo.Log(&git.LogOptions{FileName: &name})
+	if err != nil {
+		return nil, err
+	}
+	defer commits.Close()
+
+	contents := []string{}
+	err = commits.ForEach(func(commit *object.Commit) error {
+		if err := c.Err(); err != nil {
+			return err
+		}
+
+		if file, err := commit.File(name); err == nil {
+			if content, err := file.Contents(); err == nil {
+				contents = append(contents, content)
+			}
+		}
+
+		if len(contents) >= max {
+			return storer.ErrStop
+		}
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, plumbing.ErrObjectNotFound) {
+		return nil, err
+	}
+
+	return contents, nil
+}
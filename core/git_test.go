@@ -0,0 +1,129 @@
+/*
+Copyright © 2022 Websublime.dev organization@websublime.dev
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestCommitFiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		staged  []string
+		files   []string
+		tree    []string
+		invalid bool
+	}{
+		{name: "release files", files: []string{"CHANGELOG.md", "package.json"}, tree: []string{"CHANGELOG.md", "README.md", "package.json"}},
+		{name: "release file already staged", staged: []string{"package.json"}, files: []string{"CHANGELOG.md", "package.json"}, tree: []string{"CHANGELOG.md", "README.md", "package.json"}},
+		{name: "other file staged", staged: []string{"notes.md"}, files: []string{"CHANGELOG.md", "package.json"}, invalid: true},
+		{name: "other file modified and staged", staged: []string{"README.md"}, files: []string{"package.json"}, invalid: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root := t.TempDir()
+			repo, err := InitGitRepo(root)
+			if err != nil {
+				t.Fatal(err)
+			}
+			worktree, err := repo.repo.Worktree()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			cfg, err := repo.repo.Config()
+			if err != nil {
+				t.Fatal(err)
+			}
+			cfg.User.Name = "Sublime"
+			cfg.User.Email = "sublime@websublime.dev"
+			if err := repo.repo.SetConfig(cfg); err != nil {
+				t.Fatal(err)
+			}
+
+			write := func(name string, content string) {
+				if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			write("README.md", "readme")
+			if _, err := repo.CommitFiles("chore: init", []string{"README.md"}); err != nil {
+				t.Fatal(err)
+			}
+			initial, err := repo.repo.Head()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Untracked files are never committed.
+			write("draft.md", "draft")
+			for _, file := range append(test.staged, test.files...) {
+				write(file, "release")
+			}
+			for _, file := range test.staged {
+				if _, err := worktree.Add(file); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			hash, err := repo.CommitFiles("chore: release", test.files)
+			if test.invalid {
+				if err == nil || !strings.Contains(err.Error(), test.staged[0]) {
+					t.Fatalf("CommitFiles() = %s %v, want an error naming %s", hash, err, test.staged[0])
+				}
+				if head, _ := repo.repo.Head(); head.Hash() != initial.Hash() {
+					t.Errorf("CommitFiles() committed %s on error", head.Hash())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			commit, err := repo.Commit(hash)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tree, err := commit.Tree()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			files := []string{}
+			for _, entry := range tree.Entries {
+				files = append(files, entry.Name)
+			}
+			sort.Strings(files)
+
+			if !reflect.DeepEqual(files, test.tree) {
+				t.Errorf("tree = %v, want %v", files, test.tree)
+			}
+		})
+	}
+}
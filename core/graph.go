@@ -0,0 +1,149 @@
+/*
+Copyright © 2022 Websublime.dev organization@websublime.dev
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package core
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/websublime/sublime-cli/models"
+)
+
+// WorkspacePackage is a package of the workspace graph, with the ranges of
+// the workspace packages it depends on. Dependencies include the peers, which
+// are also on PeerDependencies.
+type WorkspacePackage struct {
+	Package          models.SublimePackages
+	Name             string
+	Version          string
+	Path             string
+	Dependencies     map[string]string
+	DevDependencies  map[string]string
+	PeerDependencies map[string]string
+}
+
+// WorkspaceGraph are the packages of .sublime.json linked by their
+// dependencies on each other.
+type WorkspaceGraph struct {
+	Packages []*WorkspacePackage
+}
+
+// ReadWorkspaceGraph reads the package.json of the packages. Packages without
+// one are left out.
+func (ctx *Config) ReadWorkspaceGraph(packages []models.SublimePackages) *WorkspaceGraph {
+	graph := &WorkspaceGraph{Packages: []*WorkspacePackage{}}
+	manifests := map[string]*models.PackageManifest{}
+
+	for _, pkg := range packages {
+		manifest, err := ReadPackageManifest(filepath.Join(ctx.PackageDir(pkg), "package.json"))
+		if err != nil || manifest.Name == "" {
+			continue
+		}
+
+		path, _ := filepath.Rel(ctx.RootDir, ctx.PackageDir(pkg))
+		manifests[manifest.Name] = manifest
+		graph.Packages = append(graph.Packages, &WorkspacePackage{
+			Package:          pkg,
+			Name:             manifest.Name,
+			Version:          manifest.Version,
+			Path:             filepath.ToSlash(path),
+			Dependencies:     map[string]string{},
+			DevDependencies:  map[string]string{},
+			PeerDependencies: map[string]string{},
+		})
+	}
+
+	for _, node := range graph.Packages {
+		manifest := manifests[node.Name]
+
+		for _, dependencies := range []map[string]string{manifest.Dependencies, manifest.PeerDependencies} {
+			for name, version := range dependencies {
+				if _, ok := manifests[name]; ok {
+					node.Dependencies[name] = version
+				}
+			}
+		}
+
+		for name, version := range manifest.PeerDependencies {
+			if _, ok := manifests[name]; ok {
+				node.PeerDependencies[name] = version
+			}
+		}
+
+		for name, version := range manifest.DevDependencies {
+			if _, ok := manifests[name]; ok {
+				node.DevDependencies[name] = version
+			}
+		}
+	}
+
+	return graph
+}
+
+// Find is the package with the package.json name, or the folder name.
+func (ctx *WorkspaceGraph) Find(name string) *WorkspacePackage {
+	for _, node := range ctx.Packages {
+		if node.Name == name || node.Package.Name == name {
+			return node
+		}
+	}
+
+	return nil
+}
+
+// Dependents are the packages depending (dependencies and peers) on name.
+func (ctx *WorkspaceGraph) Dependents(name string) []*WorkspacePackage {
+	dependents := []*WorkspacePackage{}
+
+	for _, node := range ctx.Packages {
+		if _, ok := node.Dependencies[name]; ok {
+			dependents = append(dependents, node)
+		}
+	}
+
+	return dependents
+}
+
+// ChangedPackages are the packages with files, relative to the repository
+// root on dir, on their folder.
+func (ctx *WorkspaceGraph) ChangedPackages(dir string, root string, files []string) []*WorkspacePackage {
+	changed := []*WorkspacePackage{}
+	prefix, _ := filepath.Rel(dir, root)
+
+	for _, node := range ctx.Packages {
+		folder := filepath.ToSlash(filepath.Join(prefix, node.Path)) + "/"
+
+		for _, file := range files {
+			if strings.HasPrefix(file, folder) {
+				changed = append(changed, node)
+				break
+			}
+		}
+	}
+
+	sort.SliceStable(changed, func(i, j int) bool {
+		return changed[i].Name < changed[j].Name
+	})
+
+	return changed
+}
@@ -0,0 +1,88 @@
+/*
+Copyright © 2022 Websublime.dev organization@websublime.dev
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package core
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/websublime/sublime-cli/models"
+	"github.com/websublime/sublime-cli/utils"
+)
+
+// Workspace hooks of .sublime.json. The running hook is set on SUBLIME_HOOK.
+const (
+	HookPreCreate  = "preCreate"
+	HookPostCreate = "postCreate"
+	HookPreDeploy  = "preDeploy"
+	HookPostDeploy = "postDeploy"
+
+	EnvHook        = "SUBLIME_HOOK"
+	EnvPackage     = "SUBLIME_PACKAGE"
+	EnvPackageType = "SUBLIME_PACKAGE_TYPE"
+	EnvPackageDir  = "SUBLIME_PACKAGE_DIR"
+	EnvTemplate    = "SUBLIME_TEMPLATE"
+	EnvDeployType  = "SUBLIME_DEPLOY_TYPE"
+	EnvEnvironment = "SUBLIME_ENVIRONMENT"
+	EnvPackages    = "SUBLIME_PACKAGES"
+)
+
+// WorkspaceHookCommands are the commands of hook, none when not configured.
+func WorkspaceHookCommands(hooks *models.WorkspaceHooks, hook string) []string {
+	if hooks == nil {
+		return nil
+	}
+
+	switch hook {
+	case HookPreCreate:
+		return hooks.PreCreate
+	case HookPostCreate:
+		return hooks.PostCreate
+	case HookPreDeploy:
+		return hooks.PreDeploy
+	case HookPostDeploy:
+		return hooks.PostDeploy
+	}
+
+	return nil
+}
+
+// RunWorkspaceHooks runs the commands of hook with the shell on the workspace
+// root, with the context env of the cli plus env. It stops on the first
+// failure.
+func (ctx *App) RunWorkspaceHooks(c context.Context, hooks *models.WorkspaceHooks, hook string, version string, env ...string) error {
+	commands := WorkspaceHookCommands(hooks, hook)
+	if len(commands) == 0 {
+		return nil
+	}
+
+	environment := append(ctx.ContextEnv(version, true), EnvHook+"="+hook)
+	environment = append(environment, env...)
+
+	for _, command := range commands {
+		if message, err := runShell(c, GetConfig().RootDir, command, environment); err != nil {
+			return fmt.Errorf(utils.MessageErrorWorkspaceHook, hook, command, message)
+		}
+	}
+
+	return nil
+}
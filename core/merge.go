@@ -0,0 +1,290 @@
+/*
+Copyright © 2022 Websublime.dev organization@websublime.dev
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffOp is a line of an edit script, kept (' '), deleted ('-') or
+// inserted ('+'). A and B are the line indexes before the op.
+type diffOp struct {
+	kind byte
+	line string
+	a    int
+	b    int
+}
+
+// diffHunk replaces the base lines [start, end) with lines.
+type diffHunk struct {
+	start int
+	end   int
+	lines []string
+}
+
+// splitLines splits text keeping the line terminators, so joining the lines
+// gives the text back.
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+// diffLines is the longest common subsequence edit script from a to b.
+// Generated files are small, so the quadratic table is fine.
+func diffLines(a []string, b []string) []diffOp {
+	table := make([][]int, len(a)+1)
+	for i := range table {
+		table[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				table[i][j] = table[i+1][j+1] + 1
+			} else if table[i+1][j] >= table[i][j+1] {
+				table[i][j] = table[i+1][j]
+			} else {
+				table[i][j] = table[i][j+1]
+			}
+		}
+	}
+
+	ops := []diffOp{}
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ops = append(ops, diffOp{kind: ' ', line: a[i], a: i, b: j})
+			i++
+			j++
+		case i < len(a) && (j == len(b) || table[i+1][j] >= table[i][j+1]):
+			ops = append(ops, diffOp{kind: '-', line: a[i], a: i, b: j})
+			i++
+		default:
+			ops = append(ops, diffOp{kind: '+', line: b[j], a: i, b: j})
+			j++
+		}
+	}
+
+	return ops
+}
+
+func diffHunks(base []string, other []string) []diffHunk {
+	hunks := []diffHunk{}
+
+	var current *diffHunk
+	for _, op := range diffLines(base, other) {
+		if op.kind == ' ' {
+			if current != nil {
+				hunks = append(hunks, *current)
+				current = nil
+			}
+			continue
+		}
+
+		if current == nil {
+			current = &diffHunk{start: op.a, end: op.a, lines: []string{}}
+		}
+
+		if op.kind == '-' {
+			current.end = op.a + 1
+		} else {
+			current.lines = append(current.lines, op.line)
+		}
+	}
+
+	if current != nil {
+		hunks = append(hunks, *current)
+	}
+
+	return hunks
+}
+
+// applyHunks is the base lines [start, end) with the hunks (all inside the
+// range) applied.
+func applyHunks(base []string, hunks []diffHunk, start int, end int) []string {
+	lines := []string{}
+	position := start
+
+	for _, hunk := range hunks {
+		lines = append(lines, base[position:hunk.start]...)
+		lines = append(lines, hunk.lines...)
+		position = hunk.end
+	}
+
+	return append(lines, base[position:end]...)
+}
+
+// Merge3 merges the changes from base to ours and from base to theirs. Changes
+// touching the same lines, unless equal, are conflicts, written with conflict
+// markers on the result.
+func Merge3(base string, ours string, theirs string) (string, bool) {
+	return merge3(base, ours, theirs, true)
+}
+
+// MergeOurs merges like Merge3, but conflicts keep the lines of ours.
+func MergeOurs(base string, ours string, theirs string) (string, bool) {
+	return merge3(base, ours, theirs, false)
+}
+
+func merge3(base string, ours string, theirs string, markers bool) (string, bool) {
+	baseLines := splitLines(base)
+	sides := [2][]diffHunk{diffHunks(baseLines, splitLines(ours)), diffHunks(baseLines, splitLines(theirs))}
+	next := [2]int{}
+
+	merged := []string{}
+	conflict := false
+	position := 0
+
+	for next[0] < len(sides[0]) || next[1] < len(sides[1]) {
+		start := -1
+		for side := range sides {
+			if next[side] < len(sides[side]) && (start < 0 || sides[side][next[side]].start < start) {
+				start = sides[side][next[side]].start
+			}
+		}
+
+		// Grow the region while a hunk of any side starts inside (or right
+		// at the end of) it.
+		end := start
+		region := [2][]diffHunk{}
+		for grown := true; grown; {
+			grown = false
+			for side := range sides {
+				for next[side] < len(sides[side]) && sides[side][next[side]].start <= end {
+					hunk := sides[side][next[side]]
+					region[side] = append(region[side], hunk)
+					if hunk.end > end {
+						end = hunk.end
+					}
+					next[side]++
+					grown = true
+				}
+			}
+		}
+
+		merged = append(merged, baseLines[position:start]...)
+		position = end
+
+		oursLines := applyHunks(baseLines, region[0], start, end)
+		theirsLines := applyHunks(baseLines, region[1], start, end)
+
+		switch {
+		case len(region[1]) == 0:
+			merged = append(merged, oursLines...)
+		case len(region[0]) == 0, strings.Join(oursLines, "") == strings.Join(theirsLines, ""):
+			merged = append(merged, theirsLines...)
+		case !markers:
+			conflict = true
+			merged = append(merged, oursLines...)
+		default:
+			conflict = true
+			merged = append(merged, "<<<<<<< current\n")
+			merged = append(merged, terminated(oursLines)...)
+			merged = append(merged, "=======\n")
+			merged = append(merged, terminated(theirsLines)...)
+			merged = append(merged, ">>>>>>> template\n")
+		}
+	}
+
+	merged = append(merged, baseLines[position:]...)
+
+	return strings.Join(merged, ""), conflict
+}
+
+// terminated makes sure the last line ends with a newline, so conflict
+// markers start on their own line.
+func terminated(lines []string) []string {
+	if len(lines) > 0 && !strings.HasSuffix(lines[len(lines)-1], "\n") {
+		lines = append(lines[:len(lines)-1:len(lines)-1], lines[len(lines)-1]+"\n")
+	}
+
+	return lines
+}
+
+// UnifiedDiff is the unified diff (3 lines of context) from a to b, empty
+// when they are equal.
+func UnifiedDiff(from string, to string, a string, b string) string {
+	ops := diffLines(splitLines(a), splitLines(b))
+
+	changes := []int{}
+	for idx, op := range ops {
+		if op.kind != ' ' {
+			changes = append(changes, idx)
+		}
+	}
+
+	if len(changes) == 0 {
+		return ""
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", from, to)
+
+	const context = 3
+	for idx := 0; idx < len(changes); {
+		first, last := changes[idx], changes[idx]
+		for idx++; idx < len(changes) && changes[idx]-last <= 2*context; idx++ {
+			last = changes[idx]
+		}
+
+		start, end := first-context, last+context+1
+		if start < 0 {
+			start = 0
+		}
+		if end > len(ops) {
+			end = len(ops)
+		}
+
+		removed, added := 0, 0
+		for _, op := range ops[start:end] {
+			if op.kind != '+' {
+				removed++
+			}
+			if op.kind != '-' {
+				added++
+			}
+		}
+
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n", hunkRange(ops[start].a, removed), hunkRange(ops[start].b, added))
+		for _, op := range ops[start:end] {
+			out.WriteByte(op.kind)
+			out.WriteString(strings.TrimSuffix(op.line, "\n"))
+			out.WriteByte('\n')
+		}
+	}
+
+	return out.String()
+}
+
+func hunkRange(start int, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
@@ -0,0 +1,210 @@
+/*
+Copyright © 2022 Websublime.dev organization@websublime.dev
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package core
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestMerge3(t *testing.T) {
+	tests := []struct {
+		name     string
+		base     string
+		ours     string
+		theirs   string
+		merged   string
+		partial  string
+		conflict bool
+	}{
+		{
+			name:    "clean merge",
+			base:    "a\nb\nc\nd\ne\n",
+			ours:    "A\nb\nc\nd\ne\n",
+			theirs:  "a\nb\nc\nd\nE\n",
+			merged:  "A\nb\nc\nd\nE\n",
+			partial: "A\nb\nc\nd\nE\n",
+		},
+		{
+			name:    "only theirs changed",
+			base:    "a\nb\nc\n",
+			ours:    "a\nb\nc\n",
+			theirs:  "a\nB\nc\nd\n",
+			merged:  "a\nB\nc\nd\n",
+			partial: "a\nB\nc\nd\n",
+		},
+		{
+			name:    "identical changes on both sides",
+			base:    "a\nb\nc\n",
+			ours:    "a\nB\nc\n",
+			theirs:  "a\nB\nc\n",
+			merged:  "a\nB\nc\n",
+			partial: "a\nB\nc\n",
+		},
+		{
+			name:    "identical insertions on an empty base",
+			base:    "",
+			ours:    "x\n",
+			theirs:  "x\n",
+			merged:  "x\n",
+			partial: "x\n",
+		},
+		{
+			name:     "conflicting edits to the same line",
+			base:     "a\nb\nc\n",
+			ours:     "a\nB\nc\n",
+			theirs:   "a\nX\nc\n",
+			merged:   "a\n<<<<<<< current\nB\n=======\nX\n>>>>>>> template\nc\n",
+			partial:  "a\nB\nc\n",
+			conflict: true,
+		},
+		{
+			name:     "conflicting insertions at the same place",
+			base:     "a\n",
+			ours:     "a\nb\n",
+			theirs:   "a\nc\n",
+			merged:   "a\n<<<<<<< current\nb\n=======\nc\n>>>>>>> template\n",
+			partial:  "a\nb\n",
+			conflict: true,
+		},
+		{
+			name:     "conflict keeps the other changes",
+			base:     "a\nb\nc\nd\ne\n",
+			ours:     "a\nB\nc\nd\ne\n",
+			theirs:   "a\nX\nc\nd\nE\n",
+			merged:   "a\n<<<<<<< current\nB\n=======\nX\n>>>>>>> template\nc\nd\nE\n",
+			partial:  "a\nB\nc\nd\nE\n",
+			conflict: true,
+		},
+		{
+			name:    "eof without trailing newline",
+			base:    "a\nb\nc",
+			ours:    "A\nb\nc",
+			theirs:  "a\nb\nC",
+			merged:  "A\nb\nC",
+			partial: "A\nb\nC",
+		},
+		{
+			name:     "conflict on the last line without trailing newline",
+			base:     "a\nb",
+			ours:     "a\nB",
+			theirs:   "a\nX",
+			merged:   "a\n<<<<<<< current\nB\n=======\nX\n>>>>>>> template\n",
+			partial:  "a\nB",
+			conflict: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			merged, conflict := Merge3(test.base, test.ours, test.theirs)
+			if merged != test.merged || conflict != test.conflict {
+				t.Errorf("Merge3() = %q, %t, want %q, %t", merged, conflict, test.merged, test.conflict)
+			}
+
+			partial, conflict := MergeOurs(test.base, test.ours, test.theirs)
+			if partial != test.partial || conflict != test.conflict {
+				t.Errorf("MergeOurs() = %q, %t, want %q, %t", partial, conflict, test.partial, test.conflict)
+			}
+		})
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a    string
+		b    string
+		want []string
+	}{
+		{name: "equal", a: "a\nb\n", b: "a\nb\n", want: []string{" a\n", " b\n"}},
+		{name: "insert all", a: "", b: "a\nb\n", want: []string{"+a\n", "+b\n"}},
+		{name: "delete all", a: "a\nb\n", b: "", want: []string{"-a\n", "-b\n"}},
+		{name: "delete and append", a: "a\nb\nc\n", b: "a\nc\nd\n", want: []string{" a\n", "-b\n", " c\n", "+d\n"}},
+		{name: "replace", a: "a\nb\nc\n", b: "a\nB\nc\n", want: []string{" a\n", "-b\n", "+B\n", " c\n"}},
+		{name: "trailing newline added", a: "a\nb", b: "a\nb\n", want: []string{" a\n", "-b", "+b\n"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := []string{}
+			for _, op := range diffLines(splitLines(test.a), splitLines(test.b)) {
+				got = append(got, fmt.Sprintf("%c%s", op.kind, op.line))
+			}
+
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("diffLines() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	lines := "l1\nl2\nl3\nl4\nl5\nl6\nl7\nl8\nl9\nl10\n"
+
+	tests := []struct {
+		name string
+		from string
+		a    string
+		b    string
+		want string
+	}{
+		{name: "equal", from: "f", a: "a\nb\n", b: "a\nb\n", want: ""},
+		{
+			name: "changed line",
+			from: "f",
+			a:    "a\nb\nc\n",
+			b:    "a\nB\nc\n",
+			want: "--- f\n+++ f\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		{
+			name: "created file",
+			from: "/dev/null",
+			a:    "",
+			b:    "a\nb\n",
+			want: "--- /dev/null\n+++ f\n@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			name: "distant changes are separate hunks",
+			from: "f",
+			a:    lines,
+			b:    "L1\nl2\nl3\nl4\nl5\nl6\nl7\nl8\nl9\nL10\n",
+			want: "--- f\n+++ f\n@@ -1,4 +1,4 @@\n-l1\n+L1\n l2\n l3\n l4\n@@ -7,4 +7,4 @@\n l7\n l8\n l9\n-l10\n+L10\n",
+		},
+		{
+			name: "eof without trailing newline",
+			from: "f",
+			a:    "a",
+			b:    "a\n",
+			want: "--- f\n+++ f\n@@ -1,1 +1,1 @@\n-a\n+a\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := UnifiedDiff(test.from, "f", test.a, test.b); got != test.want {
+				t.Errorf("UnifiedDiff() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,252 @@
+/*
+Copyright © 2022 Websublime.dev organization@websublime.dev
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/websublime/sublime-cli/models"
+	"github.com/websublime/sublime-cli/utils"
+)
+
+// lockfiles of each package manager, in detection order. A yarn.lock of
+// yarn berry is told apart by its __metadata header.
+var lockfiles = []struct {
+	name    string
+	manager utils.PackageManagerType
+}{
+	{name: "pnpm-lock.yaml", manager: utils.Pnpm},
+	{name: "yarn.lock", manager: utils.Yarn},
+	{name: "package-lock.json", manager: utils.Npm},
+	{name: "npm-shrinkwrap.json", manager: utils.Npm},
+}
+
+// WorkspacePatterns are the dirs of the workspace packages.
+var WorkspacePatterns = []string{"packages/*", "libs/*"}
+
+// PackageManager runs install and scripts with the package manager of a
+// workspace and knows its commands for the generated files.
+type PackageManager struct {
+	Type utils.PackageManagerType
+}
+
+func NewPackageManager(manager utils.PackageManagerType) *PackageManager {
+	return &PackageManager{Type: manager}
+}
+
+// DetectPackageManager finds the package manager of the workspace on dir: by
+// lockfile, then the configured one (packageManager of .sublime.json), the
+// packageManager field of package.json and the config of pnpm and yarn
+// berry. Yarn classic is the default.
+func DetectPackageManager(dir string, configured string) *PackageManager {
+	for _, lockfile := range lockfiles {
+		data, err := os.ReadFile(filepath.Join(dir, lockfile.name))
+		if err != nil {
+			continue
+		}
+
+		if lockfile.manager == utils.Yarn && strings.Contains(string(data), "__metadata:") {
+			return NewPackageManager(utils.YarnBerry)
+		}
+
+		return NewPackageManager(lockfile.manager)
+	}
+
+	if utils.IsPackageManager(configured) {
+		return NewPackageManager(utils.PackageManagerType(configured))
+	}
+
+	manifest := struct {
+		PackageManager string `json:"packageManager"`
+	}{}
+	if data, err := os.ReadFile(filepath.Join(dir, "package.json")); err == nil && json.Unmarshal(data, &manifest) == nil {
+		name, version, _ := strings.Cut(manifest.PackageManager, "@")
+
+		switch {
+		case name == "pnpm":
+			return NewPackageManager(utils.Pnpm)
+		case name == "npm":
+			return NewPackageManager(utils.Npm)
+		case name == "yarn" && version != "" && !strings.HasPrefix(version, "1."):
+			return NewPackageManager(utils.YarnBerry)
+		case name == "yarn":
+			return NewPackageManager(utils.Yarn)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "pnpm-workspace.yaml")); err == nil {
+		return NewPackageManager(utils.Pnpm)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, ".yarnrc.yml")); err == nil {
+		return NewPackageManager(utils.YarnBerry)
+	}
+
+	return NewPackageManager(utils.Yarn)
+}
+
+// Command is the executable of the package manager.
+func (ctx *PackageManager) Command() string {
+	if ctx.Type == utils.YarnBerry {
+		return string(utils.Yarn)
+	}
+
+	return string(ctx.Type)
+}
+
+// InstallCommand installs the dependencies on CI, from the lockfile.
+func (ctx *PackageManager) InstallCommand() string {
+	switch ctx.Type {
+	case utils.YarnBerry:
+		return "yarn install --immutable"
+	case utils.Npm:
+		return "npm ci"
+	case utils.Pnpm:
+		return "pnpm install --frozen-lockfile"
+	}
+
+	return "yarn"
+}
+
+// RunCommand runs a script of package.json, followed by the script name.
+func (ctx *PackageManager) RunCommand() string {
+	switch ctx.Type {
+	case utils.Npm:
+		return "npm run"
+	case utils.Pnpm:
+		return "pnpm run"
+	}
+
+	return "yarn"
+}
+
+// ExecCommand runs a binary of the dependencies, followed by its name.
+func (ctx *PackageManager) ExecCommand() string {
+	switch ctx.Type {
+	case utils.Npm:
+		return "npx"
+	case utils.Pnpm:
+		return "pnpm exec"
+	}
+
+	return "yarn"
+}
+
+// FileProps are the commands of the package manager for the templates.
+func (ctx *PackageManager) FileProps() models.PackageManagerFileProps {
+	return models.PackageManagerFileProps{
+		PackageManager: string(ctx.Type),
+		Install:        ctx.InstallCommand(),
+		Run:            ctx.RunCommand(),
+		Exec:           ctx.ExecCommand(),
+	}
+}
+
+// Variables are FileProps as template variables.
+func (ctx *PackageManager) Variables() map[string]string {
+	props := ctx.FileProps()
+
+	return map[string]string{
+		"PackageManager": props.PackageManager,
+		"Install":        props.Install,
+		"Run":            props.Run,
+		"Exec":           props.Exec,
+	}
+}
+
+func (ctx *PackageManager) Install(c context.Context, dir string) error {
+	return ctx.run(c, dir, "install")
+}
+
+// Run runs a script of the package.json on dir.
+func (ctx *PackageManager) Run(c context.Context, dir string, script string, args ...string) error {
+	if ctx.Type == utils.Npm && len(args) > 0 {
+		args = append([]string{"--"}, args...)
+	}
+
+	return ctx.run(c, dir, append([]string{"run", script}, args...)...)
+}
+
+// Configure writes the workspace config of the package manager on dir (the
+// workspaces field of package.json is on the template) and removes the
+// lockfiles of the others.
+func (ctx *PackageManager) Configure(dir string) error {
+	for _, lockfile := range lockfiles {
+		if lockfile.manager != ctx.Type && !(lockfile.manager == utils.Yarn && ctx.Type == utils.YarnBerry) {
+			if err := os.Remove(filepath.Join(dir, lockfile.name)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+
+	switch ctx.Type {
+	case utils.Pnpm:
+		content := "packages:\n"
+		for _, pattern := range WorkspacePatterns {
+			content += fmt.Sprintf("  - %q\n", pattern)
+		}
+
+		if err := writeFileAtomic(filepath.Join(dir, "pnpm-workspace.yaml"), []byte(content), 0644); err != nil {
+			return err
+		}
+
+		// Packages depend on the workspace libs by version, not with the
+		// workspace: protocol.
+		return appendConfigLine(filepath.Join(dir, ".npmrc"), "link-workspace-packages=true")
+	case utils.YarnBerry:
+		return appendConfigLine(filepath.Join(dir, ".yarnrc.yml"), "nodeLinker: node-modules")
+	}
+
+	return nil
+}
+
+func (ctx *PackageManager) run(c context.Context, dir string, args ...string) error {
+	_, err := NewProcess(dir, ctx.Command(), args...).Run(c)
+
+	return err
+}
+
+// appendConfigLine adds line to the config file on path, unless present.
+func appendConfigLine(path string, line string) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	content := string(data)
+	for _, existing := range strings.Split(content, "\n") {
+		if strings.TrimSpace(existing) == line {
+			return nil
+		}
+	}
+
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+
+	return writeFileAtomic(path, []byte(content+line+"\n"), 0644)
+}
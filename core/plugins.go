@@ -0,0 +1,185 @@
+/*
+Copyright © 2022 Websublime.dev organization@websublime.dev
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package core
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/websublime/sublime-cli/models"
+	"github.com/websublime/sublime-cli/utils"
+)
+
+// Context of the cli passed to plugins and workspace hooks.
+const (
+	EnvRoot           = "SUBLIME_ROOT"
+	EnvConfig         = "SUBLIME_CONFIG"
+	EnvWorkspace      = "SUBLIME_WORKSPACE"
+	EnvOrganization   = "SUBLIME_ORGANIZATION"
+	EnvOutput         = "SUBLIME_OUTPUT"
+	EnvVersion        = "SUBLIME_VERSION"
+	EnvAuthorID       = "SUBLIME_AUTHOR_ID"
+	EnvAuthorUsername = "SUBLIME_AUTHOR_USERNAME"
+	EnvAuthorEmail    = "SUBLIME_AUTHOR_EMAIL"
+	EnvAuthorToken    = "SUBLIME_AUTHOR_TOKEN"
+
+	PluginPrefix = "sublime-"
+)
+
+// PluginsDir is where plugins are installed for the user (~/.sublime/plugins).
+func (ctx *Config) PluginsDir() string {
+	return filepath.Join(ctx.HomeDir, ".sublime", "plugins")
+}
+
+// DiscoverPlugins finds the sublime-<name> executables of the plugins dir and
+// of PATH. The first one found for a name wins, the plugins dir first.
+func (ctx *Config) DiscoverPlugins() []models.Plugin {
+	found := map[string]models.Plugin{}
+
+	for _, dir := range append([]string{ctx.PluginsDir()}, filepath.SplitList(os.Getenv("PATH"))...) {
+		if dir == "" {
+			continue
+		}
+
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+
+		for _, entry := range entries {
+			name, ok := pluginName(dir, entry)
+			if !ok {
+				continue
+			}
+
+			if _, ok := found[name]; !ok {
+				found[name] = models.Plugin{Name: name, Path: filepath.Join(dir, entry.Name())}
+			}
+		}
+	}
+
+	plugins := []models.Plugin{}
+	for _, plugin := range found {
+		plugins = append(plugins, plugin)
+	}
+
+	sort.Slice(plugins, func(i, j int) bool {
+		return plugins[i].Name < plugins[j].Name
+	})
+
+	return plugins
+}
+
+// PluginTrusted reports if plugin gets the token of the author: plugins
+// installed on the plugins dir or named on trustedPlugins of the home
+// config. Any other sublime-<name> on PATH runs without it.
+func (ctx *Config) PluginTrusted(plugin models.Plugin) bool {
+	if filepath.Clean(filepath.Dir(plugin.Path)) == filepath.Clean(ctx.PluginsDir()) {
+		return true
+	}
+
+	home, err := ctx.LoadHomeConfig()
+	if err != nil {
+		return false
+	}
+
+	return utils.Contains(home.TrustedPlugins, plugin.Name)
+}
+
+// pluginName is the command name of a sublime-<name> executable, without the
+// extension on windows.
+func pluginName(dir string, entry os.DirEntry) (string, bool) {
+	name := entry.Name()
+	if !strings.HasPrefix(name, PluginPrefix) {
+		return "", false
+	}
+
+	info, err := os.Stat(filepath.Join(dir, name))
+	if err != nil || info.IsDir() {
+		return "", false
+	}
+
+	if runtime.GOOS == "windows" {
+		ext := strings.ToLower(filepath.Ext(name))
+		if ext != ".exe" && ext != ".bat" && ext != ".cmd" {
+			return "", false
+		}
+
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	} else if info.Mode()&0111 == 0 {
+		return "", false
+	}
+
+	name = strings.TrimPrefix(name, PluginPrefix)
+
+	return name, name != ""
+}
+
+// ContextEnv is the resolved context of the cli as environment variables:
+// workspace root and config (as json), api endpoint, organization and the
+// author, when logged in. The token of the author is only passed with token.
+func (ctx *App) ContextEnv(version string, token bool) []string {
+	config := GetConfig()
+
+	env := []string{
+		EnvRoot + "=" + config.RootDir,
+		EnvApiUrl + "=" + utils.ApiUrl,
+		EnvApiKey + "=" + utils.ApiKey,
+		EnvProfile + "=" + config.Profile,
+		EnvOutput + "=" + string(utils.GetOutput().Format),
+		EnvVersion + "=" + version,
+	}
+
+	organization := config.DefaultOrganization()
+
+	if config.HasSublime() {
+		env = append(env, EnvConfig+"="+config.SublimePath())
+
+		if sublime, err := ctx.ReadSublime(); err == nil {
+			if data, err := json.Marshal(sublime); err == nil {
+				env = append(env, EnvWorkspace+"="+string(data))
+			}
+
+			organization = sublime.Organization
+		}
+	}
+
+	env = append(env, EnvOrganization+"="+organization)
+
+	if ctx.Author != nil {
+		env = append(env,
+			EnvAuthorID+"="+ctx.Author.ID,
+			EnvAuthorUsername+"="+ctx.Author.Username,
+			EnvAuthorEmail+"="+ctx.Author.Email,
+		)
+
+		if token {
+			env = append(env, EnvAuthorToken+"="+ctx.Author.Token)
+		}
+	}
+
+	return env
+}
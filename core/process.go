@@ -0,0 +1,266 @@
+/*
+Copyright © 2022 Websublime.dev organization@websublime.dev
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package core
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/websublime/sublime-cli/utils"
+)
+
+// ProcessTailLines is the number of output lines shown when a process fails.
+const ProcessTailLines = 20
+
+var urlCredentials = regexp.MustCompile(`(://)[^/@\s]+@`)
+
+// Process is an external command (git, package manager, hooks). The output
+// is streamed to stderr with --verbose and always written to the log of the
+// running cli command.
+type Process struct {
+	Name    string
+	Args    []string
+	Dir     string
+	Env     []string
+	Timeout time.Duration
+}
+
+// ProcessError is a failed, cancelled or timed out process, with the last
+// lines of its output.
+type ProcessError struct {
+	Command string
+	Err     error
+	Tail    []string
+	Log     string
+}
+
+func (ctx *ProcessError) Error() string {
+	message := fmt.Sprintf(utils.MessageErrorProcess, ctx.Command, ctx.Err.Error())
+	if details := ctx.Details(); details != "" {
+		message += "\n" + details
+	}
+
+	return message
+}
+
+func (ctx *ProcessError) Unwrap() error {
+	return ctx.Err
+}
+
+// Details are the last lines of output and the path of the full log.
+func (ctx *ProcessError) Details() string {
+	lines := append([]string{}, ctx.Tail...)
+	if ctx.Log != "" {
+		lines = append(lines, fmt.Sprintf(utils.MessageErrorProcessLog, ctx.Log))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// NewProcess is a process on dir with the --command-timeout of the cli.
+func NewProcess(dir string, name string, args ...string) *Process {
+	return &Process{
+		Name:    name,
+		Args:    args,
+		Dir:     dir,
+		Timeout: GetConfig().CommandTimeout,
+	}
+}
+
+// String is the command line, without credentials of urls.
+func (ctx *Process) String() string {
+	return urlCredentials.ReplaceAllString(strings.Join(append([]string{ctx.Name}, ctx.Args...), " "), "$1***@")
+}
+
+// Run runs the process until it exits, c is cancelled or the timeout is
+// reached, killing it and its children. It returns stdout. The tail of stderr
+// (or of stdout, when stderr is empty) is on the error.
+func (ctx *Process) Run(c context.Context) (string, error) {
+	config := GetConfig()
+
+	if ctx.Timeout > 0 {
+		var cancel context.CancelFunc
+		c, cancel = context.WithTimeout(c, ctx.Timeout)
+		defer cancel()
+	}
+
+	command := exec.Command(ctx.Name, ctx.Args...)
+	command.Dir = ctx.Dir
+	if len(ctx.Env) > 0 {
+		command.Env = append(os.Environ(), ctx.Env...)
+	}
+	setProcessGroup(command)
+
+	stream := &syncWriter{writer: config.ProcessOutput()}
+
+	stdout := &bytes.Buffer{}
+	stdoutTail := &tailWriter{size: ProcessTailLines}
+	stderrTail := &tailWriter{size: ProcessTailLines}
+	command.Stdout = io.MultiWriter(stream, stdout, stdoutTail)
+	command.Stderr = io.MultiWriter(stream, stderrTail)
+
+	fmt.Fprintf(stream, "$ %s\n", ctx)
+	started := time.Now()
+
+	err := command.Start()
+	if err == nil {
+		done := make(chan error, 1)
+		go func() {
+			done <- command.Wait()
+		}()
+
+		select {
+		case err = <-done:
+		case <-c.Done():
+			killProcessGroup(command)
+			<-done
+
+			err = errors.New(utils.MessageErrorProcessCancelled)
+			if errors.Is(c.Err(), context.DeadlineExceeded) {
+				err = fmt.Errorf(utils.MessageErrorProcessTimeout, ctx.Timeout)
+			}
+		}
+	}
+
+	status := "ok"
+	if err != nil {
+		status = err.Error()
+	}
+	fmt.Fprintf(stream, "# %s (%s)\n\n", status, time.Since(started).Round(time.Millisecond))
+
+	if err != nil {
+		tail := stderrTail.Lines()
+		if len(tail) == 0 {
+			tail = stdoutTail.Lines()
+		}
+
+		return stdout.String(), &ProcessError{Command: ctx.String(), Err: err, Tail: tail, Log: config.LogFile}
+	}
+
+	return stdout.String(), nil
+}
+
+// ProcessOutput is where the output of processes goes: the log, and stderr
+// with --verbose.
+func (ctx *Config) ProcessOutput() io.Writer {
+	if ctx.Verbose {
+		return io.MultiWriter(ctx.ProcessLog(), os.Stderr)
+	}
+
+	return ctx.ProcessLog()
+}
+
+// ProcessLog is the log of the processes run by this cli command, created on
+// first use on ~/.sublime/logs. Processes are not logged when it can't be
+// created.
+func (ctx *Config) ProcessLog() io.Writer {
+	ctx.logOnce.Do(func() {
+		dir := filepath.Join(ctx.HomeDir, ".sublime", "logs")
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return
+		}
+
+		name := ctx.Command
+		if name == "" {
+			name = utils.CommandRoot
+		}
+
+		path := filepath.Join(dir, fmt.Sprintf("%s-%s.log", name, time.Now().Format("20060102-150405")))
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return
+		}
+
+		ctx.LogFile = path
+		ctx.log = file
+	})
+
+	if ctx.log == nil {
+		return io.Discard
+	}
+
+	return ctx.log
+}
+
+// syncWriter serializes the writes of stdout and stderr to the log.
+type syncWriter struct {
+	mutex  sync.Mutex
+	writer io.Writer
+}
+
+func (ctx *syncWriter) Write(data []byte) (int, error) {
+	ctx.mutex.Lock()
+	defer ctx.mutex.Unlock()
+
+	return ctx.writer.Write(data)
+}
+
+// tailWriter keeps the last size lines written.
+type tailWriter struct {
+	size    int
+	lines   []string
+	partial string
+}
+
+func (ctx *tailWriter) Write(data []byte) (int, error) {
+	text := ctx.partial + string(data)
+	lines := strings.Split(text, "\n")
+
+	ctx.partial = lines[len(lines)-1]
+	for _, line := range lines[:len(lines)-1] {
+		ctx.lines = append(ctx.lines, strings.TrimRight(line, "\r"))
+	}
+
+	if len(ctx.lines) > ctx.size {
+		ctx.lines = ctx.lines[len(ctx.lines)-ctx.size:]
+	}
+
+	return len(data), nil
+}
+
+// Lines are the last lines, without the empty ones at the end.
+func (ctx *tailWriter) Lines() []string {
+	lines := append([]string{}, ctx.lines...)
+	if strings.TrimSpace(ctx.partial) != "" {
+		lines = append(lines, ctx.partial)
+	}
+
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	if len(lines) > ctx.size {
+		lines = lines[len(lines)-ctx.size:]
+	}
+
+	return lines
+}
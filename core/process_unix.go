@@ -1,3 +1,5 @@
+//go:build !windows
+
 /*
 Copyright © 2022 Websublime.dev organization@websublime.dev
 
@@ -19,23 +21,21 @@ LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
 OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
 THE SOFTWARE.
 */
-package utils
+package core
 
 import (
 	"os/exec"
-	"strings"
+	"syscall"
 )
 
-func YarnInstall(path string) (string, error) {
-	yarnCmd := exec.Command("yarn", "--cwd", path, "install")
-	output, err := yarnCmd.Output()
-
-	return strings.Replace(string(output), "\n", "", -1), err
+// setProcessGroup starts the command on its own process group, so its
+// children are killed with it.
+func setProcessGroup(command *exec.Cmd) {
+	command.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
 }
 
-func YarnBuild(path string) (string, error) {
-	yarnCmd := exec.Command("yarn", "--cwd", path, "build")
-	output, err := yarnCmd.Output()
-
-	return strings.Replace(string(output), "\n", "", -1), err
+func killProcessGroup(command *exec.Cmd) {
+	if command.Process != nil {
+		_ = syscall.Kill(-command.Process.Pid, syscall.SIGKILL)
+	}
 }
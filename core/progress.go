@@ -0,0 +1,247 @@
+/*
+Copyright © 2022 Websublime.dev organization@websublime.dev
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/progress"
+	"github.com/websublime/sublime-cli/utils"
+)
+
+// ProgressReporter reports the steps of a long running command. Start sets
+// the number of steps, each Step begins the next one and Done or Fail ends
+// the command.
+type ProgressReporter interface {
+	Start(steps int)
+	Step(message string)
+	Done()
+	Fail(message string)
+}
+
+// ProgressEvent is a line of the json progress renderer.
+type ProgressEvent struct {
+	Event   string `json:"event"`
+	Step    int    `json:"step,omitempty"`
+	Steps   int    `json:"steps"`
+	Message string `json:"message,omitempty"`
+	Time    string `json:"time"`
+}
+
+// NewProgressReporter returns the renderer of mode writing to writer. Auto is
+// interactive on terminals and plain otherwise (ex: CI logs).
+func NewProgressReporter(mode utils.ProgressMode, writer io.Writer) ProgressReporter {
+	if mode == utils.ProgressAuto {
+		mode = utils.ProgressPlain
+
+		if file, ok := writer.(*os.File); ok && utils.IsTerminal(file) {
+			mode = utils.ProgressInteractive
+		}
+	}
+
+	switch mode {
+	case utils.ProgressInteractive:
+		return &interactiveProgress{writer: writer}
+	case utils.ProgressJson:
+		return &jsonProgress{writer: writer}
+	default:
+		return &plainProgress{writer: writer}
+	}
+}
+
+type progressSteps struct {
+	steps int
+	step  int
+}
+
+func (ctx *progressSteps) start(steps int) {
+	ctx.steps = steps
+	ctx.step = 0
+}
+
+// next advances to the next step. Commands with optional steps may call Step
+// more times than expected, the total grows with them.
+func (ctx *progressSteps) next() {
+	ctx.step++
+
+	if ctx.step > ctx.steps {
+		ctx.steps = ctx.step
+	}
+}
+
+// interactiveProgress renders a go-pretty tracker per step. Render stops by
+// itself once the last tracker is done.
+type interactiveProgress struct {
+	progressSteps
+	writer   io.Writer
+	pw       progress.Writer
+	tracker  *progress.Tracker
+	finished chan struct{}
+}
+
+func (ctx *interactiveProgress) Start(steps int) {
+	ctx.start(steps)
+
+	pw := progress.NewWriter()
+	pw.SetOutputWriter(ctx.writer)
+	pw.SetAutoStop(true)
+	pw.SetTrackerLength(25)
+	pw.SetMessageWidth(50)
+	pw.SetTrackerPosition(progress.PositionRight)
+	pw.SetStyle(progress.StyleBlocks)
+	pw.SetUpdateFrequency(50 * time.Millisecond)
+	pw.Style().Colors = progress.StyleColorsExample
+	pw.Style().Visibility.Time = false
+	pw.Style().Visibility.Value = false
+
+	ctx.pw = pw
+	ctx.tracker = nil
+	ctx.finished = nil
+}
+
+func (ctx *interactiveProgress) Step(message string) {
+	if ctx.pw == nil {
+		ctx.Start(0)
+	}
+
+	ctx.next()
+
+	previous := ctx.tracker
+	ctx.tracker = &progress.Tracker{
+		Total:   1,
+		Message: fmt.Sprintf("[%d/%d] %s", ctx.step, ctx.steps, message),
+		Units:   progress.UnitsDefault,
+	}
+
+	// The next tracker is appended first, so render is not auto stopped
+	// between steps.
+	ctx.pw.AppendTracker(ctx.tracker)
+
+	if previous != nil {
+		previous.MarkAsDone()
+	}
+
+	if ctx.finished == nil {
+		ctx.finished = make(chan struct{})
+
+		go func(pw progress.Writer, finished chan struct{}) {
+			pw.Render()
+			close(finished)
+		}(ctx.pw, ctx.finished)
+	}
+}
+
+func (ctx *interactiveProgress) Done() {
+	if ctx.tracker != nil {
+		ctx.tracker.MarkAsDone()
+	}
+
+	ctx.wait()
+}
+
+func (ctx *interactiveProgress) Fail(message string) {
+	if ctx.tracker != nil {
+		ctx.tracker.UpdateMessage(fmt.Sprintf("[%d/%d] %s", ctx.step, ctx.steps, message))
+		ctx.tracker.MarkAsErrored()
+	}
+
+	ctx.wait()
+}
+
+func (ctx *interactiveProgress) wait() {
+	if ctx.finished != nil {
+		<-ctx.finished
+	}
+
+	ctx.pw = nil
+	ctx.tracker = nil
+	ctx.finished = nil
+}
+
+// plainProgress prints a line per step, readable on logs of non terminals.
+type plainProgress struct {
+	progressSteps
+	writer io.Writer
+}
+
+func (ctx *plainProgress) Start(steps int) {
+	ctx.start(steps)
+}
+
+func (ctx *plainProgress) Step(message string) {
+	ctx.next()
+	fmt.Fprintf(ctx.writer, "[%d/%d] %s\n", ctx.step, ctx.steps, message)
+}
+
+func (ctx *plainProgress) Done() {}
+
+func (ctx *plainProgress) Fail(message string) {
+	fmt.Fprintf(ctx.writer, "[%d/%d] %s\n", ctx.step, ctx.steps, message)
+}
+
+// jsonProgress writes a json object per event (start, step, done, error).
+type jsonProgress struct {
+	progressSteps
+	writer io.Writer
+	mutex  sync.Mutex
+}
+
+func (ctx *jsonProgress) Start(steps int) {
+	ctx.start(steps)
+	ctx.emit("start", "")
+}
+
+func (ctx *jsonProgress) Step(message string) {
+	ctx.next()
+	ctx.emit("step", message)
+}
+
+func (ctx *jsonProgress) Done() {
+	ctx.emit("done", "")
+}
+
+func (ctx *jsonProgress) Fail(message string) {
+	ctx.emit("error", message)
+}
+
+func (ctx *jsonProgress) emit(event string, message string) {
+	ctx.mutex.Lock()
+	defer ctx.mutex.Unlock()
+
+	data, err := json.Marshal(&ProgressEvent{
+		Event:   event,
+		Step:    ctx.step,
+		Steps:   ctx.steps,
+		Message: message,
+		Time:    time.Now().UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return
+	}
+
+	ctx.writer.Write(append(data, '\n'))
+}
@@ -0,0 +1,189 @@
+/*
+Copyright © 2022 Websublime.dev organization@websublime.dev
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package core
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/websublime/sublime-cli/models"
+	"github.com/websublime/sublime-cli/utils"
+)
+
+var (
+	conventionalHeader   = regexp.MustCompile(`^([a-zA-Z]+)(?:\(([^)]*)\))?(!)?:\s*(.+)$`)
+	conventionalBreaking = regexp.MustCompile(`(?m)^BREAKING[ -]CHANGE:`)
+)
+
+// ConventionalCommit is a commit message of the conventional commits spec:
+// type(scope)!: description, with an optional BREAKING CHANGE footer.
+type ConventionalCommit struct {
+	Type        string
+	Scope       string
+	Breaking    bool
+	Description string
+}
+
+// ParseConventionalCommit parses the header of message. Messages out of the
+// spec (ex: merge commits) are not conventional commits.
+func ParseConventionalCommit(message string) (*ConventionalCommit, bool) {
+	lines := strings.SplitN(strings.TrimSpace(message), "\n", 2)
+
+	match := conventionalHeader.FindStringSubmatch(strings.TrimSpace(lines[0]))
+	if match == nil {
+		return nil, false
+	}
+
+	return &ConventionalCommit{
+		Type:        strings.ToLower(match[1]),
+		Scope:       match[2],
+		Breaking:    match[3] == "!" || (len(lines) > 1 && conventionalBreaking.MatchString(lines[1])),
+		Description: strings.TrimSpace(match[4]),
+	}, true
+}
+
+// Bump is major for breaking changes, minor for features and patch for fixes
+// and performance improvements. Other types (chore, docs, ci...) release
+// nothing.
+func (ctx *ConventionalCommit) Bump() utils.BumpType {
+	switch {
+	case ctx.Breaking:
+		return utils.BumpMajor
+	case ctx.Type == "feat":
+		return utils.BumpMinor
+	case ctx.Type == "fix" || ctx.Type == "perf":
+		return utils.BumpPatch
+	}
+
+	return utils.BumpNone
+}
+
+// LastPackageTag is the tag of the highest released version of the package,
+// nil when it was never tagged.
+func LastPackageTag(tags []GitTag, name string) *PackageTag {
+	var last *PackageTag
+
+	for _, tag := range tags {
+		packageTag, err := ParsePackageTag(tag.Name)
+		if err != nil || packageTag.Name != name {
+			continue
+		}
+
+		if last == nil || utils.CompareVersions(packageTag.Version, last.Version) > 0 {
+			last = packageTag
+		}
+	}
+
+	return last
+}
+
+// ReadReleaseCommits are the conventional commits of each package since its
+// last tag, limited to the commits changing files of the package folder.
+// Commits releasing something are returned as changesets (one per commit, by
+// short hash), so they are planned and applied like the .changeset files.
+func (ctx *Config) ReadReleaseCommits(c context.Context, repo *GitRepo, graph *WorkspaceGraph) ([]models.ReleaseCommit, []models.Changeset, error) {
+	tags, err := repo.Tags()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	commits := []models.ReleaseCommit{}
+	changesets := []models.Changeset{}
+	history := map[string][]GitCommit{}
+
+	for _, node := range graph.Packages {
+		since := ""
+		if tag := LastPackageTag(tags, node.Name); tag != nil {
+			since = tag.Tag
+		}
+
+		if _, ok := history[since]; !ok {
+			if history[since], err = repo.CommitsSince(c, since); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		dir, err := filepath.Rel(repo.Dir, ctx.PackageDir(node.Package))
+		if err != nil {
+			return nil, nil, err
+		}
+		dir = filepath.ToSlash(dir) + "/"
+
+		for _, commit := range history[since] {
+			if !touchesDir(commit.Files, dir) {
+				continue
+			}
+
+			conventional, ok := ParseConventionalCommit(commit.Message)
+			if !ok {
+				continue
+			}
+
+			hash := commit.Hash[:7]
+			bump := conventional.Bump()
+			commits = append(commits, models.ReleaseCommit{
+				Hash:        hash,
+				Package:     node.Name,
+				Since:       since,
+				Type:        conventional.Type,
+				Bump:        bump,
+				Description: conventional.Description,
+			})
+
+			if bump == utils.BumpNone {
+				continue
+			}
+
+			release := models.ChangesetRelease{Name: node.Name, Type: bump}
+			found := false
+			for idx := range changesets {
+				if changesets[idx].ID == hash {
+					changesets[idx].Releases = append(changesets[idx].Releases, release)
+					found = true
+				}
+			}
+
+			if !found {
+				changesets = append(changesets, models.Changeset{
+					ID:       hash,
+					Summary:  fmt.Sprintf("%s: %s", hash, conventional.Description),
+					Releases: []models.ChangesetRelease{release},
+				})
+			}
+		}
+	}
+
+	return commits, changesets, nil
+}
+
+func touchesDir(files []string, dir string) bool {
+	for _, file := range files {
+		if strings.HasPrefix(file, dir) {
+			return true
+		}
+	}
+
+	return false
+}
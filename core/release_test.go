@@ -0,0 +1,262 @@
+/*
+Copyright © 2022 Websublime.dev organization@websublime.dev
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/websublime/sublime-cli/models"
+	"github.com/websublime/sublime-cli/utils"
+)
+
+func TestParseConventionalCommit(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		commit  *ConventionalCommit
+		bump    utils.BumpType
+	}{
+		{
+			name:    "feature",
+			message: "feat: add button",
+			commit:  &ConventionalCommit{Type: "feat", Description: "add button"},
+			bump:    utils.BumpMinor,
+		},
+		{
+			name:    "breaking with scope",
+			message: "feat(x)!: drop legacy props",
+			commit:  &ConventionalCommit{Type: "feat", Scope: "x", Breaking: true, Description: "drop legacy props"},
+			bump:    utils.BumpMajor,
+		},
+		{
+			name:    "type is case insensitive",
+			message: "Fix(Api): crash on start",
+			commit:  &ConventionalCommit{Type: "fix", Scope: "Api", Description: "crash on start"},
+			bump:    utils.BumpPatch,
+		},
+		{
+			name:    "performance",
+			message: "perf: cache the graph",
+			commit:  &ConventionalCommit{Type: "perf", Description: "cache the graph"},
+			bump:    utils.BumpPatch,
+		},
+		{
+			name:    "breaking change footer",
+			message: "fix: rename option\n\nThe option is now named size.\n\nBREAKING CHANGE: width was removed",
+			commit:  &ConventionalCommit{Type: "fix", Breaking: true, Description: "rename option"},
+			bump:    utils.BumpMajor,
+		},
+		{
+			name:    "breaking-change footer",
+			message: "refactor: new api\n\nBREAKING-CHANGE: old api removed",
+			commit:  &ConventionalCommit{Type: "refactor", Breaking: true, Description: "new api"},
+			bump:    utils.BumpMajor,
+		},
+		{
+			name:    "footer token must start the line",
+			message: "fix: typo\n\nThis is not a BREAKING CHANGE: at all",
+			commit:  &ConventionalCommit{Type: "fix", Description: "typo"},
+			bump:    utils.BumpPatch,
+		},
+		{
+			name:    "breaking change on the header is not a footer",
+			message: "docs: BREAKING CHANGE: explain",
+			commit:  &ConventionalCommit{Type: "docs", Description: "BREAKING CHANGE: explain"},
+			bump:    utils.BumpNone,
+		},
+		{
+			name:    "chore releases nothing",
+			message: "  chore(deps): bump go-git  \n",
+			commit:  &ConventionalCommit{Type: "chore", Scope: "deps", Description: "bump go-git"},
+			bump:    utils.BumpNone,
+		},
+		{name: "merge commit", message: "Merge branch 'main' into feature"},
+		{name: "merge pull request", message: "Merge pull request #12 from acme/feature\n\nfeat: add button"},
+		{name: "missing description", message: "feat:"},
+		{name: "missing type", message: ": add button"},
+		{name: "empty", message: ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			commit, ok := ParseConventionalCommit(test.message)
+			if ok != (test.commit != nil) {
+				t.Fatalf("ParseConventionalCommit(%q) = %+v, %t", test.message, commit, ok)
+			}
+			if !ok {
+				return
+			}
+
+			if !reflect.DeepEqual(commit, test.commit) {
+				t.Errorf("ParseConventionalCommit(%q) = %+v, want %+v", test.message, commit, test.commit)
+			}
+			if bump := commit.Bump(); bump != test.bump {
+				t.Errorf("Bump() = %s, want %s", bump, test.bump)
+			}
+		})
+	}
+}
+
+func TestLastPackageTag(t *testing.T) {
+	tags := []GitTag{
+		{Name: "@acme/ui@1.0.0-rc.10"},
+		{Name: "@acme/ui@1.0.0-rc.2"},
+		{Name: "v2.0.0"},
+		{Name: "@acme/api@3.0.0"},
+		{Name: "@acme/ui@0.9.0"},
+	}
+
+	if tag := LastPackageTag(tags, "@acme/ui"); tag == nil || tag.Version != "1.0.0-rc.10" {
+		t.Errorf("LastPackageTag(@acme/ui) = %+v, want 1.0.0-rc.10", tag)
+	}
+
+	tags = append(tags, GitTag{Name: "@acme/ui@1.0.0"})
+	if tag := LastPackageTag(tags, "@acme/ui"); tag == nil || tag.Version != "1.0.0" {
+		t.Errorf("LastPackageTag(@acme/ui) = %+v, want 1.0.0", tag)
+	}
+
+	if tag := LastPackageTag(tags, "@acme/web"); tag != nil {
+		t.Errorf("LastPackageTag(@acme/web) = %+v, want nil", tag)
+	}
+}
+
+// releaseRepo builds a workspace with the @acme/ui and @acme/api packages
+// and commits each change at its own time, so the history order is known.
+type releaseRepo struct {
+	t    *testing.T
+	root string
+	repo *git.Repository
+	when time.Time
+}
+
+func (ctx *releaseRepo) commit(message string, files []string, parents ...plumbing.Hash) plumbing.Hash {
+	ctx.t.Helper()
+
+	worktree, err := ctx.repo.Worktree()
+	if err != nil {
+		ctx.t.Fatal(err)
+	}
+
+	for _, file := range files {
+		path := filepath.Join(ctx.root, filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			ctx.t.Fatal(err)
+		}
+
+		content := message
+		if filepath.Base(file) == "package.json" {
+			content = `{"name": "@acme/` + filepath.Base(filepath.Dir(path)) + `", "version": "1.0.0"}`
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			ctx.t.Fatal(err)
+		}
+		if _, err := worktree.Add(file); err != nil {
+			ctx.t.Fatal(err)
+		}
+	}
+
+	ctx.when = ctx.when.Add(time.Minute)
+	signature := &object.Signature{Name: "Sublime", Email: "sublime@websublime.dev", When: ctx.when}
+
+	hash, err := worktree.Commit(message, &git.CommitOptions{Author: signature, Committer: signature, Parents: parents})
+	if err != nil {
+		ctx.t.Fatal(err)
+	}
+
+	return hash
+}
+
+func TestReadReleaseCommits(t *testing.T) {
+	root := t.TempDir()
+	if _, err := InitGitRepo(root); err != nil {
+		t.Fatal(err)
+	}
+	repo, err := OpenGitRepo(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	history := &releaseRepo{t: t, root: root, repo: repo.repo, when: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	ui, api := "packages/ui/", "packages/api/"
+	initial := history.commit("chore: init", []string{ui + "package.json", api + "package.json"})
+	if _, err := repo.repo.CreateTag("@acme/ui@1.0.0", initial, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	feature := history.commit("feat(ui): add button", []string{ui + "button.ts"})
+	fix := history.commit("fix: api crash", []string{api + "index.ts"})
+	docs := history.commit("docs(ui): readme", []string{ui + "README.md"})
+	breaking := history.commit("refactor(ui)!: drop legacy props", []string{ui + "button.ts"})
+	history.commit("feat: root tooling", []string{"package.json"})
+	shared := history.commit("feat: shared tokens\n\nBREAKING CHANGE: tokens moved", []string{ui + "tokens.ts", api + "tokens.ts"})
+	history.commit("feat(ui): merged feature", []string{ui + "merged.ts"}, shared, breaking)
+	history.commit("update ui", []string{ui + "button.ts"})
+
+	config := &Config{RootDir: root}
+	graph := config.ReadWorkspaceGraph([]models.SublimePackages{
+		{Name: "ui", Scope: "@acme", Type: utils.Package},
+		{Name: "api", Scope: "@acme", Type: utils.Package},
+	})
+
+	commits, changesets, err := config.ReadReleaseCommits(context.Background(), repo, graph)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	short := func(hash plumbing.Hash) string { return hash.String()[:7] }
+
+	wantCommits := []models.ReleaseCommit{
+		{Hash: short(shared), Package: "@acme/ui", Since: "@acme/ui@1.0.0", Type: "feat", Bump: utils.BumpMajor, Description: "shared tokens"},
+		{Hash: short(breaking), Package: "@acme/ui", Since: "@acme/ui@1.0.0", Type: "refactor", Bump: utils.BumpMajor, Description: "drop legacy props"},
+		{Hash: short(docs), Package: "@acme/ui", Since: "@acme/ui@1.0.0", Type: "docs", Bump: utils.BumpNone, Description: "readme"},
+		{Hash: short(feature), Package: "@acme/ui", Since: "@acme/ui@1.0.0", Type: "feat", Bump: utils.BumpMinor, Description: "add button"},
+		{Hash: short(shared), Package: "@acme/api", Type: "feat", Bump: utils.BumpMajor, Description: "shared tokens"},
+		{Hash: short(fix), Package: "@acme/api", Type: "fix", Bump: utils.BumpPatch, Description: "api crash"},
+		{Hash: short(initial), Package: "@acme/api", Type: "chore", Bump: utils.BumpNone, Description: "init"},
+	}
+
+	wantChangesets := []models.Changeset{
+		{ID: short(shared), Summary: short(shared) + ": shared tokens", Releases: []models.ChangesetRelease{
+			{Name: "@acme/ui", Type: utils.BumpMajor},
+			{Name: "@acme/api", Type: utils.BumpMajor},
+		}},
+		{ID: short(breaking), Summary: short(breaking) + ": drop legacy props", Releases: []models.ChangesetRelease{{Name: "@acme/ui", Type: utils.BumpMajor}}},
+		{ID: short(feature), Summary: short(feature) + ": add button", Releases: []models.ChangesetRelease{{Name: "@acme/ui", Type: utils.BumpMinor}}},
+		{ID: short(fix), Summary: short(fix) + ": api crash", Releases: []models.ChangesetRelease{{Name: "@acme/api", Type: utils.BumpPatch}}},
+	}
+
+	if !reflect.DeepEqual(commits, wantCommits) {
+		t.Errorf("commits = %+v\nwant %+v", commits, wantCommits)
+	}
+	if !reflect.DeepEqual(changesets, wantChangesets) {
+		t.Errorf("changesets = %+v\nwant %+v", changesets, wantChangesets)
+	}
+}
@@ -0,0 +1,328 @@
+/*
+Copyright © 2022 Websublime.dev organization@websublime.dev
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"text/template"
+
+	"github.com/websublime/sublime-cli/models"
+	"github.com/websublime/sublime-cli/utils"
+)
+
+type templateRender struct {
+	source     string
+	target     string
+	delimiters []string
+}
+
+// LoadTemplateManifest reads the sublime-template.json of the template dir.
+// Templates without one return a nil manifest.
+func LoadTemplateManifest(dir string) (*models.TemplateManifest, error) {
+	file := filepath.Join(dir, utils.TemplateManifest)
+
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseTemplateManifest(data, file)
+}
+
+// ParseTemplateManifest decodes and validates a manifest. Name is the file
+// reported on errors.
+func ParseTemplateManifest(data []byte, name string) (*models.TemplateManifest, error) {
+	manifest := &models.TemplateManifest{}
+
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf(utils.MessageErrorTemplateManifest, name, err.Error())
+	}
+
+	if err := validateTemplateManifest(manifest); err != nil {
+		return nil, fmt.Errorf(utils.MessageErrorTemplateManifest, name, err.Error())
+	}
+
+	return manifest, nil
+}
+
+func validateTemplateManifest(manifest *models.TemplateManifest) error {
+	if manifest.Delimiters != nil && len(manifest.Delimiters) != 2 {
+		return errors.New("delimiters must be a pair")
+	}
+
+	for i, prompt := range manifest.Prompts {
+		if prompt.Name == "" {
+			return fmt.Errorf("prompts[%d] has no name", i)
+		}
+
+		switch prompt.Type {
+		case "", utils.TemplatePromptInput, utils.TemplatePromptConfirm:
+		case utils.TemplatePromptSelect:
+			if len(prompt.Choices) == 0 {
+				return fmt.Errorf("prompts[%d] is a select without choices", i)
+			}
+		default:
+			return fmt.Errorf("prompts[%d] has unknown type %s", i, prompt.Type)
+		}
+	}
+
+	for i, file := range manifest.Files {
+		if !fs.ValidPath(file.Source) || file.Source == "." {
+			return fmt.Errorf("files[%d] source must be a relative path", i)
+		}
+
+		if file.Delimiters != nil && len(file.Delimiters) != 2 {
+			return fmt.Errorf("files[%d] delimiters must be a pair", i)
+		}
+
+		if isTemplateGlob(file.Source) && file.Target != "" {
+			return fmt.Errorf("files[%d] glob source can not have a target", i)
+		}
+	}
+
+	return nil
+}
+
+// RenderTemplateString renders text with vars. Delimiters default to {{ }},
+// variables not set render empty.
+func RenderTemplateString(text string, delimiters []string, vars map[string]string) (string, error) {
+	left, right := "{{", "}}"
+	if len(delimiters) == 2 {
+		left, right = delimiters[0], delimiters[1]
+	}
+
+	tmpl, err := template.New("template").Delims(left, right).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var out strings.Builder
+	if err := tmpl.Execute(&out, vars); err != nil {
+		return "", err
+	}
+
+	return out.String(), nil
+}
+
+// IsTemplateTruthy reports if a rendered value (ex: a confirm prompt or a
+// file condition) is set. Empty, false, 0, no and off are not.
+func IsTemplateTruthy(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "false", "0", "no", "off":
+		return false
+	}
+
+	return true
+}
+
+// ApplyTemplate copies the template dir to target and renders the files of
+// manifest with vars. Sources are read from files, or from the template dir
+// when files is nil (they are then rendered instead of copied). Excluded
+// files and files with a falsy condition are not created.
+func ApplyTemplate(dir string, target string, manifest *models.TemplateManifest, files fs.FS, vars map[string]string) error {
+	local := files == nil
+	if local {
+		files = os.DirFS(dir)
+	}
+
+	renders, sources, err := planTemplateFiles(manifest, files, vars)
+	if err != nil {
+		return err
+	}
+
+	err = copyTemplate(dir, target, func(rel string) bool {
+		return (local && sources[rel]) || isTemplateExcluded(rel, manifest.Exclude)
+	})
+	if err != nil {
+		return err
+	}
+
+	// Dirs left empty by the sources not copied are removed, os.Remove keeps
+	// the ones with files.
+	if local {
+		for source := range sources {
+			for dir := path.Dir(source); dir != "."; dir = path.Dir(dir) {
+				os.Remove(filepath.Join(target, filepath.FromSlash(dir)))
+			}
+		}
+	}
+
+	for _, render := range renders {
+		data, err := fs.ReadFile(files, render.source)
+		if err != nil {
+			return fmt.Errorf(utils.MessageErrorTemplateRender, render.source, err.Error())
+		}
+
+		content, err := RenderTemplateString(string(data), render.delimiters, vars)
+		if err != nil {
+			return fmt.Errorf(utils.MessageErrorTemplateRender, render.source, err.Error())
+		}
+
+		mode := os.FileMode(0644)
+		if info, err := fs.Stat(files, render.source); err == nil {
+			mode = info.Mode().Perm()
+		}
+
+		destination := filepath.Join(target, filepath.FromSlash(render.target))
+		if err := os.MkdirAll(filepath.Dir(destination), 0755); err != nil {
+			return err
+		}
+
+		if err := os.WriteFile(destination, []byte(content), mode); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// planTemplateFiles expands the files of manifest to the renders to do and
+// the set of sources they use (including the ones of skipped files).
+func planTemplateFiles(manifest *models.TemplateManifest, files fs.FS, vars map[string]string) ([]templateRender, map[string]bool, error) {
+	renders := []templateRender{}
+	sources := map[string]bool{}
+
+	for _, file := range manifest.Files {
+		delimiters := manifest.Delimiters
+		if file.Delimiters != nil {
+			delimiters = file.Delimiters
+		}
+
+		matches := []string{file.Source}
+		if isTemplateGlob(file.Source) {
+			found, err := fs.Glob(files, file.Source)
+			if err != nil {
+				return nil, nil, fmt.Errorf(utils.MessageErrorTemplateRender, file.Source, err.Error())
+			}
+
+			matches = []string{}
+			for _, match := range found {
+				if info, err := fs.Stat(files, match); err == nil && !info.IsDir() {
+					matches = append(matches, match)
+				}
+			}
+		}
+
+		for _, match := range matches {
+			sources[match] = true
+		}
+
+		if file.When != "" {
+			when, err := RenderTemplateString(file.When, delimiters, vars)
+			if err != nil {
+				return nil, nil, fmt.Errorf(utils.MessageErrorTemplateRender, file.Source, err.Error())
+			}
+
+			if !IsTemplateTruthy(when) {
+				continue
+			}
+		}
+
+		for _, match := range matches {
+			targetPath := match
+			if file.Target != "" {
+				targetPath = file.Target
+			}
+
+			targetPath, err := RenderTemplateString(targetPath, delimiters, vars)
+			if err != nil {
+				return nil, nil, fmt.Errorf(utils.MessageErrorTemplateRender, match, err.Error())
+			}
+
+			if !fs.ValidPath(targetPath) || targetPath == "." {
+				return nil, nil, fmt.Errorf(utils.MessageErrorTemplateRender, match, "target must be a relative path")
+			}
+
+			renders = append(renders, templateRender{source: match, target: targetPath, delimiters: delimiters})
+		}
+	}
+
+	return renders, sources, nil
+}
+
+func isTemplateGlob(source string) bool {
+	return strings.ContainsAny(source, "*?[")
+}
+
+// isTemplateExcluded matches rel, or one of its parent dirs, with the
+// exclude patterns.
+func isTemplateExcluded(rel string, patterns []string) bool {
+	for _, pattern := range patterns {
+		for candidate := rel; candidate != "." && candidate != "/"; candidate = path.Dir(candidate) {
+			if matched, _ := path.Match(pattern, candidate); matched {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// RunTemplateHooks runs the post create hooks of manifest with the shell on
+// dir. Hooks are rendered with vars and stop on the first failure.
+func RunTemplateHooks(c context.Context, dir string, manifest *models.TemplateManifest, vars map[string]string) error {
+	for _, hook := range manifest.Hooks.PostCreate {
+		command, err := RenderTemplateString(hook, manifest.Delimiters, vars)
+		if err != nil {
+			return fmt.Errorf(utils.MessageErrorTemplateHook, hook, err.Error())
+		}
+
+		if message, err := runShell(c, dir, command, nil); err != nil {
+			return fmt.Errorf(utils.MessageErrorTemplateHook, command, message)
+		}
+	}
+
+	return nil
+}
+
+// runShell runs command with the shell on dir, with env added to the
+// environment. On failure it returns the last lines of output and the log.
+func runShell(c context.Context, dir string, command string, env []string) (string, error) {
+	process := NewProcess(dir, "sh", "-c", command)
+	if runtime.GOOS == "windows" {
+		process = NewProcess(dir, "cmd", "/C", command)
+	}
+	process.Env = env
+
+	if _, err := process.Run(c); err != nil {
+		var processError *ProcessError
+		if errors.As(err, &processError) && processError.Details() != "" {
+			return processError.Details(), err
+		}
+
+		return err.Error(), err
+	}
+
+	return "", nil
+}
@@ -0,0 +1,166 @@
+/*
+Copyright © 2022 Websublime.dev organization@websublime.dev
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/websublime/sublime-cli/models"
+	"github.com/websublime/sublime-cli/schemas"
+)
+
+// jsonSchema is the subset of JSON Schema used by schemas/sublime.schema.json.
+type jsonSchema struct {
+	Type       string                 `json:"type"`
+	Required   []string               `json:"required"`
+	Properties map[string]*jsonSchema `json:"properties"`
+	Items      *jsonSchema            `json:"items"`
+	Pattern    string                 `json:"pattern"`
+	Enum       []string               `json:"enum"`
+	MinLength  *int                   `json:"minLength"`
+	Minimum    *float64               `json:"minimum"`
+}
+
+// ValidateSublime checks a decoded .sublime.json against the published
+// schema, plus the rules a schema can't express (unique package names).
+func ValidateSublime(doc map[string]interface{}) ([]models.SchemaViolation, error) {
+	schema := &jsonSchema{}
+	if err := json.Unmarshal(schemas.Sublime, schema); err != nil {
+		return nil, err
+	}
+
+	violations := schema.validate("", doc)
+
+	names := map[string]bool{}
+	if packages, ok := doc["packages"].([]interface{}); ok {
+		for idx, pkg := range packages {
+			value, ok := pkg.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			name := fmt.Sprint(value["name"])
+			if names[name] {
+				violations = append(violations, models.SchemaViolation{
+					Path:    fmt.Sprintf("/packages/%d/name", idx),
+					Message: fmt.Sprintf("duplicated package %q", name),
+				})
+			}
+			names[name] = true
+		}
+	}
+
+	return violations, nil
+}
+
+func (ctx *jsonSchema) validate(path string, value interface{}) []models.SchemaViolation {
+	violation := func(format string, args ...interface{}) []models.SchemaViolation {
+		pointer := path
+		if pointer == "" {
+			pointer = "/"
+		}
+
+		return []models.SchemaViolation{{Path: pointer, Message: fmt.Sprintf(format, args...)}}
+	}
+
+	switch ctx.Type {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return violation("must be an object")
+		}
+
+		violations := []models.SchemaViolation{}
+		for _, key := range ctx.Required {
+			if _, ok := object[key]; !ok {
+				violations = append(violations, violation("missing required property %q", key)...)
+			}
+		}
+
+		keys := []string{}
+		for key := range ctx.Properties {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			if property, ok := object[key]; ok {
+				violations = append(violations, ctx.Properties[key].validate(path+"/"+key, property)...)
+			}
+		}
+
+		return violations
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return violation("must be an array")
+		}
+
+		violations := []models.SchemaViolation{}
+		if ctx.Items != nil {
+			for idx, item := range items {
+				violations = append(violations, ctx.Items.validate(fmt.Sprintf("%s/%d", path, idx), item)...)
+			}
+		}
+
+		return violations
+	case "integer":
+		number, ok := value.(float64)
+		if !ok || number != math.Trunc(number) {
+			return violation("must be an integer")
+		}
+
+		if ctx.Minimum != nil && number < *ctx.Minimum {
+			return violation("must be >= %v", *ctx.Minimum)
+		}
+	case "string":
+		text, ok := value.(string)
+		if !ok {
+			return violation("must be a string")
+		}
+
+		if ctx.MinLength != nil && len(text) < *ctx.MinLength {
+			return violation("must not be empty")
+		}
+
+		if ctx.Pattern != "" && !regexp.MustCompile(ctx.Pattern).MatchString(text) {
+			return violation("%q does not match %s", text, ctx.Pattern)
+		}
+
+		if len(ctx.Enum) > 0 {
+			for _, option := range ctx.Enum {
+				if option == text {
+					return nil
+				}
+			}
+
+			return violation("%q must be one of: %s", text, strings.Join(ctx.Enum, ", "))
+		}
+	}
+
+	return nil
+}
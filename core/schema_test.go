@@ -0,0 +1,145 @@
+/*
+Copyright © 2022 Websublime.dev organization@websublime.dev
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package core
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/websublime/sublime-cli/models"
+)
+
+func TestValidateSublime(t *testing.T) {
+	valid := `{
+		"schemaVersion": 1,
+		"name": "mono",
+		"repo": "acme/mono",
+		"namespace": "@acme/mono",
+		"organization": "acme",
+		"packages": [
+			{"name": "ui", "scope": "@acme", "type": "pkg"},
+			{"name": "vite", "scope": "@acme", "type": "lib"}
+		],
+		"packageManager": "pnpm",
+		"hooks": {"preCreate": ["pnpm lint"]}
+	}`
+
+	tests := []struct {
+		name       string
+		patch      string
+		violations []models.SchemaViolation
+	}{
+		{name: "valid", patch: `{}`, violations: []models.SchemaViolation{}},
+		{
+			name:       "missing required property",
+			patch:      `{"name": null}`,
+			violations: []models.SchemaViolation{{Path: "/", Message: `missing required property "name"`}},
+		},
+		{
+			name:       "pattern",
+			patch:      `{"name": "Mono"}`,
+			violations: []models.SchemaViolation{{Path: "/name", Message: `"Mono" does not match ^[a-z0-9][a-z0-9._-]*$`}},
+		},
+		{
+			name:       "integer",
+			patch:      `{"schemaVersion": 1.5}`,
+			violations: []models.SchemaViolation{{Path: "/schemaVersion", Message: "must be an integer"}},
+		},
+		{
+			name:       "minimum",
+			patch:      `{"schemaVersion": 0}`,
+			violations: []models.SchemaViolation{{Path: "/schemaVersion", Message: "must be >= 1"}},
+		},
+		{
+			name:       "string type",
+			patch:      `{"repo": 42}`,
+			violations: []models.SchemaViolation{{Path: "/repo", Message: "must be a string"}},
+		},
+		{
+			name:       "min length",
+			patch:      `{"organization": ""}`,
+			violations: []models.SchemaViolation{{Path: "/organization", Message: "must not be empty"}},
+		},
+		{
+			name:       "enum",
+			patch:      `{"packageManager": "bun"}`,
+			violations: []models.SchemaViolation{{Path: "/packageManager", Message: `"bun" must be one of: yarn, yarn-berry, npm, pnpm`}},
+		},
+		{
+			name:       "array type",
+			patch:      `{"packages": {"name": "ui"}}`,
+			violations: []models.SchemaViolation{{Path: "/packages", Message: "must be an array"}},
+		},
+		{
+			name:  "array items",
+			patch: `{"packages": [{"name": "ui", "type": "app"}, "vite"]}`,
+			violations: []models.SchemaViolation{
+				{Path: "/packages/0", Message: `missing required property "scope"`},
+				{Path: "/packages/0/type", Message: `"app" must be one of: lib, pkg`},
+				{Path: "/packages/1", Message: "must be an object"},
+			},
+		},
+		{
+			name:       "nested arrays",
+			patch:      `{"hooks": {"preCreate": ["pnpm lint", ""]}}`,
+			violations: []models.SchemaViolation{{Path: "/hooks/preCreate/1", Message: "must not be empty"}},
+		},
+		{
+			name:  "duplicated package names",
+			patch: `{"packages": [{"name": "ui", "scope": "@acme", "type": "pkg"}, {"name": "ui", "scope": "@acme", "type": "lib"}]}`,
+			violations: []models.SchemaViolation{
+				{Path: "/packages/1/name", Message: `duplicated package "ui"`},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			doc := map[string]interface{}{}
+			if err := json.Unmarshal([]byte(valid), &doc); err != nil {
+				t.Fatal(err)
+			}
+
+			patch := map[string]interface{}{}
+			if err := json.Unmarshal([]byte(test.patch), &patch); err != nil {
+				t.Fatal(err)
+			}
+			for key, value := range patch {
+				if value == nil {
+					delete(doc, key)
+				} else {
+					doc[key] = value
+				}
+			}
+
+			violations, err := ValidateSublime(doc)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(violations, test.violations) {
+				t.Errorf("violations = %+v\nwant %+v", violations, test.violations)
+			}
+		})
+	}
+}
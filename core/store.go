@@ -0,0 +1,386 @@
+/*
+Copyright © 2022 Websublime.dev organization@websublime.dev
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package core
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/websublime/sublime-cli/models"
+	"github.com/websublime/sublime-cli/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// LockTimeout is how long a store waits for another sublime process
+// holding the lock of the same workspace config.
+var LockTimeout = 10 * time.Second
+
+// WorkspaceStore reads and writes a workspace config (.sublime.json or
+// .sublime.yaml). Writes are atomic (temp file and rename), serialized by an
+// advisory lock and keep the keys unknown to models.SublimeJsonFileProps.
+type WorkspaceStore struct {
+	Path string
+}
+
+func NewWorkspaceStore(path string) *WorkspaceStore {
+	return &WorkspaceStore{
+		Path: path,
+	}
+}
+
+func (ctx *WorkspaceStore) IsYaml() bool {
+	ext := strings.ToLower(filepath.Ext(ctx.Path))
+
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// Document decodes the config as is, without migrating it. Yaml documents
+// are normalized to the types of a json document.
+func (ctx *WorkspaceStore) Document() (map[string]interface{}, error) {
+	data, err := os.ReadFile(ctx.Path)
+	if err != nil {
+		return nil, errors.New(utils.MessageErrorReadFile)
+	}
+
+	if ctx.IsYaml() {
+		var value interface{}
+		if err := yaml.Unmarshal(data, &value); err != nil {
+			return nil, errors.New(utils.MessageErrorParseFile)
+		}
+
+		if data, err = json.Marshal(value); err != nil {
+			return nil, errors.New(utils.MessageErrorParseFile)
+		}
+	}
+
+	doc := map[string]interface{}{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, errors.New(utils.MessageErrorParseFile)
+	}
+
+	return doc, nil
+}
+
+// Load reads the config, migrating it in memory to the current schema
+// version. The migration is persisted on the next Save or Update.
+func (ctx *WorkspaceStore) Load() (*models.SublimeJsonFileProps, error) {
+	doc, err := ctx.Document()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := MigrateSublime(doc); err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, errors.New(utils.MessageErrorParseFile)
+	}
+
+	sublime := &models.SublimeJsonFileProps{}
+	if err := json.Unmarshal(data, sublime); err != nil {
+		return nil, errors.New(utils.MessageErrorParseFile)
+	}
+
+	return sublime, nil
+}
+
+// Save writes sublime with the current schema version, merged with the
+// unknown keys of the file on disk.
+func (ctx *WorkspaceStore) Save(sublime *models.SublimeJsonFileProps) error {
+	unlock, err := ctx.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	return ctx.write(sublime)
+}
+
+// Update loads the config, applies change and saves it while holding the
+// lock, so concurrent invocations don't lose each other writes.
+func (ctx *WorkspaceStore) Update(change func(sublime *models.SublimeJsonFileProps) error) error {
+	unlock, err := ctx.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	sublime, err := ctx.Load()
+	if err != nil {
+		return err
+	}
+
+	if err := change(sublime); err != nil {
+		return err
+	}
+
+	return ctx.write(sublime)
+}
+
+func (ctx *WorkspaceStore) write(sublime *models.SublimeJsonFileProps) error {
+	sublime.Schema = utils.SublimeSchemaUrl
+	sublime.SchemaVersion = utils.SublimeSchemaVersion
+
+	if sublime.Packages == nil {
+		sublime.Packages = []models.SublimePackages{}
+	}
+
+	previous, err := ctx.Document()
+	if err != nil {
+		previous = map[string]interface{}{}
+	}
+
+	doc, err := mergeDocument(sublime, previous)
+	if err != nil {
+		return errors.New(utils.MessageErrorIndentFile)
+	}
+
+	previousPackages := map[string]map[string]interface{}{}
+	if packages, ok := previous["packages"].([]interface{}); ok {
+		for _, pkg := range packages {
+			if value, ok := pkg.(map[string]interface{}); ok {
+				previousPackages[fmt.Sprint(value["name"])] = value
+			}
+		}
+	}
+
+	packages := []interface{}{}
+	for idx := range sublime.Packages {
+		pkg, err := mergeDocument(&sublime.Packages[idx], previousPackages[sublime.Packages[idx].Name])
+		if err != nil {
+			return errors.New(utils.MessageErrorIndentFile)
+		}
+		packages = append(packages, pkg)
+	}
+	doc.values["packages"] = packages
+
+	var data []byte
+	if ctx.IsYaml() {
+		buffer := bytes.Buffer{}
+		encoder := yaml.NewEncoder(&buffer)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(doc); err != nil {
+			return errors.New(utils.MessageErrorIndentFile)
+		}
+		data = buffer.Bytes()
+	} else {
+		if data, err = json.MarshalIndent(doc, "", " "); err != nil {
+			return errors.New(utils.MessageErrorIndentFile)
+		}
+	}
+
+	return writeFileAtomic(ctx.Path, data, 0644)
+}
+
+// lock takes an advisory lock for the config. The lock file lives on the
+// private lock dir of the user (~/.sublime/cache/locks), so it never shows
+// up on the repo and other users can not hold or pre-create it.
+func (ctx *WorkspaceStore) lock() (func(), error) {
+	path, err := filepath.Abs(ctx.Path)
+	if err != nil {
+		path = ctx.Path
+	}
+
+	dir := config.LockDir()
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	hash := sha1.Sum([]byte(path))
+	lockPath := filepath.Join(dir, fmt.Sprintf("sublime-%s.lock", hex.EncodeToString(hash[:8])))
+
+	file, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(LockTimeout)
+	for {
+		if err := tryLockFile(file); err == nil {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			file.Close()
+			return nil, fmt.Errorf(utils.MessageErrorWorkspaceLocked, ctx.Path)
+		}
+
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	return func() {
+		_ = unlockFile(file)
+		file.Close()
+	}, nil
+}
+
+// writeFileAtomic writes data to a temp file on the same dir and renames it
+// over path, so readers never see a partial file.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	temp, err := os.CreateTemp(filepath.Dir(path), fmt.Sprintf(".%s.*.tmp", filepath.Base(path)))
+	if err != nil {
+		return errors.New(utils.MessageErrorWriteFile)
+	}
+
+	clean := func() {
+		temp.Close()
+		os.Remove(temp.Name())
+	}
+
+	if _, err := temp.Write(data); err != nil {
+		clean()
+		return errors.New(utils.MessageErrorWriteFile)
+	}
+
+	if err := temp.Sync(); err != nil {
+		clean()
+		return errors.New(utils.MessageErrorWriteFile)
+	}
+
+	if err := temp.Close(); err != nil {
+		os.Remove(temp.Name())
+		return errors.New(utils.MessageErrorWriteFile)
+	}
+
+	if err := os.Chmod(temp.Name(), perm); err != nil {
+		os.Remove(temp.Name())
+		return errors.New(utils.MessageErrorWriteFile)
+	}
+
+	if err := os.Rename(temp.Name(), path); err != nil {
+		os.Remove(temp.Name())
+		return errors.New(utils.MessageErrorWriteFile)
+	}
+
+	return nil
+}
+
+// document is a json object keeping the order of its keys.
+type document struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// mergeDocument encodes value (a struct) with its keys in field order,
+// followed by the keys of previous unknown to value, sorted.
+func mergeDocument(value interface{}, previous map[string]interface{}) (*document, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &document{values: map[string]interface{}{}}
+	if err := json.Unmarshal(data, &doc.values); err != nil {
+		return nil, err
+	}
+
+	kind := reflect.Indirect(reflect.ValueOf(value)).Type()
+	for idx := 0; idx < kind.NumField(); idx++ {
+		key := strings.Split(kind.Field(idx).Tag.Get("json"), ",")[0]
+		if _, ok := doc.values[key]; ok && key != "-" {
+			doc.keys = append(doc.keys, key)
+		}
+	}
+
+	unknown := []string{}
+	for key := range previous {
+		if _, ok := doc.values[key]; !ok && !isStructKey(kind, key) {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+
+	for _, key := range unknown {
+		doc.keys = append(doc.keys, key)
+		doc.values[key] = previous[key]
+	}
+
+	return doc, nil
+}
+
+// isStructKey reports known keys omitted on encoding (omitempty), they
+// must not be restored from the previous document.
+func isStructKey(kind reflect.Type, key string) bool {
+	for idx := 0; idx < kind.NumField(); idx++ {
+		if strings.Split(kind.Field(idx).Tag.Get("json"), ",")[0] == key {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (ctx *document) MarshalJSON() ([]byte, error) {
+	buffer := bytes.Buffer{}
+	buffer.WriteByte('{')
+
+	for idx, key := range ctx.keys {
+		if idx > 0 {
+			buffer.WriteByte(',')
+		}
+
+		name, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := json.Marshal(ctx.values[key])
+		if err != nil {
+			return nil, err
+		}
+
+		buffer.Write(name)
+		buffer.WriteByte(':')
+		buffer.Write(value)
+	}
+
+	buffer.WriteByte('}')
+
+	return buffer.Bytes(), nil
+}
+
+func (ctx *document) MarshalYAML() (interface{}, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+
+	for _, key := range ctx.keys {
+		value := &yaml.Node{}
+		if err := value.Encode(ctx.values[key]); err != nil {
+			return nil, err
+		}
+
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+	}
+
+	return node, nil
+}
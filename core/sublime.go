@@ -0,0 +1,145 @@
+/*
+Copyright © 2022 Websublime.dev organization@websublime.dev
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/websublime/sublime-cli/models"
+	"github.com/websublime/sublime-cli/utils"
+)
+
+// SublimeMigration upgrades a decoded .sublime.json to Version. Migrations
+// work on the raw document so keys unknown to this cli are kept.
+type SublimeMigration struct {
+	Version     int
+	Description string
+	Migrate     func(doc map[string]interface{}) error
+}
+
+// SublimeMigrations must be ordered by Version, the last one being
+// utils.SublimeSchemaVersion. New fields (with their defaults) are added
+// here together with the schema and models.SublimeJsonFileProps.
+var SublimeMigrations = []SublimeMigration{
+	{
+		Version:     1,
+		Description: "Add schemaVersion, default root and packages",
+		Migrate: func(doc map[string]interface{}) error {
+			if root, ok := doc["root"].(string); !ok || root == "" {
+				doc["root"] = "./"
+			}
+			if _, ok := doc["packages"].([]interface{}); !ok {
+				doc["packages"] = []interface{}{}
+			}
+			for _, key := range []string{"id", "description"} {
+				if _, ok := doc[key]; !ok {
+					doc[key] = ""
+				}
+			}
+
+			return nil
+		},
+	},
+}
+
+// SublimePath is the config of the workspace: the --config file, or the
+// first of .sublime.json, .sublime.yaml and .sublime.yml on the root dir.
+func (ctx *Config) SublimePath() string {
+	if ctx.ConfigFile != "" {
+		return ctx.ConfigFile
+	}
+
+	for _, name := range []string{".sublime.yaml", ".sublime.yml"} {
+		path := filepath.Join(ctx.RootDir, name)
+		if _, err := os.Stat(filepath.Join(ctx.RootDir, ".sublime.json")); err != nil {
+			if _, err := os.Stat(path); err == nil {
+				return path
+			}
+		}
+	}
+
+	return filepath.Join(ctx.RootDir, ".sublime.json")
+}
+
+// HasSublime reports if the .sublime.json of the workspace exists.
+func (ctx *Config) HasSublime() bool {
+	_, err := os.Stat(ctx.SublimePath())
+
+	return err == nil
+}
+
+// SchemaVersionOf reads the schemaVersion of a decoded .sublime.json, files
+// written before it existed are version 0.
+func SchemaVersionOf(doc map[string]interface{}) int {
+	if version, ok := doc["schemaVersion"].(float64); ok {
+		return int(version)
+	}
+
+	return 0
+}
+
+// MigrateSublime upgrades doc in place to the current schema version and
+// returns the applied migrations.
+func MigrateSublime(doc map[string]interface{}) ([]SublimeMigration, error) {
+	version := SchemaVersionOf(doc)
+	if version > utils.SublimeSchemaVersion {
+		return nil, fmt.Errorf(utils.MessageErrorSublimeSchemaNewer, version, utils.SublimeSchemaVersion)
+	}
+
+	applied := []SublimeMigration{}
+	for _, migration := range SublimeMigrations {
+		if migration.Version <= version {
+			continue
+		}
+
+		if err := migration.Migrate(doc); err != nil {
+			return applied, fmt.Errorf(utils.MessageErrorSublimeMigration, migration.Version, err.Error())
+		}
+
+		doc["schemaVersion"] = float64(migration.Version)
+		applied = append(applied, migration)
+	}
+
+	return applied, nil
+}
+
+// Store is the store of the workspace config.
+func (ctx *App) Store() *WorkspaceStore {
+	return NewWorkspaceStore(GetConfig().SublimePath())
+}
+
+// ReadSublime loads the config of the workspace.
+func (ctx *App) ReadSublime() (*models.SublimeJsonFileProps, error) {
+	return ctx.Store().Load()
+}
+
+// WriteSublime saves the config of the workspace.
+func (ctx *App) WriteSublime(sublime *models.SublimeJsonFileProps) error {
+	return ctx.Store().Save(sublime)
+}
+
+// UpdateSublime changes the config of the workspace under its lock.
+func (ctx *App) UpdateSublime(change func(sublime *models.SublimeJsonFileProps) error) error {
+	return ctx.Store().Update(change)
+}
@@ -0,0 +1,134 @@
+/*
+Copyright © 2022 Websublime.dev organization@websublime.dev
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/websublime/sublime-cli/models"
+	"github.com/websublime/sublime-cli/utils"
+)
+
+// PackageDir is the folder of a workspace package: libs/<name> for libraries
+// and packages/<name> for packages.
+func (ctx *Config) PackageDir(pkg models.SublimePackages) string {
+	libDir := "libs"
+	if pkg.Type == utils.Package {
+		libDir = "packages"
+	}
+
+	return filepath.Join(ctx.RootDir, libDir, pkg.Name)
+}
+
+func (ctx *Config) ReadPackageJson(pkg models.SublimePackages) (*models.PackageJson, error) {
+	packageJson := &models.PackageJson{}
+
+	data, err := os.ReadFile(filepath.Join(ctx.PackageDir(pkg), "package.json"))
+	if err != nil {
+		return nil, errors.New(utils.MessageErrorReadFile)
+	}
+
+	if err := json.Unmarshal(data, packageJson); err != nil {
+		return nil, errors.New(utils.MessageErrorParseFile)
+	}
+
+	return packageJson, nil
+}
+
+// WorkspaceDrift compares the packages on .sublime.json (and the version on
+// their package.json) with the cloud packages rows of the workspace. Local
+// packages are matched by id, falling back to the name when the id is empty
+// or unknown. Cloud rows left unmatched are orphans.
+func (ctx *App) WorkspaceDrift(packages []models.SublimePackages, cloud []models.Package) []models.PackageDrift {
+	config := GetConfig()
+	drifts := []models.PackageDrift{}
+	claimed := map[string]bool{}
+
+	find := func(match func(models.Package) bool) *models.Package {
+		for idx := range cloud {
+			if !claimed[cloud[idx].ID] && match(cloud[idx]) {
+				return &cloud[idx]
+			}
+		}
+		return nil
+	}
+
+	for _, pkg := range packages {
+		var row *models.Package
+
+		if pkg.ID != "" {
+			row = find(func(candidate models.Package) bool { return candidate.ID == pkg.ID })
+		}
+
+		if row == nil {
+			row = find(func(candidate models.Package) bool { return candidate.Name == pkg.Name })
+
+			drift := models.PackageDrift{Kind: utils.DriftMissingID, Package: pkg.Name}
+			if pkg.ID != "" {
+				drift = models.PackageDrift{Kind: utils.DriftStaleID, Package: pkg.Name, ID: pkg.ID, Local: pkg.ID}
+			}
+			if row != nil {
+				drift.Cloud = row.ID
+			}
+
+			drifts = append(drifts, drift)
+		}
+
+		packageJson, err := config.ReadPackageJson(pkg)
+		if err != nil {
+			drifts = append(drifts, models.PackageDrift{Kind: utils.DriftMissingManifest, Package: pkg.Name, ID: pkg.ID, Local: err.Error()})
+		}
+
+		if row == nil {
+			continue
+		}
+
+		claimed[row.ID] = true
+
+		fields := [][3]string{
+			{"name", pkg.Name, row.Name},
+			{"type", string(pkg.Type), string(row.Type)},
+			{"description", pkg.Description, row.Description},
+		}
+
+		for _, field := range fields {
+			if field[1] != field[2] {
+				drifts = append(drifts, models.PackageDrift{Kind: utils.DriftField, Package: pkg.Name, ID: row.ID, Field: field[0], Local: field[1], Cloud: field[2]})
+			}
+		}
+
+		if packageJson != nil && packageJson.Version != row.Version {
+			drifts = append(drifts, models.PackageDrift{Kind: utils.DriftVersion, Package: pkg.Name, ID: row.ID, Field: "version", Local: packageJson.Version, Cloud: row.Version})
+		}
+	}
+
+	for _, row := range cloud {
+		if !claimed[row.ID] {
+			drifts = append(drifts, models.PackageDrift{Kind: utils.DriftOrphan, Package: row.Name, ID: row.ID, Cloud: row.Version})
+		}
+	}
+
+	return drifts
+}
@@ -0,0 +1,105 @@
+/*
+Copyright © 2022 Websublime.dev organization@websublime.dev
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package core
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/websublime/sublime-cli/utils"
+)
+
+var tagVersion = regexp.MustCompile(`^\d+\.\d+\.\d+(-[0-9A-Za-z.-]+)?(\+[0-9A-Za-z.-]+)?$`)
+
+// PackageTag is a release tag of one package, as created by changesets:
+// @scope/name@1.2.3 (or name@1.2.3 for unscoped packages).
+type PackageTag struct {
+	Tag     string
+	Name    string
+	Version string
+}
+
+// ParsePackageTag splits tag on the last @. Repository wide tags (ex: v1.2.3)
+// are not package tags.
+func ParsePackageTag(tag string) (*PackageTag, error) {
+	idx := strings.LastIndex(tag, "@")
+	if idx <= 0 || !tagVersion.MatchString(tag[idx+1:]) {
+		return nil, fmt.Errorf(utils.MessageErrorPackageTag, tag)
+	}
+
+	return &PackageTag{Tag: tag, Name: tag[:idx], Version: tag[idx+1:]}, nil
+}
+
+// TagPackage is the package a tag was created for. Tags name the
+// package.json name, which can differ from the folder name of .sublime.json.
+func (ctx *WorkspaceGraph) TagPackage(tag *PackageTag) *WorkspacePackage {
+	for _, node := range ctx.Packages {
+		if node.Name == tag.Name {
+			return node
+		}
+	}
+
+	return nil
+}
+
+// TagsAt are the tags pointing to the commit of ref, as several package tags
+// are created on the same release commit.
+func (ctx *GitRepo) TagsAt(ref string) ([]GitTag, error) {
+	commit, err := ctx.Commit(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := ctx.Tags()
+	if err != nil {
+		return nil, err
+	}
+
+	found := []GitTag{}
+	for _, tag := range tags {
+		if tag.Commit == commit.Hash.String() {
+			found = append(found, tag)
+		}
+	}
+
+	return found, nil
+}
+
+// CreateTag creates an annotated tag on commit, so "git push --follow-tags"
+// pushes it with the commit. The tagger is the one of the git config.
+func (ctx *GitRepo) CreateTag(name string, commit string) error {
+	_, err := ctx.repo.CreateTag(name, plumbing.NewHash(commit), &git.CreateTagOptions{Message: name})
+	if err != nil {
+		if errors.Is(err, git.ErrMissingTagger) {
+			return errors.New(utils.MessageErrorGitAuthor)
+		}
+		if errors.Is(err, git.ErrTagExists) {
+			return fmt.Errorf(utils.MessageErrorGitTagExists, name)
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,126 @@
+/*
+Copyright © 2022 Websublime.dev organization@websublime.dev
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/websublime/sublime-cli/models"
+	"github.com/websublime/sublime-cli/utils"
+)
+
+func TestParsePackageTag(t *testing.T) {
+	tests := []struct {
+		tag     string
+		name    string
+		version string
+		invalid bool
+	}{
+		{tag: "@scope/pkg@1.2.3", name: "@scope/pkg", version: "1.2.3"},
+		{tag: "pkg@1.2.3", name: "pkg", version: "1.2.3"},
+		{tag: "pkg@1.2.3-rc.1+b", name: "pkg", version: "1.2.3-rc.1+b"},
+		{tag: "@scope/pkg@0.0.1-beta.0", name: "@scope/pkg", version: "0.0.1-beta.0"},
+		{tag: "v1.2.3", invalid: true},
+		{tag: "1.2.3", invalid: true},
+		{tag: "@1.2.3", invalid: true},
+		{tag: "@scope/pkg", invalid: true},
+		{tag: "pkg@latest", invalid: true},
+		{tag: "pkg@1.2", invalid: true},
+		{tag: "@scope/pkg@v1.2.3", invalid: true},
+		{tag: "", invalid: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.tag, func(t *testing.T) {
+			tag, err := ParsePackageTag(test.tag)
+			if test.invalid {
+				if err == nil {
+					t.Fatalf("ParsePackageTag(%q) = %+v, want an error", test.tag, tag)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ParsePackageTag(%q) failed: %v", test.tag, err)
+			}
+			if tag.Tag != test.tag || tag.Name != test.name || tag.Version != test.version {
+				t.Errorf("ParsePackageTag(%q) = %+v, want %s %s", test.tag, tag, test.name, test.version)
+			}
+		})
+	}
+}
+
+func TestTagPackage(t *testing.T) {
+	root := t.TempDir()
+	manifests := map[string]string{
+		"packages/button": `{"name": "@acme/ui-button", "version": "1.2.0"}`,
+		"libs/utils":      `{"name": "@acme/utils", "version": "0.3.0"}`,
+	}
+	for dir, manifest := range manifests {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(root, dir, "package.json"), []byte(manifest), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	config := &Config{RootDir: root}
+	graph := config.ReadWorkspaceGraph([]models.SublimePackages{
+		{Name: "button", Scope: "@acme", Type: utils.Package},
+		{Name: "utils", Scope: "@acme", Type: utils.Library},
+	})
+
+	tests := []struct {
+		tag     string
+		folder  string
+		version string
+	}{
+		{tag: "@acme/ui-button@1.2.0", folder: "button", version: "1.2.0"},
+		{tag: "@acme/utils@0.3.0", folder: "utils", version: "0.3.0"},
+		{tag: "@acme/button@1.2.0"},
+		{tag: "button@1.2.0"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.tag, func(t *testing.T) {
+			tag, err := ParsePackageTag(test.tag)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			node := graph.TagPackage(tag)
+			if test.folder == "" {
+				if node != nil {
+					t.Fatalf("TagPackage(%q) = %s, want nil", test.tag, node.Package.Name)
+				}
+				return
+			}
+
+			if node == nil || node.Package.Name != test.folder || node.Version != test.version {
+				t.Errorf("TagPackage(%q) = %+v, want %s %s", test.tag, node, test.folder, test.version)
+			}
+		})
+	}
+}
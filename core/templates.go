@@ -0,0 +1,499 @@
+/*
+Copyright © 2022 Websublime.dev organization@websublime.dev
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package core
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/websublime/sublime-cli/models"
+	"github.com/websublime/sublime-cli/utils"
+)
+
+// TemplateFetcher downloads a template source to dir. Dir does not exist
+// yet, its parent does.
+type TemplateFetcher interface {
+	Fetch(c context.Context, source *models.TemplateSource, dir string) error
+}
+
+// TemplateFetchers are the fetchers of the remote source kinds. Local sources
+// are used in place.
+var TemplateFetchers = map[utils.TemplateSourceKind]TemplateFetcher{
+	utils.SourceGit:     &gitFetcher{},
+	utils.SourceTarball: &tarballFetcher{},
+	utils.SourceNpm:     &npmFetcher{},
+}
+
+func TemplateSourceKindOf(source string) utils.TemplateSourceKind {
+	switch {
+	case strings.HasPrefix(source, "npm:"):
+		return utils.SourceNpm
+	case strings.HasSuffix(source, ".tar.gz"), strings.HasSuffix(source, ".tgz"):
+		return utils.SourceTarball
+	case strings.HasPrefix(source, "https://"), strings.HasPrefix(source, "http://"),
+		strings.HasPrefix(source, "ssh://"), strings.HasPrefix(source, "git@"),
+		strings.HasSuffix(strings.SplitN(source, "#", 2)[0], ".git"):
+		return utils.SourceGit
+	default:
+		return utils.SourceLocal
+	}
+}
+
+// TemplateCacheDir is where remote templates are cached (~/.sublime/cache/templates).
+func (ctx *Config) TemplateCacheDir() string {
+	return filepath.Join(ctx.HomeDir, ".sublime", "cache", "templates")
+}
+
+// TemplateSources lists the templates declared on .sublime.json (sublime can
+// be nil), then on the home config, then the builtin ones. The first source
+// with a name wins.
+func (ctx *Config) TemplateSources(sublime *models.SublimeJsonFileProps) []models.TemplateSource {
+	sources := []models.TemplateSource{}
+
+	if sublime != nil {
+		base := ctx.RootDir
+		if ctx.HasSublime() {
+			base = filepath.Dir(ctx.SublimePath())
+		}
+
+		for _, source := range sublime.Templates {
+			source.Base = base
+			source.Origin = filepath.Base(ctx.SublimePath())
+			sources = append(sources, source)
+		}
+	}
+
+	if home, err := ctx.LoadHomeConfig(); err == nil {
+		for _, source := range home.Templates {
+			source.Base = filepath.Dir(ctx.HomeConfigFile())
+			source.Origin = ctx.HomeConfigFile()
+			sources = append(sources, source)
+		}
+	}
+
+	for _, builtin := range utils.TemplatesMap {
+		sources = append(sources, models.TemplateSource{
+			Name:   string(builtin.Template),
+			Source: builtin.Link,
+			Origin: "builtin",
+		})
+	}
+
+	return sources
+}
+
+// ResolveTemplate finds the template source of name. Names not declared are
+// used as the source itself (ex: --template https://host/template.tar.gz).
+func (ctx *Config) ResolveTemplate(name string, sublime *models.SublimeJsonFileProps) (*models.TemplateSource, error) {
+	for _, source := range ctx.TemplateSources(sublime) {
+		if source.Name == name {
+			return &source, nil
+		}
+	}
+
+	source := &models.TemplateSource{Name: name, Source: name, Base: ctx.RootDir, Origin: "flag"}
+
+	if TemplateSourceKindOf(name) == utils.SourceLocal {
+		if info, err := os.Stat(ctx.templateLocalPath(source)); err != nil || !info.IsDir() {
+			return nil, fmt.Errorf(utils.MessageErrorTemplateUnknown, name)
+		}
+	}
+
+	return source, nil
+}
+
+// FetchTemplate returns the directory with the files of source. Remote
+// sources pinned to an immutable ref (a git commit sha or an exact npm
+// version) are downloaded once to the cache. Others are downloaded again on
+// each use, and the cached copy is only used when that download fails.
+func (ctx *Config) FetchTemplate(c context.Context, source *models.TemplateSource) (string, error) {
+	kind := TemplateSourceKindOf(source.Source)
+
+	if kind == utils.SourceLocal {
+		dir := ctx.templateLocalPath(source)
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			return "", fmt.Errorf(utils.MessageErrorTemplateLocal, dir)
+		}
+
+		return dir, nil
+	}
+
+	hash := sha1.Sum([]byte(strings.Join([]string{string(kind), source.Source, source.Ref, source.Registry}, "|")))
+	dir := filepath.Join(ctx.TemplateCacheDir(), hex.EncodeToString(hash[:])[:12])
+	immutable := TemplateRefImmutable(source)
+
+	_, statErr := os.Stat(dir)
+	if immutable && statErr == nil {
+		return dir, nil
+	}
+
+	if err := os.MkdirAll(ctx.TemplateCacheDir(), 0755); err != nil {
+		return "", err
+	}
+
+	tmp, err := os.MkdirTemp(ctx.TemplateCacheDir(), ".fetch-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmp)
+
+	fetched := filepath.Join(tmp, "template")
+	if err := TemplateFetchers[kind].Fetch(c, source, fetched); err != nil {
+		if statErr == nil {
+			utils.WarningOut(fmt.Sprintf(utils.MessageCommandTemplateStale, source.Name, err.Error()))
+			return dir, nil
+		}
+
+		return "", fmt.Errorf(utils.MessageErrorTemplateFetch, source.Name, source.Source, err.Error())
+	}
+
+	if !immutable {
+		if err := os.RemoveAll(dir); err != nil {
+			return "", err
+		}
+	}
+
+	// Other process may have cached it meanwhile, both copies are the same.
+	if err := os.Rename(fetched, dir); err != nil {
+		if _, statErr := os.Stat(dir); statErr != nil {
+			return "", err
+		}
+	}
+
+	return dir, nil
+}
+
+var (
+	gitCommitRegex = regexp.MustCompile(`^[0-9a-f]{40}$`)
+	npmExactRegex  = regexp.MustCompile(`^\d+\.\d+\.\d+(-[0-9A-Za-z.-]+)?(\+[0-9A-Za-z.-]+)?$`)
+)
+
+// TemplateRefImmutable reports if source always resolves to the same files:
+// a git source pinned to a full commit sha or an npm source pinned to an
+// exact version. Branches, tags, dist-tags and tarball urls can move.
+func TemplateRefImmutable(source *models.TemplateSource) bool {
+	switch TemplateSourceKindOf(source.Source) {
+	case utils.SourceGit:
+		ref := source.Ref
+		if parts := strings.SplitN(source.Source, "#", 2); len(parts) == 2 && ref == "" {
+			ref = parts[1]
+		}
+
+		return gitCommitRegex.MatchString(ref)
+	case utils.SourceNpm:
+		name := strings.TrimPrefix(source.Source, "npm:")
+		if idx := strings.LastIndex(name, "@"); idx > 0 {
+			return npmExactRegex.MatchString(name[idx+1:])
+		}
+	}
+
+	return false
+}
+
+func (ctx *Config) templateLocalPath(source *models.TemplateSource) string {
+	dir := source.Source
+	if strings.HasPrefix(dir, "~/") {
+		dir = filepath.Join(ctx.HomeDir, dir[2:])
+	}
+
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(source.Base, dir)
+	}
+
+	return dir
+}
+
+// CopyTemplate copies the files of the template dir to target, skipping the
+// git metadata and the template manifest.
+func CopyTemplate(dir string, target string) error {
+	return copyTemplate(dir, target, func(string) bool { return false })
+}
+
+// copyTemplate copies the template dir to target, skipping the files (or
+// dirs) skip returns true for. Skip is called with slash separated paths
+// relative to dir.
+func copyTemplate(dir string, target string, skip func(rel string) bool) error {
+	return filepath.Walk(dir, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() && info.Name() == ".git" {
+			return filepath.SkipDir
+		}
+
+		if rel != "." && (filepath.ToSlash(rel) == utils.TemplateManifest || skip(filepath.ToSlash(rel))) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		destination := filepath.Join(target, rel)
+
+		if info.IsDir() {
+			return os.MkdirAll(destination, 0755)
+		}
+
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		return copyFile(file, destination, info.Mode().Perm())
+	})
+}
+
+func copyFile(source string, destination string, mode os.FileMode) error {
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(destination, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
+
+type gitFetcher struct{}
+
+// Fetch clones the ref (source ref or the #ref of the url), authenticated
+// with the token of tokenEnv on https urls.
+func (ctx *gitFetcher) Fetch(c context.Context, source *models.TemplateSource, dir string) error {
+	link, ref := source.Source, source.Ref
+	if parts := strings.SplitN(link, "#", 2); len(parts) == 2 {
+		link = parts[0]
+		if ref == "" {
+			ref = parts[1]
+		}
+	}
+
+	token := ""
+	if source.TokenEnv != "" {
+		token = os.Getenv(source.TokenEnv)
+	}
+
+	if err := CloneGitRepo(c, link, dir, ref, token); err != nil {
+		return err
+	}
+
+	return os.RemoveAll(filepath.Join(dir, ".git"))
+}
+
+type tarballFetcher struct{}
+
+func (ctx *tarballFetcher) Fetch(c context.Context, source *models.TemplateSource, dir string) error {
+	var reader io.ReadCloser
+
+	if strings.HasPrefix(source.Source, "https://") || strings.HasPrefix(source.Source, "http://") {
+		body, err := downloadTemplate(c, source.Source, source.TokenEnv)
+		if err != nil {
+			return err
+		}
+		reader = body
+	} else {
+		file, err := os.Open(config.templateLocalPath(source))
+		if err != nil {
+			return err
+		}
+		reader = file
+	}
+	defer reader.Close()
+
+	return extractTarGz(reader, source.Source, dir)
+}
+
+type npmFetcher struct{}
+
+type npmPackument struct {
+	DistTags map[string]string `json:"dist-tags"`
+	Versions map[string]struct {
+		Dist struct {
+			Tarball string `json:"tarball"`
+		} `json:"dist"`
+	} `json:"versions"`
+}
+
+// Fetch downloads the tarball of npm:<name>[@version|@tag] (default latest)
+// from the source registry, NPM_CONFIG_REGISTRY or the public registry.
+func (ctx *npmFetcher) Fetch(c context.Context, source *models.TemplateSource, dir string) error {
+	name, version := strings.TrimPrefix(source.Source, "npm:"), "latest"
+	if idx := strings.LastIndex(name, "@"); idx > 0 {
+		name, version = name[:idx], name[idx+1:]
+	}
+
+	registry := source.Registry
+	if registry == "" {
+		registry = os.Getenv("NPM_CONFIG_REGISTRY")
+	}
+	if registry == "" {
+		registry = utils.NpmRegistry
+	}
+
+	body, err := downloadTemplate(c, fmt.Sprintf("%s/%s", strings.TrimSuffix(registry, "/"), url.PathEscape(name)), source.TokenEnv)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	packument := &npmPackument{}
+	if err := json.NewDecoder(body).Decode(packument); err != nil {
+		return err
+	}
+
+	if tagged, ok := packument.DistTags[version]; ok {
+		version = tagged
+	}
+
+	release, ok := packument.Versions[version]
+	if !ok || release.Dist.Tarball == "" {
+		return fmt.Errorf(utils.MessageErrorTemplateNpm, name, version, registry)
+	}
+
+	tarball, err := downloadTemplate(c, release.Dist.Tarball, source.TokenEnv)
+	if err != nil {
+		return err
+	}
+	defer tarball.Close()
+
+	return extractTarGz(tarball, release.Dist.Tarball, dir)
+}
+
+// downloadTemplate gets link, with the bearer token of the tokenEnv variable
+// for private registries.
+func downloadTemplate(c context.Context, link string, tokenEnv string) (io.ReadCloser, error) {
+	request, err := http.NewRequestWithContext(c, http.MethodGet, link, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if token := os.Getenv(tokenEnv); tokenEnv != "" && token != "" {
+		request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	}
+
+	client := &http.Client{Timeout: config.Timeout}
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode != http.StatusOK {
+		response.Body.Close()
+		return nil, fmt.Errorf(utils.MessageErrorTemplateDownload, link, response.StatusCode)
+	}
+
+	return response.Body, nil
+}
+
+// extractTarGz extracts the archive to dir. A single top level directory
+// (ex: package/ of npm, <repo>-<ref>/ of github) is stripped.
+func extractTarGz(reader io.Reader, name string, dir string) error {
+	gz, err := gzip.NewReader(reader)
+	if err != nil {
+		return fmt.Errorf(utils.MessageErrorTemplateArchive, name, err.Error())
+	}
+	defer gz.Close()
+
+	extract := dir + ".extract"
+	if err := os.MkdirAll(extract, 0755); err != nil {
+		return err
+	}
+	defer os.RemoveAll(extract)
+
+	archive := tar.NewReader(gz)
+	for {
+		header, err := archive.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf(utils.MessageErrorTemplateArchive, name, err.Error())
+		}
+
+		entry := path.Clean(strings.TrimPrefix(header.Name, "/"))
+		if entry == "." || entry == ".." || strings.HasPrefix(entry, "../") {
+			continue
+		}
+
+		target := filepath.Join(extract, filepath.FromSlash(entry))
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+
+			file, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, header.FileInfo().Mode().Perm()|0600)
+			if err != nil {
+				return err
+			}
+
+			if _, err := io.Copy(file, archive); err != nil {
+				file.Close()
+				return err
+			}
+
+			if err := file.Close(); err != nil {
+				return err
+			}
+		}
+	}
+
+	root := extract
+	if entries, err := os.ReadDir(extract); err == nil && len(entries) == 1 && entries[0].IsDir() {
+		root = filepath.Join(extract, entries[0].Name())
+	}
+
+	return os.Rename(root, dir)
+}
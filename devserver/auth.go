@@ -0,0 +1,193 @@
+/*
+Copyright © 2022 Websublime.dev organization@websublime.dev
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package devserver
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type signupRequest struct {
+	Email    string            `json:"email"`
+	Password string            `json:"password"`
+	Data     map[string]string `json:"data"`
+}
+
+type tokenRequest struct {
+	Email        string `json:"email"`
+	Password     string `json:"password"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+func (ctx *Server) handleAuth(w http.ResponseWriter, r *http.Request) {
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+
+	if key := r.Header.Get("apikey"); key != ctx.ApiKey && key != ctx.ServiceKey {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"message": "Invalid API key"})
+		return
+	}
+
+	switch strings.TrimPrefix(r.URL.Path, "/auth/v1/") {
+	case "signup":
+		ctx.signup(w, r)
+	case "token":
+		ctx.token(w, r)
+	case "user":
+		ctx.user(w, r)
+	case "health":
+		writeJSON(w, http.StatusOK, map[string]string{"name": "GoTrue", "version": "dev", "description": "Sublime dev server auth"})
+	default:
+		writeJSON(w, http.StatusNotFound, map[string]string{"msg": "Not found"})
+	}
+}
+
+func (ctx *Server) signup(w http.ResponseWriter, r *http.Request) {
+	body := signupRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Email == "" || body.Password == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{"code": 400, "msg": "Signup requires a valid email and password"})
+		return
+	}
+
+	if ctx.findUser(func(user *User) bool { return user.Email == body.Email }) != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{"code": 400, "msg": "User already registered"})
+		return
+	}
+
+	user := &User{
+		ID:        newID(),
+		Email:     body.Email,
+		Password:  body.Password,
+		Metadata:  body.Data,
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+	ctx.state.Users = append(ctx.state.Users, user)
+
+	if ctx.Organization != "" {
+		ctx.joinOrganization(ctx.Organization, user.ID)
+	}
+
+	ctx.persist()
+
+	writeJSON(w, http.StatusOK, userPayload(user))
+}
+
+func (ctx *Server) token(w http.ResponseWriter, r *http.Request) {
+	body := tokenRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request", "error_description": err.Error()})
+		return
+	}
+
+	var user *User
+
+	switch r.URL.Query().Get("grant_type") {
+	case "password":
+		user = ctx.findUser(func(user *User) bool { return user.Email == body.Email && user.Password == body.Password })
+	case "refresh_token":
+		if userID, ok := ctx.state.Refresh[body.RefreshToken]; ok {
+			delete(ctx.state.Refresh, body.RefreshToken)
+			user = ctx.findUser(func(user *User) bool { return user.ID == userID })
+		}
+	default:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type", "error_description": "Unsupported grant type"})
+		return
+	}
+
+	if user == nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "Invalid login credentials"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ctx.session(user))
+}
+
+func (ctx *Server) user(w http.ResponseWriter, r *http.Request) {
+	caller, err := ctx.identify(r)
+	if err != nil || caller.User == nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]interface{}{"code": 401, "msg": "Invalid token"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, userPayload(caller.User))
+}
+
+func (ctx *Server) session(user *User) map[string]interface{} {
+	expires := time.Now().Add(ctx.TokenTTL).Unix()
+	claims, _ := json.Marshal(map[string]interface{}{
+		"sub":   user.ID,
+		"email": user.Email,
+		"role":  "authenticated",
+		"exp":   expires,
+	})
+
+	header := base64.StdEncoding.EncodeToString([]byte(`{"alg":"none","typ":"JWT"}`))
+	token := fmt.Sprintf("%s.%s.%s", header, base64.StdEncoding.EncodeToString(claims), newSecret())
+	refresh := newSecret()
+
+	ctx.state.Sessions[token] = user.ID
+	ctx.state.Refresh[refresh] = user.ID
+	ctx.persist()
+
+	return map[string]interface{}{
+		"access_token":  token,
+		"token_type":    "bearer",
+		"expires_in":    int64(ctx.TokenTTL.Seconds()),
+		"refresh_token": refresh,
+		"user":          userPayload(user),
+	}
+}
+
+func (ctx *Server) joinOrganization(name string, userID string) {
+	var org Row
+	for _, row := range ctx.state.Tables["organization"] {
+		if row["name"] == name {
+			org = row
+			break
+		}
+	}
+
+	if org == nil {
+		org = ctx.insert("organization", Row{"name": name}, userID)
+	}
+
+	ctx.insert("organization_users", Row{"organization_id": org["id"], "user_id": userID, "role": "owner"}, userID)
+}
+
+func userPayload(user *User) map[string]interface{} {
+	return map[string]interface{}{
+		"id":                 user.ID,
+		"aud":                "authenticated",
+		"role":               "authenticated",
+		"email":              user.Email,
+		"email_confirmed_at": user.CreatedAt,
+		"confirmed_at":       user.CreatedAt,
+		"created_at":         user.CreatedAt,
+		"user_metadata":      user.Metadata,
+		"app_metadata":       map[string]interface{}{"provider": "email", "providers": []string{"email"}},
+		"identities":         []interface{}{},
+	}
+}
@@ -0,0 +1,376 @@
+/*
+Copyright © 2022 Websublime.dev organization@websublime.dev
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package devserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/websublime/sublime-cli/api"
+	"github.com/websublime/sublime-cli/utils"
+)
+
+// startServer runs a dev server and points the cli api url at it.
+func startServer(t *testing.T) *Server {
+	t.Helper()
+
+	server := NewServer()
+	listener := httptest.NewServer(server)
+	t.Cleanup(listener.Close)
+
+	apiUrl, apiKey := utils.ApiUrl, utils.ApiKey
+	utils.ApiUrl, utils.ApiKey = listener.URL, server.ApiKey
+	t.Cleanup(func() { utils.ApiUrl, utils.ApiKey = apiUrl, apiKey })
+
+	return server
+}
+
+// author registers and logs in an author, returning its id and a client
+// with its session.
+func author(t *testing.T, name string) (string, *api.Supabase) {
+	t.Helper()
+
+	anon := api.NewSupabase(utils.ApiUrl, utils.ApiKey, utils.ApiKey, "production")
+	email := name + "@acme.test"
+
+	user, err := anon.RegisterAuthor(context.Background(), name, name, email, "secret123")
+	if err != nil {
+		t.Fatalf("register %s: %v", name, err)
+	}
+
+	login, err := anon.Login(context.Background(), email, "secret123")
+	if err != nil {
+		t.Fatalf("login %s: %v", name, err)
+	}
+
+	return user.ID, api.NewSupabase(utils.ApiUrl, utils.ApiKey, login.Token, "production")
+}
+
+func TestFlow(t *testing.T) {
+	server := startServer(t)
+	c := context.Background()
+
+	authorID, supabase := author(t, "dev")
+
+	org, err := supabase.CreateOrganization(c, "acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := supabase.CreateWorkspaceBucket(c, org.Name, false); err != nil {
+		t.Fatal(err)
+	}
+
+	valid, err := supabase.ValidateUserOrganization(c, authorID, "acme")
+	if err != nil || !valid {
+		t.Fatalf("author is not a member of acme: %t %v", valid, err)
+	}
+
+	workspaces, err := supabase.CreateOrganizationWorkspace(c, "mono", "git@github.com:acme/mono.git", "", org.ID)
+	if err != nil || len(workspaces) != 1 {
+		t.Fatalf("workspace: %v %v", workspaces, err)
+	}
+	workspace := workspaces[0]
+
+	packages, err := supabase.CreateWorkspacePackage(c, "ui", "", utils.Package, utils.TemplateType("lit"), workspace.ID)
+	if err != nil || len(packages) != 1 {
+		t.Fatalf("create: %v %v", packages, err)
+	}
+	pkg := packages[0]
+
+	listed, err := supabase.GetPackagesByWorkspace(c, workspace.ID)
+	if err != nil || len(listed) != 1 || listed[0].Template != utils.TemplateType("lit") {
+		t.Fatalf("packages: %v %v", listed, err)
+	}
+
+	_, raw, err := supabase.CreateDeployToken(c, workspace.ID, "ci", []string{"upload", "version"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// action: the deploy token is exchanged for a session scoped to the
+	// workspace, which uploads the artifacts and bumps the version.
+	anon := api.NewSupabase(utils.ApiUrl, utils.ApiKey, utils.ApiKey, "production")
+	session, err := anon.ExchangeDeployToken(c, raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.WorkspaceID != workspace.ID {
+		t.Fatalf("session workspace = %s, want %s", session.WorkspaceID, workspace.ID)
+	}
+	deploy := api.NewSupabase(utils.ApiUrl, utils.ApiKey, session.Token, "production")
+
+	artifact := filepath.Join(t.TempDir(), "ui.js")
+	if err := os.WriteFile(artifact, []byte("export {}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := deploy.Upload(c, org.Name, artifact, "mono/ui/1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	if content, ok := server.Object("acme/mono/ui/1.0.0/ui.js"); !ok || string(content) != "export {}" {
+		t.Fatalf("object = %q %t", content, ok)
+	}
+
+	updated, err := deploy.UpdateWorkspacePackageVersion(c, pkg.ID, "1.0.0")
+	if err != nil || updated.Version != "1.0.0" {
+		t.Fatalf("version: %v %v", updated, err)
+	}
+
+	if _, err := deploy.CreateWorkspaceBucket(c, "other", false); !api.IsApiError(err, http.StatusForbidden) {
+		t.Fatalf("deploy session created a bucket: %v", err)
+	}
+	if _, err := deploy.DeleteWorkspaceByID(c, workspace.ID); !api.IsApiError(err, http.StatusForbidden) {
+		t.Fatalf("deploy session deleted the workspace: %v", err)
+	}
+}
+
+// TestOwnership checks authors only read the rows of their organizations and
+// inserts, updates and deletes are only applied to rows the author can
+// change. Like PostgREST, rows left out are not an error, so the state of the
+// server is checked.
+func TestOwnership(t *testing.T) {
+	server := startServer(t)
+	c := context.Background()
+
+	ownerID, owner := author(t, "owner")
+	memberID, member := author(t, "member")
+	outsiderID, outsider := author(t, "outsider")
+
+	org, err := owner.CreateOrganization(c, "acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := owner.InviteOrganizationMember(c, org.ID, "member@acme.test", string(utils.RoleMember)); err != nil {
+		t.Fatal(err)
+	}
+
+	workspaces, err := owner.CreateOrganizationWorkspace(c, "mono", "", "", org.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	workspace := workspaces[0]
+
+	packages, err := owner.CreateWorkspacePackage(c, "ui", "", utils.Package, "", workspace.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg := packages[0]
+
+	if _, _, err := owner.CreateDeployToken(c, workspace.ID, "ci", []string{"upload"}, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	reads := []struct {
+		name string
+		rows func() (int, error)
+		want int
+	}{
+		{
+			name: "outsider lists the memberships of the owner",
+			rows: func() (int, error) { rows, err := outsider.GetOrganizationByUser(c, ownerID); return len(rows), err },
+		},
+		{
+			name: "outsider lists the workspaces",
+			rows: func() (int, error) {
+				rows, err := outsider.GetWorkspacesByOrganization(c, org.ID)
+				return len(rows), err
+			},
+		},
+		{
+			name: "outsider lists the packages",
+			rows: func() (int, error) {
+				rows, err := outsider.GetPackagesByWorkspace(c, workspace.ID)
+				return len(rows), err
+			},
+		},
+		{
+			name: "outsider lists the deploy tokens",
+			rows: func() (int, error) {
+				rows, err := outsider.GetDeployTokensByWorkspace(c, workspace.ID)
+				return len(rows), err
+			},
+		},
+		{
+			name: "member lists the packages",
+			rows: func() (int, error) {
+				rows, err := member.GetPackagesByWorkspace(c, workspace.ID)
+				return len(rows), err
+			},
+			want: 1,
+		},
+		{
+			name: "member lists the deploy tokens",
+			rows: func() (int, error) {
+				rows, err := member.GetDeployTokensByWorkspace(c, workspace.ID)
+				return len(rows), err
+			},
+			want: 1,
+		},
+	}
+
+	for _, test := range reads {
+		t.Run(test.name, func(t *testing.T) {
+			count, err := test.rows()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if count != test.want {
+				t.Errorf("rows = %d, want %d", count, test.want)
+			}
+		})
+	}
+
+	insert := func(client *api.Supabase, table string, body string) {
+		_ = client.Send(c, api.Request{Method: http.MethodPost, Path: fmt.Sprintf("%s/%s", api.RestEndpoint, table), Body: []byte(body)}, nil)
+	}
+
+	row := func(table string, column string, value string) Row {
+		for _, row := range server.Rows(table) {
+			if fmt.Sprint(row[column]) == value {
+				return row
+			}
+		}
+		return nil
+	}
+	version := func() string { return fmt.Sprint(row("packages", "id", pkg.ID)["version"]) }
+	membership := func(userID string) bool { return row("organization_users", "user_id", userID) != nil }
+
+	tests := []struct {
+		name    string
+		call    func()
+		applied func() bool
+		allowed bool
+	}{
+		{
+			name:    "outsider creates an organization without owner",
+			call:    func() { insert(outsider, "organization", `{"name": "rogue"}`) },
+			applied: func() bool { return row("organization", "name", "rogue") != nil },
+		},
+		{
+			name: "outsider joins the organization",
+			call: func() {
+				insert(outsider, "organization_users", fmt.Sprintf(`{"organization_id": %q, "user_id": %q, "role": "owner"}`, org.ID, outsiderID))
+			},
+			applied: func() bool { return membership(outsiderID) },
+		},
+		{
+			name: "member adds an owner without an invite",
+			call: func() {
+				insert(member, "organization_users", fmt.Sprintf(`{"organization_id": %q, "user_id": %q, "role": "owner"}`, org.ID, outsiderID))
+			},
+			applied: func() bool { return membership(outsiderID) },
+		},
+		{
+			name:    "outsider creates a workspace",
+			call:    func() { _, _ = outsider.CreateOrganizationWorkspace(c, "rogue", "", "", org.ID) },
+			applied: func() bool { return row("workspaces", "name", "rogue") != nil },
+		},
+		{
+			name:    "outsider creates a package",
+			call:    func() { _, _ = outsider.CreateWorkspacePackage(c, "rogue", "", utils.Package, "", workspace.ID) },
+			applied: func() bool { return row("packages", "name", "rogue") != nil },
+		},
+		{
+			name:    "outsider creates a deploy token",
+			call:    func() { _, _, _ = outsider.CreateDeployToken(c, workspace.ID, "rogue", []string{"upload"}, "") },
+			applied: func() bool { return row("deploy_tokens", "name", "rogue") != nil },
+		},
+		{
+			name:    "member creates the organization bucket",
+			call:    func() { _, _ = member.CreateWorkspaceBucket(c, org.Name, true) },
+			applied: func() bool { return row("buckets", "name", org.Name) != nil },
+		},
+		{
+			name:    "member creates a package",
+			call:    func() { _, _ = member.CreateWorkspacePackage(c, "web", "", utils.Package, "", workspace.ID) },
+			applied: func() bool { return row("packages", "name", "web") != nil },
+			allowed: true,
+		},
+		{
+			name:    "owner creates the organization bucket",
+			call:    func() { _, _ = owner.CreateWorkspaceBucket(c, org.Name, true) },
+			applied: func() bool { return row("buckets", "name", org.Name) != nil },
+			allowed: true,
+		},
+		{
+			name:    "outsider updates a package",
+			call:    func() { _, _ = outsider.UpdateWorkspacePackageVersion(c, pkg.ID, "9.9.9") },
+			applied: func() bool { return version() == "9.9.9" },
+		},
+		{
+			name:    "outsider deletes a package",
+			call:    func() { _, _ = outsider.DeletePackageByID(c, pkg.ID) },
+			applied: func() bool { return row("packages", "id", pkg.ID) == nil },
+		},
+		{
+			name:    "outsider deletes the workspace",
+			call:    func() { _, _ = outsider.DeleteWorkspaceByID(c, workspace.ID) },
+			applied: func() bool { return row("workspaces", "id", workspace.ID) == nil },
+		},
+		{
+			name:    "member deletes the organization",
+			call:    func() { _, _ = member.DeleteOrganizationByID(c, org.ID) },
+			applied: func() bool { return row("organization", "id", org.ID) == nil },
+		},
+		{
+			name:    "member removes the owner",
+			call:    func() { _, _ = member.RemoveOrganizationMember(c, org.ID, ownerID) },
+			applied: func() bool { return !membership(ownerID) },
+		},
+		{
+			name:    "owner removes themselves",
+			call:    func() { _, _ = owner.RemoveOrganizationMember(c, org.ID, ownerID) },
+			applied: func() bool { return !membership(ownerID) },
+		},
+		{
+			name:    "member updates a package",
+			call:    func() { _, _ = member.UpdateWorkspacePackageVersion(c, pkg.ID, "1.1.0") },
+			applied: func() bool { return version() == "1.1.0" },
+			allowed: true,
+		},
+		{
+			name:    "owner removes a member",
+			call:    func() { _, _ = owner.RemoveOrganizationMember(c, org.ID, memberID) },
+			applied: func() bool { return !membership(memberID) },
+			allowed: true,
+		},
+		{
+			name:    "owner deletes the workspace",
+			call:    func() { _, _ = owner.DeleteWorkspaceByID(c, workspace.ID) },
+			applied: func() bool { return row("workspaces", "id", workspace.ID) == nil },
+			allowed: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.call()
+			if applied := test.applied(); applied != test.allowed {
+				t.Errorf("applied = %t, want %t", applied, test.allowed)
+			}
+		})
+	}
+}
@@ -0,0 +1,415 @@
+/*
+Copyright © 2022 Websublime.dev organization@websublime.dev
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package devserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+type filter struct {
+	Column   string
+	Operator string
+	Value    string
+}
+
+func (ctx *Server) handleRest(w http.ResponseWriter, r *http.Request) {
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+
+	caller, err := ctx.identify(r)
+	if err != nil {
+		writeError(w, http.StatusUnauthorized, "PGRST301", err.Error(), "")
+		return
+	}
+
+	table := strings.TrimPrefix(r.URL.Path, "/rest/v1/")
+	if strings.HasPrefix(table, "rpc/") {
+		ctx.handleRpc(w, r, caller, strings.TrimPrefix(table, "rpc/"))
+		return
+	}
+
+	if caller.User == nil && !caller.Service && caller.Deploy == nil {
+		writeError(w, http.StatusUnauthorized, "42501", fmt.Sprintf("permission denied for table %s", table), "Login first or use a valid token.")
+		return
+	}
+
+	userID := caller.userID()
+
+	query := r.URL.Query()
+	filters := parseFilters(query)
+	if caller.Deploy != nil {
+		scoped, ok := ctx.deployFilters(caller.Deploy, r.Method, table)
+		if !ok {
+			writeError(w, http.StatusForbidden, "42501", fmt.Sprintf("permission denied for table %s", table), "The deploy token does not grant this operation.")
+			return
+		}
+		filters = append(filters, scoped...)
+	}
+	representation := strings.Contains(r.Header.Get("Prefer"), "return=representation")
+
+	switch r.Method {
+	case http.MethodGet:
+		rows := []Row{}
+		for _, row := range ctx.state.Tables[table] {
+			if matches(row, filters) && ctx.readable(caller, table, row) {
+				rows = append(rows, ctx.project(row, query.Get("select")))
+			}
+		}
+		sortRows(rows, query.Get("order"))
+		writeJSON(w, http.StatusOK, rows)
+	case http.MethodPost:
+		payload, err := decodeRows(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "PGRST102", err.Error(), "")
+			return
+		}
+
+		// Like a single PostgREST transaction, one row failing the policies
+		// rejects the whole insert.
+		for _, row := range payload {
+			if !ctx.writable(caller, r.Method, table, row) {
+				writeError(w, http.StatusForbidden, "42501", fmt.Sprintf("new row violates row-level security policy for table \"%s\"", table), "")
+				return
+			}
+		}
+
+		created := []Row{}
+		for _, row := range payload {
+			if hint, ok := ctx.violatesUnique(table, row); ok {
+				writeError(w, http.StatusConflict, "23505", fmt.Sprintf("duplicate key value violates unique constraint \"%s_key\"", table), hint)
+				return
+			}
+			created = append(created, ctx.insert(table, row, userID))
+		}
+		ctx.persist()
+
+		if representation {
+			writeJSON(w, http.StatusCreated, created)
+		} else {
+			w.WriteHeader(http.StatusCreated)
+		}
+	case http.MethodPatch:
+		payload := Row{}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			writeError(w, http.StatusBadRequest, "PGRST102", err.Error(), "")
+			return
+		}
+
+		updated := []Row{}
+		for _, row := range ctx.state.Tables[table] {
+			if matches(row, filters) && ctx.writable(caller, r.Method, table, row) {
+				for key, value := range payload {
+					row[key] = value
+				}
+				updated = append(updated, copyRow(row))
+			}
+		}
+		ctx.persist()
+
+		if representation {
+			writeJSON(w, http.StatusOK, updated)
+		} else {
+			w.WriteHeader(http.StatusNoContent)
+		}
+	case http.MethodDelete:
+		kept := []Row{}
+		deleted := []Row{}
+		for _, row := range ctx.state.Tables[table] {
+			if matches(row, filters) && ctx.writable(caller, r.Method, table, row) {
+				deleted = append(deleted, row)
+			} else {
+				kept = append(kept, row)
+			}
+		}
+		ctx.state.Tables[table] = kept
+		ctx.persist()
+
+		if representation {
+			writeJSON(w, http.StatusOK, deleted)
+		} else {
+			w.WriteHeader(http.StatusNoContent)
+		}
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "PGRST117", "Unsupported HTTP method", "")
+	}
+}
+
+// readable emulates the row level security of selects: authors only read
+// rows of the organizations they belong to. Deploy sessions are already
+// scoped by deployFilters.
+func (ctx *Server) readable(caller *identity, table string, row Row) bool {
+	if caller.Service || caller.Deploy != nil {
+		return true
+	}
+
+	userID := caller.userID()
+	if userID == "" {
+		return false
+	}
+
+	switch table {
+	case "organization":
+		return ctx.organizationRole(fmt.Sprint(row["id"]), userID) != ""
+	case "organization_users", "workspaces":
+		return ctx.organizationRole(fmt.Sprint(row["organization_id"]), userID) != ""
+	case "packages", "deploy_tokens":
+		return ctx.workspaceMember(fmt.Sprint(row["workspace_id"]), userID)
+	}
+
+	return false
+}
+
+// writable emulates the row level security of inserts, updates and deletes.
+// Rows the caller can not change are left out, like on the cloud schema:
+// authors change rows of the organizations they belong to, and only owners
+// change the organization and its memberships. Organizations and memberships
+// are only inserted by the create_organization and invite_organization_member
+// rpcs. Owners can not remove themselves and an organization keeps an owner.
+// Deploy sessions are already scoped by deployFilters.
+func (ctx *Server) writable(caller *identity, method string, table string, row Row) bool {
+	if caller.Service || caller.Deploy != nil {
+		return true
+	}
+
+	userID := caller.userID()
+	if userID == "" {
+		return false
+	}
+
+	if method == http.MethodPost && (table == "organization" || table == "organization_users") {
+		return false
+	}
+
+	switch table {
+	case "organization":
+		return ctx.organizationRole(fmt.Sprint(row["id"]), userID) == "owner"
+	case "organization_users":
+		orgID := fmt.Sprint(row["organization_id"])
+		if ctx.organizationRole(orgID, userID) != "owner" {
+			return false
+		}
+		if method == http.MethodDelete {
+			return fmt.Sprint(row["user_id"]) != userID && (fmt.Sprint(row["role"]) != "owner" || ctx.organizationOwners(orgID) > 1)
+		}
+		return true
+	case "workspaces":
+		return ctx.organizationRole(fmt.Sprint(row["organization_id"]), userID) != ""
+	case "packages":
+		return ctx.workspaceMember(fmt.Sprint(row["workspace_id"]), userID)
+	case "deploy_tokens":
+		if createdBy, ok := row["created_by"]; method == http.MethodPost && ok && fmt.Sprint(createdBy) != userID {
+			return false
+		}
+		return ctx.workspaceMember(fmt.Sprint(row["workspace_id"]), userID)
+	}
+
+	return false
+}
+
+// violatesUnique emulates the unique constraints of the cloud schema.
+func (ctx *Server) violatesUnique(table string, row Row) (string, bool) {
+	keys := map[string][]string{
+		"organization":       {"name"},
+		"workspaces":         {"organization_id", "name"},
+		"packages":           {"workspace_id", "name"},
+		"organization_users": {"organization_id", "user_id"},
+	}
+
+	columns, ok := keys[table]
+	if !ok {
+		return "", false
+	}
+
+	for _, existing := range ctx.state.Tables[table] {
+		same := true
+		for _, column := range columns {
+			if fmt.Sprint(existing[column]) != fmt.Sprint(row[column]) {
+				same = false
+				break
+			}
+		}
+		if same {
+			return fmt.Sprintf("Key (%s) already exists.", strings.Join(columns, ", ")), true
+		}
+	}
+
+	return "", false
+}
+
+func decodeRows(r *http.Request) ([]Row, error) {
+	raw := json.RawMessage{}
+	if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+		return nil, err
+	}
+
+	if strings.HasPrefix(strings.TrimSpace(string(raw)), "[") {
+		rows := []Row{}
+		err := json.Unmarshal(raw, &rows)
+		return rows, err
+	}
+
+	row := Row{}
+	err := json.Unmarshal(raw, &row)
+
+	return []Row{row}, err
+}
+
+func parseFilters(query url.Values) []filter {
+	filters := []filter{}
+
+	for column, values := range query {
+		switch column {
+		case "select", "order", "limit", "offset", "on_conflict":
+			continue
+		}
+
+		for _, value := range values {
+			parts := strings.SplitN(value, ".", 2)
+			if len(parts) != 2 {
+				continue
+			}
+			filters = append(filters, filter{Column: column, Operator: parts[0], Value: parts[1]})
+		}
+	}
+
+	return filters
+}
+
+func matches(row Row, filters []filter) bool {
+	for _, f := range filters {
+		value := ""
+		if row[f.Column] != nil {
+			value = fmt.Sprint(row[f.Column])
+		}
+
+		switch f.Operator {
+		case "eq":
+			if value != f.Value {
+				return false
+			}
+		case "neq":
+			if value == f.Value {
+				return false
+			}
+		case "is":
+			if (f.Value == "null") != (row[f.Column] == nil) {
+				return false
+			}
+		case "in":
+			options := strings.Split(strings.Trim(f.Value, "()"), ",")
+			found := false
+			for _, option := range options {
+				if strings.Trim(option, `"`) == value {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// project applies a PostgREST select clause, including one level of
+// embedding through <relation>_id columns (ex: organization(id,name)).
+func (ctx *Server) project(row Row, selection string) Row {
+	if selection == "" || selection == "*" {
+		return copyRow(row)
+	}
+
+	projected := Row{}
+	for _, item := range splitSelect(selection) {
+		open := strings.Index(item, "(")
+		if open < 0 {
+			if item == "*" {
+				for key, value := range row {
+					projected[key] = value
+				}
+			} else if value, ok := row[item]; ok {
+				projected[item] = value
+			}
+			continue
+		}
+
+		relation := item[:open]
+		columns := strings.TrimSuffix(item[open+1:], ")")
+
+		var embedded Row
+		for _, candidate := range ctx.state.Tables[relation] {
+			if fmt.Sprint(candidate["id"]) == fmt.Sprint(row[relation+"_id"]) {
+				embedded = ctx.project(candidate, columns)
+				break
+			}
+		}
+		projected[relation] = embedded
+	}
+
+	return projected
+}
+
+func splitSelect(selection string) []string {
+	items := []string{}
+	depth := 0
+	start := 0
+
+	for idx, char := range selection {
+		switch char {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				items = append(items, strings.TrimSpace(selection[start:idx]))
+				start = idx + 1
+			}
+		}
+	}
+
+	return append(items, strings.TrimSpace(selection[start:]))
+}
+
+func sortRows(rows []Row, order string) {
+	if order == "" {
+		return
+	}
+
+	parts := strings.Split(order, ".")
+	column := parts[0]
+	descending := len(parts) > 1 && parts[1] == "desc"
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		left, right := fmt.Sprint(rows[i][column]), fmt.Sprint(rows[j][column])
+		if descending {
+			return left > right
+		}
+		return left < right
+	})
+}
@@ -0,0 +1,305 @@
+/*
+Copyright © 2022 Websublime.dev organization@websublime.dev
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package devserver
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+func (ctx *Server) handleRpc(w http.ResponseWriter, r *http.Request, caller *identity, name string) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "PGRST117", "Unsupported HTTP method", "")
+		return
+	}
+
+	switch name {
+	case "exchange_deploy_token":
+		ctx.exchangeDeployToken(w, r)
+	case "create_organization":
+		ctx.createOrganization(w, r, caller)
+	case "get_organization_members":
+		ctx.organizationMembers(w, r, caller)
+	case "invite_organization_member":
+		ctx.inviteOrganizationMember(w, r, caller)
+	default:
+		writeError(w, http.StatusNotFound, "PGRST202", fmt.Sprintf("Could not find the function public.%s in the schema cache", name), "")
+	}
+}
+
+// exchangeDeployToken mirrors public.exchange_deploy_token: a valid token is
+// traded for a short lived session bound to the token workspace and scopes.
+// Like the deploy role of the cloud jwt, the session carries no author.
+func (ctx *Server) exchangeDeployToken(w http.ResponseWriter, r *http.Request) {
+	payload := map[string]string{}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeError(w, http.StatusBadRequest, "PGRST102", err.Error(), "")
+		return
+	}
+
+	sum := sha256.Sum256([]byte(payload["token"]))
+	hash := hex.EncodeToString(sum[:])
+
+	var token Row
+	for _, row := range ctx.state.Tables["deploy_tokens"] {
+		if row["token_hash"] == hash && row["revoked_at"] == nil {
+			token = row
+			break
+		}
+	}
+
+	if token == nil || isExpired(token["expires_at"]) {
+		writeError(w, http.StatusBadRequest, "28000", "invalid or expired deploy token", "")
+		return
+	}
+
+	scopes := []string{}
+	if values, ok := token["scopes"].([]interface{}); ok {
+		for _, value := range values {
+			scopes = append(scopes, fmt.Sprint(value))
+		}
+	}
+
+	ttl := 15 * time.Minute
+	session := &DeploySession{
+		TokenID:     fmt.Sprint(token["id"]),
+		WorkspaceID: fmt.Sprint(token["workspace_id"]),
+		Scopes:      scopes,
+		Expires:     time.Now().Add(ttl).Unix(),
+	}
+
+	access := fmt.Sprintf("deploy.%s", newSecret())
+	ctx.state.Deploy[access] = session
+	token["last_used_at"] = time.Now().UTC().Format(time.RFC3339)
+	ctx.persist()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": access,
+		"expires_in":   int64(ttl.Seconds()),
+		"workspace_id": session.WorkspaceID,
+		"scopes":       session.Scopes,
+	})
+}
+
+func (ctx *Server) organizationRole(orgID string, userID string) string {
+	for _, row := range ctx.state.Tables["organization_users"] {
+		if fmt.Sprint(row["organization_id"]) == orgID && fmt.Sprint(row["user_id"]) == userID {
+			return fmt.Sprint(row["role"])
+		}
+	}
+
+	return ""
+}
+
+func (ctx *Server) organizationOwners(orgID string) int {
+	owners := 0
+	for _, row := range ctx.state.Tables["organization_users"] {
+		if fmt.Sprint(row["organization_id"]) == orgID && fmt.Sprint(row["role"]) == "owner" {
+			owners++
+		}
+	}
+
+	return owners
+}
+
+func (ctx *Server) ownsOrganization(name string, userID string) bool {
+	for _, org := range ctx.state.Tables["organization"] {
+		if fmt.Sprint(org["name"]) == name {
+			return ctx.organizationRole(fmt.Sprint(org["id"]), userID) == "owner"
+		}
+	}
+
+	return false
+}
+
+// workspaceMember mirrors public.is_workspace_member.
+func (ctx *Server) workspaceMember(workspaceID string, userID string) bool {
+	for _, workspace := range ctx.state.Tables["workspaces"] {
+		if fmt.Sprint(workspace["id"]) == workspaceID {
+			return ctx.organizationRole(fmt.Sprint(workspace["organization_id"]), userID) != ""
+		}
+	}
+
+	return false
+}
+
+func memberPayload(user *User, membership Row) Row {
+	return Row{
+		"user_id":    user.ID,
+		"email":      user.Email,
+		"name":       user.Metadata["name"],
+		"username":   user.Metadata["author"],
+		"role":       membership["role"],
+		"created_at": membership["created_at"],
+	}
+}
+
+// createOrganization mirrors public.create_organization: the organization
+// and its owner membership are created together.
+func (ctx *Server) createOrganization(w http.ResponseWriter, r *http.Request, caller *identity) {
+	payload := map[string]string{}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeError(w, http.StatusBadRequest, "PGRST102", err.Error(), "")
+		return
+	}
+
+	userID := caller.userID()
+	if userID == "" {
+		writeError(w, http.StatusUnauthorized, "42501", "login required", "")
+		return
+	}
+
+	org := Row{"name": payload["name"]}
+	if hint, ok := ctx.violatesUnique("organization", org); ok {
+		writeError(w, http.StatusConflict, "23505", "duplicate key value violates unique constraint \"organization_key\"", hint)
+		return
+	}
+
+	created := ctx.insert("organization", org, userID)
+	ctx.insert("organization_users", Row{"organization_id": created["id"], "user_id": userID, "role": "owner"}, userID)
+	ctx.persist()
+
+	writeJSON(w, http.StatusOK, created)
+}
+
+// organizationMembers mirrors public.get_organization_members.
+func (ctx *Server) organizationMembers(w http.ResponseWriter, r *http.Request, caller *identity) {
+	payload := map[string]string{}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeError(w, http.StatusBadRequest, "PGRST102", err.Error(), "")
+		return
+	}
+
+	orgID := payload["organization_id"]
+	if !caller.Service && ctx.organizationRole(orgID, caller.userID()) == "" {
+		writeError(w, http.StatusForbidden, "42501", fmt.Sprintf("not a member of organization %s", orgID), "")
+		return
+	}
+
+	members := []Row{}
+	for _, row := range ctx.state.Tables["organization_users"] {
+		if fmt.Sprint(row["organization_id"]) != orgID {
+			continue
+		}
+
+		userID := fmt.Sprint(row["user_id"])
+		if user := ctx.findUser(func(user *User) bool { return user.ID == userID }); user != nil {
+			members = append(members, memberPayload(user, row))
+		}
+	}
+
+	writeJSON(w, http.StatusOK, members)
+}
+
+// inviteOrganizationMember mirrors public.invite_organization_member.
+func (ctx *Server) inviteOrganizationMember(w http.ResponseWriter, r *http.Request, caller *identity) {
+	payload := map[string]string{}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeError(w, http.StatusBadRequest, "PGRST102", err.Error(), "")
+		return
+	}
+
+	orgID := payload["organization_id"]
+	if !caller.Service && ctx.organizationRole(orgID, caller.userID()) != "owner" {
+		writeError(w, http.StatusForbidden, "42501", "only owners can invite members", "")
+		return
+	}
+
+	role := payload["role"]
+	if role == "" {
+		role = "member"
+	}
+	if role != "owner" && role != "member" {
+		writeError(w, http.StatusBadRequest, "22023", fmt.Sprintf("invalid role %s", role), "")
+		return
+	}
+
+	invited := ctx.findUser(func(user *User) bool { return strings.EqualFold(user.Email, payload["email"]) })
+	if invited == nil {
+		writeError(w, http.StatusBadRequest, "P0002", fmt.Sprintf("no author registered with %s", payload["email"]), "Ask them to run \"sublime register\" first.")
+		return
+	}
+
+	membership := Row{"organization_id": orgID, "user_id": invited.ID, "role": role}
+	if hint, ok := ctx.violatesUnique("organization_users", membership); ok {
+		writeError(w, http.StatusConflict, "23505", "duplicate key value violates unique constraint \"organization_users_key\"", hint)
+		return
+	}
+
+	created := ctx.insert("organization_users", membership, caller.userID())
+	ctx.persist()
+
+	writeJSON(w, http.StatusOK, memberPayload(invited, created))
+}
+
+// deployFilters restricts what a deploy session can reach on rest/v1, the
+// same way the deploy token policies do on the cloud schema.
+func (ctx *Server) deployFilters(session *DeploySession, method string, table string) ([]filter, bool) {
+	switch {
+	case table == "packages" && (method == http.MethodGet || (method == http.MethodPatch && session.hasScope("version"))):
+		return []filter{{Column: "workspace_id", Operator: "eq", Value: session.WorkspaceID}}, true
+	case table == "workspaces" && method == http.MethodGet:
+		return []filter{{Column: "id", Operator: "eq", Value: session.WorkspaceID}}, true
+	}
+
+	return nil, false
+}
+
+// deployBucket is the organization bucket of the session workspace, the
+// only bucket a deploy session with the upload scope can write to.
+func (ctx *Server) deployBucket(session *DeploySession) string {
+	if !session.hasScope("upload") {
+		return ""
+	}
+
+	for _, workspace := range ctx.state.Tables["workspaces"] {
+		if fmt.Sprint(workspace["id"]) != session.WorkspaceID {
+			continue
+		}
+		for _, org := range ctx.state.Tables["organization"] {
+			if fmt.Sprint(org["id"]) == fmt.Sprint(workspace["organization_id"]) {
+				return fmt.Sprint(org["name"])
+			}
+		}
+	}
+
+	return ""
+}
+
+func isExpired(value interface{}) bool {
+	text, ok := value.(string)
+	if !ok || strings.TrimSpace(text) == "" {
+		return false
+	}
+
+	expires, err := time.Parse(time.RFC3339, text)
+	if err != nil {
+		return false
+	}
+
+	return time.Now().After(expires)
+}
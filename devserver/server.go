@@ -0,0 +1,351 @@
+/*
+Copyright © 2022 Websublime.dev organization@websublime.dev
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package devserver
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultApiKey     = "sublime-dev-anon-key"
+	DefaultServiceKey = "sublime-dev-service-key"
+)
+
+type User struct {
+	ID        string            `json:"id"`
+	Email     string            `json:"email"`
+	Password  string            `json:"password"`
+	Metadata  map[string]string `json:"user_metadata"`
+	CreatedAt string            `json:"created_at"`
+}
+
+type Row map[string]interface{}
+
+// DeploySession is issued when a deploy token is exchanged. It is bound to
+// a single workspace and only grants the token scopes.
+type DeploySession struct {
+	TokenID     string   `json:"token_id"`
+	WorkspaceID string   `json:"workspace_id"`
+	Scopes      []string `json:"scopes"`
+	Expires     int64    `json:"expires"`
+}
+
+func (ctx *DeploySession) hasScope(scope string) bool {
+	for _, value := range ctx.Scopes {
+		if value == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+type State struct {
+	Users    []*User                   `json:"users"`
+	Sessions map[string]string         `json:"sessions"`
+	Refresh  map[string]string         `json:"refresh"`
+	Deploy   map[string]*DeploySession `json:"deploy"`
+	Tables   map[string][]Row          `json:"tables"`
+}
+
+// identity is the caller of a request: an author, the service role or a
+// deploy session. All fields empty means anonymous.
+type identity struct {
+	User    *User
+	Service bool
+	Deploy  *DeploySession
+}
+
+func (ctx *identity) userID() string {
+	if ctx.User != nil {
+		return ctx.User.ID
+	}
+
+	return ""
+}
+
+// Server is an in memory (optionally disk backed) implementation of the
+// auth/v1, rest/v1 and storage/v1 endpoints used by the CLI.
+type Server struct {
+	ApiKey       string
+	ServiceKey   string
+	DataDir      string
+	Organization string
+	TokenTTL     time.Duration
+
+	mu      sync.Mutex
+	state   *State
+	objects map[string][]byte
+	mux     *http.ServeMux
+}
+
+func NewServer() *Server {
+	server := &Server{
+		ApiKey:     DefaultApiKey,
+		ServiceKey: DefaultServiceKey,
+		TokenTTL:   time.Hour,
+		state: &State{
+			Users:    []*User{},
+			Sessions: map[string]string{},
+			Refresh:  map[string]string{},
+			Deploy:   map[string]*DeploySession{},
+			Tables:   map[string][]Row{},
+		},
+		objects: map[string][]byte{},
+		mux:     http.NewServeMux(),
+	}
+
+	server.mux.HandleFunc("/auth/v1/", server.handleAuth)
+	server.mux.HandleFunc("/rest/v1/", server.handleRest)
+	server.mux.HandleFunc("/storage/v1/", server.handleStorage)
+
+	return server
+}
+
+// Load restores the state persisted on DataDir (if any).
+func (ctx *Server) Load() error {
+	if ctx.DataDir == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(filepath.Join(ctx.DataDir, "state.json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+
+	return json.Unmarshal(data, ctx.state)
+}
+
+// Seed inserts rows from a json file shaped as {"table": [rows...]}.
+func (ctx *Server) Seed(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	tables := map[string][]Row{}
+	if err := json.Unmarshal(data, &tables); err != nil {
+		return err
+	}
+
+	for table, rows := range tables {
+		for _, row := range rows {
+			ctx.Insert(table, row)
+		}
+	}
+
+	return nil
+}
+
+// Insert adds a row to a table, filling id and created_at when missing.
+func (ctx *Server) Insert(table string, row Row) Row {
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+
+	return ctx.insert(table, row, "")
+}
+
+// Rows returns a copy of the rows of a table.
+func (ctx *Server) Rows(table string) []Row {
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+
+	rows := []Row{}
+	for _, row := range ctx.state.Tables[table] {
+		rows = append(rows, copyRow(row))
+	}
+
+	return rows
+}
+
+// Object returns the content of an uploaded object (bucket/key).
+func (ctx *Server) Object(key string) ([]byte, bool) {
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+
+	data, ok := ctx.objects[key]
+	if !ok && ctx.DataDir != "" {
+		content, err := os.ReadFile(filepath.Join(ctx.DataDir, "storage", filepath.FromSlash(key)))
+		if err == nil {
+			return content, true
+		}
+	}
+
+	return data, ok
+}
+
+// AddOrganization creates an organization and makes every given user id a member.
+func (ctx *Server) AddOrganization(name string, userIDs ...string) Row {
+	org := ctx.Insert("organization", Row{"name": name})
+
+	for _, id := range userIDs {
+		ctx.Insert("organization_users", Row{"organization_id": org["id"], "user_id": id, "role": "owner"})
+	}
+
+	return org
+}
+
+func (ctx *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx.mux.ServeHTTP(w, r)
+}
+
+// ListenAndServe starts the server on addr and blocks.
+func (ctx *Server) ListenAndServe(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	return http.Serve(listener, ctx)
+}
+
+func (ctx *Server) insert(table string, row Row, userID string) Row {
+	created := copyRow(row)
+
+	if _, ok := created["id"]; !ok {
+		created["id"] = newID()
+	}
+	if _, ok := created["created_at"]; !ok {
+		created["created_at"] = time.Now().UTC().Format(time.RFC3339)
+	}
+	if _, ok := created["created_by"]; !ok && userID != "" {
+		created["created_by"] = userID
+	}
+
+	ctx.state.Tables[table] = append(ctx.state.Tables[table], created)
+
+	return copyRow(created)
+}
+
+func (ctx *Server) persist() {
+	if ctx.DataDir == "" {
+		return
+	}
+
+	data, err := json.MarshalIndent(ctx.state, "", " ")
+	if err != nil {
+		return
+	}
+
+	_ = os.MkdirAll(ctx.DataDir, 0755)
+	_ = os.WriteFile(filepath.Join(ctx.DataDir, "state.json"), data, 0644)
+}
+
+func (ctx *Server) identify(r *http.Request) (*identity, error) {
+	apiKey := r.Header.Get("apikey")
+	if apiKey != ctx.ApiKey && apiKey != ctx.ServiceKey {
+		return nil, errors.New("Invalid API key")
+	}
+
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if token == ctx.ServiceKey {
+		return &identity{Service: true}, nil
+	}
+	if token == ctx.ApiKey || token == "" {
+		return &identity{}, nil
+	}
+
+	if session, ok := ctx.state.Deploy[token]; ok {
+		if time.Now().Unix() > session.Expires {
+			return nil, errors.New("JWT expired")
+		}
+		return &identity{Deploy: session}, nil
+	}
+
+	userID, ok := ctx.state.Sessions[token]
+	if !ok {
+		return nil, errors.New("invalid JWT: unable to parse or verify signature")
+	}
+
+	return &identity{User: ctx.findUser(func(user *User) bool { return user.ID == userID })}, nil
+}
+
+func (ctx *Server) findUser(match func(*User) bool) *User {
+	for _, user := range ctx.state.Users {
+		if match(user) {
+			return user
+		}
+	}
+
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+
+	if value != nil {
+		_ = json.NewEncoder(w).Encode(value)
+	}
+}
+
+// writeError replies with a PostgREST shaped error body.
+func writeError(w http.ResponseWriter, status int, code string, message string, hint string) {
+	writeJSON(w, status, map[string]string{
+		"code":    code,
+		"message": message,
+		"details": "",
+		"hint":    hint,
+	})
+}
+
+func newID() string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	buf[6] = (buf[6] & 0x0f) | 0x40
+	buf[8] = (buf[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", buf[0:4], buf[4:6], buf[6:8], buf[8:10], buf[10:16])
+}
+
+func newSecret() string {
+	buf := make([]byte, 24)
+	_, _ = rand.Read(buf)
+
+	return hex.EncodeToString(buf)
+}
+
+func copyRow(row Row) Row {
+	created := Row{}
+	for key, value := range row {
+		created[key] = value
+	}
+
+	return created
+}
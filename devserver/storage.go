@@ -0,0 +1,170 @@
+/*
+Copyright © 2022 Websublime.dev organization@websublime.dev
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package devserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+func (ctx *Server) handleStorage(w http.ResponseWriter, r *http.Request) {
+	route := strings.TrimPrefix(r.URL.Path, "/storage/v1/")
+
+	if r.Method == http.MethodGet && strings.HasPrefix(route, "object/public/") {
+		ctx.download(w, strings.TrimPrefix(route, "object/public/"))
+		return
+	}
+
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+
+	caller, err := ctx.identify(r)
+	if err != nil || (caller.User == nil && !caller.Service && caller.Deploy == nil) {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"statusCode": "401", "error": "Unauthorized", "message": "Invalid JWT"})
+		return
+	}
+
+	if caller.Deploy != nil {
+		bucket := strings.SplitN(strings.TrimPrefix(route, "object/"), "/", 2)[0]
+		if r.Method != http.MethodPost || !strings.HasPrefix(route, "object/") || bucket != ctx.deployBucket(caller.Deploy) {
+			writeJSON(w, http.StatusForbidden, map[string]string{"statusCode": "403", "error": "Unauthorized", "message": "new row violates row-level security policy"})
+			return
+		}
+	}
+
+	switch {
+	case route == "bucket" && r.Method == http.MethodPost:
+		ctx.createBucket(w, r, caller)
+	case route == "bucket" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, ctx.state.Tables["buckets"])
+	case strings.HasPrefix(route, "object/") && r.Method == http.MethodPost:
+		ctx.upload(w, r, strings.TrimPrefix(route, "object/"))
+	default:
+		writeJSON(w, http.StatusNotFound, map[string]string{"statusCode": "404", "error": "Not Found", "message": "Route not found"})
+	}
+}
+
+// createBucket mirrors the cloud bucket policy: authors only create the
+// bucket named after an organization they own.
+func (ctx *Server) createBucket(w http.ResponseWriter, r *http.Request, caller *identity) {
+	bucket := Row{}
+	if err := json.NewDecoder(r.Body).Decode(&bucket); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"statusCode": "400", "error": "Bad Request", "message": err.Error()})
+		return
+	}
+
+	if !caller.Service && !ctx.ownsOrganization(fmt.Sprint(bucket["name"]), caller.userID()) {
+		writeJSON(w, http.StatusForbidden, map[string]string{"statusCode": "403", "error": "Unauthorized", "message": "new row violates row-level security policy"})
+		return
+	}
+
+	for _, existing := range ctx.state.Tables["buckets"] {
+		if existing["id"] == bucket["id"] {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"statusCode": "409", "error": "Duplicate", "message": "The resource already exists"})
+			return
+		}
+	}
+
+	ctx.insert("buckets", bucket, "")
+	ctx.persist()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"name": bucket["name"]})
+}
+
+func (ctx *Server) upload(w http.ResponseWriter, r *http.Request, key string) {
+	bucket := strings.SplitN(key, "/", 2)[0]
+
+	found := false
+	for _, existing := range ctx.state.Tables["buckets"] {
+		if existing["id"] == bucket || existing["name"] == bucket {
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		writeJSON(w, http.StatusNotFound, map[string]string{"statusCode": "404", "error": "Bucket not found", "message": "Bucket not found"})
+		return
+	}
+
+	_, exists := ctx.objects[key]
+	if exists && r.Header.Get("x-upsert") != "true" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"statusCode": "409", "error": "Duplicate", "message": "The resource already exists"})
+		return
+	}
+
+	content, err := readUpload(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"statusCode": "400", "error": "Bad Request", "message": err.Error()})
+		return
+	}
+
+	ctx.objects[key] = content
+
+	if ctx.DataDir != "" {
+		target := filepath.Join(ctx.DataDir, "storage", filepath.FromSlash(key))
+		_ = os.MkdirAll(filepath.Dir(target), 0755)
+		_ = os.WriteFile(target, content, 0644)
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"Key": key})
+}
+
+func (ctx *Server) download(w http.ResponseWriter, key string) {
+	content, ok := ctx.Object(key)
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"statusCode": "404", "error": "not_found", "message": "Object not found"})
+		return
+	}
+
+	if kind := mime.TypeByExtension(path.Ext(key)); kind != "" {
+		w.Header().Set("Content-Type", kind)
+	}
+
+	_, _ = w.Write(content)
+}
+
+func readUpload(r *http.Request) ([]byte, error) {
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/") {
+		return io.ReadAll(r.Body)
+	}
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+
+	part, err := reader.NextPart()
+	if err != nil {
+		return nil, fmt.Errorf("missing file part: %w", err)
+	}
+	defer part.Close()
+
+	return io.ReadAll(part)
+}
@@ -0,0 +1,69 @@
+/*
+Copyright © 2022 Websublime.dev organization@websublime.dev
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package models
+
+import "github.com/websublime/sublime-cli/utils"
+
+// Changeset is a .changeset/<id>.md file: the bumps of the released packages
+// on the front matter and the summary for the changelogs.
+type Changeset struct {
+	ID       string             `json:"id"`
+	Summary  string             `json:"summary"`
+	Releases []ChangesetRelease `json:"releases"`
+}
+
+type ChangesetRelease struct {
+	Name string         `json:"name"`
+	Type utils.BumpType `json:"type"`
+}
+
+// ChangesetConfig holds the .changeset/config.json fields used to version
+// the packages.
+type ChangesetConfig struct {
+	BaseBranch                 string         `json:"baseBranch"`
+	UpdateInternalDependencies utils.BumpType `json:"updateInternalDependencies"`
+	Ignore                     []string       `json:"ignore"`
+}
+
+// VersionBump is the next version of a package. Dependencies are the bumped
+// workspace packages it depends on, which bump it with a patch, or a major
+// for minor and major bumps of peers, when it has no changeset.
+type VersionBump struct {
+	Name         string         `json:"name"`
+	Path         string         `json:"path"`
+	Version      string         `json:"version"`
+	NewVersion   string         `json:"newVersion"`
+	Type         utils.BumpType `json:"type"`
+	Changesets   []string       `json:"changesets"`
+	Dependencies []string       `json:"dependencies,omitempty"`
+}
+
+// ReleaseCommit is a conventional commit changing a package since its last
+// tag (Since, empty when never tagged).
+type ReleaseCommit struct {
+	Hash        string         `json:"hash"`
+	Package     string         `json:"package"`
+	Since       string         `json:"since,omitempty"`
+	Type        string         `json:"type"`
+	Bump        utils.BumpType `json:"bump"`
+	Description string         `json:"description"`
+}
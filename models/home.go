@@ -0,0 +1,40 @@
+/*
+Copyright © 2022 Websublime.dev organization@websublime.dev
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package models
+
+type HomeProfile struct {
+	ApiUrl       string `json:"apiUrl,omitempty"`
+	ApiKey       string `json:"apiKey,omitempty"`
+	Organization string `json:"organization,omitempty"`
+}
+
+type HomeConfig struct {
+	Profile      string                 `json:"profile,omitempty"`
+	ApiUrl       string                 `json:"apiUrl,omitempty"`
+	ApiKey       string                 `json:"apiKey,omitempty"`
+	Organization string                 `json:"organization,omitempty"`
+	Profiles     map[string]HomeProfile `json:"profiles,omitempty"`
+	Templates    []TemplateSource       `json:"templates,omitempty"`
+	// TrustedPlugins are plugins outside of ~/.sublime/plugins that get
+	// the token of the author.
+	TrustedPlugins []string `json:"trustedPlugins,omitempty"`
+}
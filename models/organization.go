@@ -22,15 +22,38 @@ THE SOFTWARE.
 package models
 
 type Organization struct {
-	ID        string `json:"id"`
+	ID        string `json:"id,omitempty"`
 	Name      string `json:"name"`
-	CreatedAt string `json:"created_at"`
+	CreatedAt string `json:"created_at,omitempty"`
 }
 
 type OrganizationByUserResponse struct {
+	Role         string       `json:"role"`
 	Organization Organization `json:"organization"`
 }
 
+type OrganizationUser struct {
+	OrganizationID string `json:"organization_id"`
+	UserID         string `json:"user_id"`
+	Role           string `json:"role"`
+	CreatedAt      string `json:"created_at,omitempty"`
+}
+
+type OrganizationMember struct {
+	UserID    string `json:"user_id"`
+	Email     string `json:"email"`
+	Name      string `json:"name"`
+	Username  string `json:"username"`
+	Role      string `json:"role"`
+	CreatedAt string `json:"created_at,omitempty"`
+}
+
+type OrganizationInvite struct {
+	OrganizationID string `json:"organization_id"`
+	Email          string `json:"email,omitempty"`
+	Role           string `json:"role,omitempty"`
+}
+
 func NewOrganization(name string) *Organization {
 	return &Organization{
 		Name: name,
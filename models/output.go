@@ -0,0 +1,199 @@
+/*
+Copyright © 2022 Websublime.dev organization@websublime.dev
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package models
+
+import "github.com/websublime/sublime-cli/utils"
+
+// Result objects of the commands, printed with --output json or yaml.
+
+type VersionResult struct {
+	Version   string `json:"version"`
+	BuildTime string `json:"build_time"`
+}
+
+type StatusPackage struct {
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	Type        utils.PackageType `json:"type"`
+	Version     string            `json:"version"`
+	Description string            `json:"description"`
+}
+
+type StatusResult struct {
+	Name         string          `json:"name"`
+	Namespace    string          `json:"namespace"`
+	ID           string          `json:"id"`
+	Repo         string          `json:"repo"`
+	Organization string          `json:"organization"`
+	Docs         string          `json:"docs"`
+	Endpoint     string          `json:"endpoint"`
+	Packages     []StatusPackage `json:"packages"`
+}
+
+type DeployTokenCreateResult struct {
+	Token  DeployToken `json:"token"`
+	Secret string      `json:"secret"`
+}
+
+type DeployTokenResult struct {
+	DeployToken
+	Status string `json:"status"`
+}
+
+type OrganizationResult struct {
+	Organization
+	Role     string `json:"role"`
+	Selected bool   `json:"selected"`
+}
+
+type OrganizationCreateResult struct {
+	Organization Organization `json:"organization"`
+	Bucket       string       `json:"bucket"`
+}
+
+type WorkspaceCreateResult struct {
+	Workspace Workspace `json:"workspace"`
+	Dir       string    `json:"dir"`
+}
+
+type WorkspaceResult struct {
+	WorkspacesByOrganizationResponse
+	Selected bool `json:"selected"`
+}
+
+// WorkspacePackageResult is a cloud package. Local is set when the workspace
+// is the one on .sublime.json, reporting if the package is tracked there.
+type WorkspacePackageResult struct {
+	Package
+	Local *bool `json:"local,omitempty"`
+}
+
+type WorkspaceShowResult struct {
+	Workspace WorkspacesByOrganizationResponse `json:"workspace"`
+	Packages  []WorkspacePackageResult         `json:"packages"`
+}
+
+type SyncResult struct {
+	Workspace string         `json:"workspace"`
+	InSync    bool           `json:"in_sync"`
+	DryRun    bool           `json:"dry_run"`
+	Applied   bool           `json:"applied"`
+	Drift     []PackageDrift `json:"drift"`
+	Actions   []SyncAction   `json:"actions,omitempty"`
+	Conflicts []PackageDrift `json:"conflicts,omitempty"`
+}
+
+type FileResult struct {
+	Path   string           `json:"path"`
+	Status utils.FileStatus `json:"status"`
+}
+
+type InitResult struct {
+	Workspace SublimeJsonFileProps `json:"workspace"`
+	Files     []FileResult         `json:"files"`
+	Packages  []DetectedPackage    `json:"packages"`
+}
+
+type ConfigResult struct {
+	Path          string            `json:"path"`
+	SchemaVersion int               `json:"schema_version"`
+	Migrations    []int             `json:"migrations"`
+	Violations    []SchemaViolation `json:"violations"`
+}
+
+type AuthorResult struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Expires  int64  `json:"expires,omitempty"`
+	File     string `json:"file"`
+}
+
+type PackageCreateResult struct {
+	Package Package `json:"package"`
+	Path    string  `json:"path"`
+}
+
+type ActionArtifact struct {
+	Name     string `json:"name"`
+	Version  string `json:"version"`
+	Folder   string `json:"folder"`
+	Files    int    `json:"files"`
+	Manifest string `json:"manifest"`
+}
+
+type PackageVersionResult struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type ActionResult struct {
+	Type      string                 `json:"type"`
+	Tags      []string               `json:"tags,omitempty"`
+	Artifacts []ActionArtifact       `json:"artifacts"`
+	Versions  []PackageVersionResult `json:"versions,omitempty"`
+}
+
+type ChangesetAddResult struct {
+	Changeset Changeset     `json:"changeset"`
+	File      string        `json:"file"`
+	Bumps     []VersionBump `json:"bumps"`
+}
+
+type ChangesetStatusResult struct {
+	Changesets []Changeset   `json:"changesets"`
+	Bumps      []VersionBump `json:"bumps"`
+	Unreleased []string      `json:"unreleased,omitempty"`
+}
+
+type ChangesetVersionResult struct {
+	DryRun bool          `json:"dryRun"`
+	Bumps  []VersionBump `json:"bumps"`
+	Files  []string      `json:"files"`
+}
+
+type ReleasePlanResult struct {
+	Commits []ReleaseCommit `json:"commits"`
+	Bumps   []VersionBump   `json:"bumps"`
+}
+
+type ReleaseApplyResult struct {
+	Bumps    []VersionBump          `json:"bumps"`
+	Files    []string               `json:"files"`
+	Commit   string                 `json:"commit,omitempty"`
+	Tags     []string               `json:"tags"`
+	Versions []PackageVersionResult `json:"versions,omitempty"`
+}
+
+type UpgradeFile struct {
+	Path     string           `json:"path"`
+	Template string           `json:"template"`
+	Status   utils.FileStatus `json:"status"`
+	Diff     string           `json:"diff,omitempty"`
+}
+
+type UpgradeResult struct {
+	Version string        `json:"version"`
+	DryRun  bool          `json:"dry_run"`
+	Files   []UpgradeFile `json:"files"`
+}
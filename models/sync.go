@@ -0,0 +1,44 @@
+/*
+Copyright © 2022 Websublime.dev organization@websublime.dev
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package models
+
+import "github.com/websublime/sublime-cli/utils"
+
+// PackageDrift is a difference between a package on .sublime.json (and its
+// package.json) and the packages row on the cloud.
+type PackageDrift struct {
+	Kind    utils.DriftKind `json:"kind"`
+	Package string          `json:"package"`
+	ID      string          `json:"id,omitempty"`
+	Field   string          `json:"field,omitempty"`
+	Local   string          `json:"local,omitempty"`
+	Cloud   string          `json:"cloud,omitempty"`
+	Reason  string          `json:"reason,omitempty"`
+}
+
+// SyncAction is one step of the plan applied by "sublime sync".
+type SyncAction struct {
+	Action  utils.SyncActionKind `json:"action"`
+	Package string               `json:"package"`
+	ID      string               `json:"id,omitempty"`
+	Detail  string               `json:"detail,omitempty"`
+}
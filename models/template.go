@@ -0,0 +1,93 @@
+/*
+Copyright © 2022 Websublime.dev organization@websublime.dev
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package models
+
+// TemplateSource declares where the files of a template come from. Source
+// is a local directory, an https (or ssh) git url, a .tar.gz url or file, or
+// an npm package as npm:<name>[@version].
+type TemplateSource struct {
+	Name     string `json:"name"`
+	Source   string `json:"source"`
+	Ref      string `json:"ref,omitempty"`
+	Registry string `json:"registry,omitempty"`
+	TokenEnv string `json:"tokenEnv,omitempty"`
+	// Base is the directory relative local sources resolve against, the one
+	// of the file declaring the source.
+	Base string `json:"-"`
+	// Origin is where the source was declared (.sublime.json, home or builtin).
+	Origin string `json:"-"`
+}
+
+// TemplateManifest is the sublime-template.json of a template. It declares
+// the prompts and variables of the template, the files rendered with them
+// and the hooks run once the package is created.
+type TemplateManifest struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Delimiters  []string               `json:"delimiters,omitempty"`
+	Prompts     []TemplatePrompt       `json:"prompts,omitempty"`
+	Variables   map[string]string      `json:"variables,omitempty"`
+	Files       []TemplateManifestFile `json:"files,omitempty"`
+	Exclude     []string               `json:"exclude,omitempty"`
+	Hooks       TemplateHooks          `json:"hooks"`
+}
+
+// TemplatePrompt asks the value of the variable Name. Type is input, select
+// (one of Choices) or confirm (true or false).
+type TemplatePrompt struct {
+	Name     string   `json:"name"`
+	Message  string   `json:"message"`
+	Type     string   `json:"type,omitempty"`
+	Default  string   `json:"default,omitempty"`
+	Choices  []string `json:"choices,omitempty"`
+	Required bool     `json:"required,omitempty"`
+}
+
+// TemplateManifestFile is rendered from Source to Target (both relative to
+// the package dir, Target defaults to Source). Files are only created when
+// When renders to a truthy value.
+type TemplateManifestFile struct {
+	Source     string   `json:"source"`
+	Target     string   `json:"target,omitempty"`
+	Delimiters []string `json:"delimiters,omitempty"`
+	When       string   `json:"when,omitempty"`
+}
+
+type TemplateHooks struct {
+	PostCreate []string `json:"postCreate,omitempty"`
+}
+
+// GeneratedFile is a workspace file rendered from an embedded template. Hash
+// is the sha256 of the rendered content, the base "sublime upgrade" merges
+// newer renders against.
+type GeneratedFile struct {
+	Path       string            `json:"path"`
+	Template   string            `json:"template"`
+	Delimiters []string          `json:"delimiters"`
+	Variables  map[string]string `json:"variables"`
+	Hash       string            `json:"hash,omitempty"`
+	Version    string            `json:"version,omitempty"`
+}
+
+type GeneratedFiles struct {
+	Files []GeneratedFile `json:"files"`
+}
@@ -0,0 +1,68 @@
+/*
+Copyright © 2022 Websublime.dev organization@websublime.dev
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package models
+
+type DeployToken struct {
+	ID          string   `json:"id,omitempty"`
+	WorkspaceID string   `json:"workspace_id,omitempty"`
+	Name        string   `json:"name,omitempty"`
+	Scopes      []string `json:"scopes,omitempty"`
+	TokenHash   string   `json:"token_hash,omitempty"`
+	Prefix      string   `json:"prefix,omitempty"`
+	ExpiresAt   string   `json:"expires_at,omitempty"`
+	RevokedAt   string   `json:"revoked_at,omitempty"`
+	LastUsedAt  string   `json:"last_used_at,omitempty"`
+	CreatedAt   string   `json:"created_at,omitempty"`
+	CreatedBy   string   `json:"created_by,omitempty"`
+}
+
+type DeployTokenExchange struct {
+	Token string `json:"token"`
+}
+
+type DeploySession struct {
+	Token       string   `json:"access_token"`
+	Expires     int64    `json:"expires_in"`
+	WorkspaceID string   `json:"workspace_id"`
+	Scopes      []string `json:"scopes"`
+}
+
+func NewDeployToken(workspaceID string, name string, scopes []string, hash string, prefix string, expiresAt string) *DeployToken {
+	return &DeployToken{
+		WorkspaceID: workspaceID,
+		Name:        name,
+		Scopes:      scopes,
+		TokenHash:   hash,
+		Prefix:      prefix,
+		ExpiresAt:   expiresAt,
+	}
+}
+
+func (ctx *DeploySession) HasScope(scope string) bool {
+	for _, value := range ctx.Scopes {
+		if value == scope {
+			return true
+		}
+	}
+
+	return false
+}
@@ -38,7 +38,31 @@ type PackageJson struct {
 	DevDependencies map[string]string `json:"devDependencies"`
 }
 
+// PackageManifest holds the package.json fields read from existing repos.
+// Other fields are ignored, so manifests with non standard shapes (ex:
+// author as an object) can still be read.
+type PackageManifest struct {
+	Name             string            `json:"name"`
+	Version          string            `json:"version"`
+	Description      string            `json:"description"`
+	Dependencies     map[string]string `json:"dependencies"`
+	DevDependencies  map[string]string `json:"devDependencies"`
+	PeerDependencies map[string]string `json:"peerDependencies"`
+}
+
+func (ctx *PackageManifest) AllDependencies() map[string]string {
+	dependencies := map[string]string{}
+	for _, deps := range []map[string]string{ctx.DevDependencies, ctx.Dependencies} {
+		for name, version := range deps {
+			dependencies[name] = version
+		}
+	}
+
+	return dependencies
+}
+
 type PackageJsonFileProps struct {
+	PackageManagerFileProps
 	Name      string
 	Namespace string
 	Repo      string
@@ -49,6 +73,15 @@ type PackageJsonFileProps struct {
 	Type      string
 }
 
+// PackageManagerFileProps are the commands of the package manager used on
+// the generated files.
+type PackageManagerFileProps struct {
+	PackageManager string
+	Install        string
+	Run            string
+	Exec           string
+}
+
 type ViteJsonFileProps struct {
 	Namespace string
 	Scope     string
@@ -68,26 +101,45 @@ type SublimePackages struct {
 	Description string            `json:"description"`
 }
 
+// DetectedPackage is a package found on libs/ or packages/ of an existing
+// monorepo by "sublime init".
+type DetectedPackage struct {
+	Package  SublimePackages    `json:"package"`
+	Template utils.TemplateType `json:"template"`
+	Version  string             `json:"version"`
+	Path     string             `json:"path"`
+}
+
 type SublimeJsonFileProps struct {
-	Name         string            `json:"name"`
-	Repo         string            `json:"repo"`
-	Namespace    string            `json:"namespace"`
-	Root         string            `json:"root"`
-	Organization string            `json:"organization"`
-	ID           string            `json:"id"`
-	Description  string            `json:"description"`
-	Packages     []SublimePackages `json:"packages"`
-}
-
-type SublimeViperProps struct {
-	Name         string            `mapstructure:"name"`
-	Repo         string            `mapstructure:"repo"`
-	Namespace    string            `mapstructure:"namespace"`
-	Root         string            `mapstructure:"root"`
-	Organization string            `mapstructure:"organization"`
-	ID           string            `mapstructure:"id"`
-	Description  string            `mapstructure:"description"`
-	Packages     []SublimePackages `mapstructure:"packages"`
+	Schema         string            `json:"$schema,omitempty"`
+	SchemaVersion  int               `json:"schemaVersion"`
+	Name           string            `json:"name"`
+	Repo           string            `json:"repo"`
+	Namespace      string            `json:"namespace"`
+	Root           string            `json:"root"`
+	Organization   string            `json:"organization"`
+	ID             string            `json:"id"`
+	Description    string            `json:"description"`
+	Packages       []SublimePackages `json:"packages"`
+	Templates      []TemplateSource  `json:"templates,omitempty"`
+	Hooks          *WorkspaceHooks   `json:"hooks,omitempty"`
+	PackageManager string            `json:"packageManager,omitempty"`
+}
+
+// WorkspaceHooks are shell commands run on the workspace root around the
+// create and action (deploy) commands.
+type WorkspaceHooks struct {
+	PreCreate  []string `json:"preCreate,omitempty"`
+	PostCreate []string `json:"postCreate,omitempty"`
+	PreDeploy  []string `json:"preDeploy,omitempty"`
+	PostDeploy []string `json:"postDeploy,omitempty"`
+}
+
+// SchemaViolation is a value of .sublime.json not matching the schema. Path
+// is a json pointer to the value.
+type SchemaViolation struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
 }
 
 type ReadmeFileProps struct {
@@ -97,21 +149,23 @@ type ReadmeFileProps struct {
 }
 
 type ReleaseYamlFileProps struct {
+	PackageManagerFileProps
 	Username string
 	Email    string
 	Scope    string
 }
 
 type ArtifactsYamlFileProps struct {
+	PackageManagerFileProps
 	Version string
 }
 
 type SnapshotsYamlFileProps struct {
-	Version string
-}
-
-type ApiExtractorFileProps struct {
-	Name string
+	PackageManagerFileProps
+	Version  string
+	Username string
+	Email    string
+	Scope    string
 }
 
 type TsconfigBase struct {
@@ -0,0 +1,173 @@
+-- Scoped and revocable deploy tokens used by `sublime action` on CI.
+-- The CLI only stores the sha256 of the token. CI exchanges the raw token for
+-- a short lived jwt that carries the workspace and scopes as claims, so the
+-- service role key is no longer needed outside of the platform.
+--
+-- The jwt is signed for the dedicated `deploy` role and has no `sub`: it is
+-- not a user session, so the policies written for `authenticated` never
+-- match it and it can only touch the rows named by its deploy_* claims.
+
+create extension if not exists pgcrypto with schema extensions;
+create extension if not exists pgjwt with schema extensions;
+
+create table if not exists public.deploy_tokens (
+  id uuid primary key default gen_random_uuid(),
+  workspace_id uuid not null references public.workspaces (id) on delete cascade,
+  name text not null default 'deploy',
+  scopes text[] not null default array['upload', 'version'],
+  token_hash text not null unique,
+  prefix text not null,
+  expires_at timestamptz,
+  revoked_at timestamptz,
+  last_used_at timestamptz,
+  created_at timestamptz not null default now(),
+  created_by uuid not null default auth.uid() references auth.users (id),
+  constraint deploy_tokens_scopes_check check (scopes <@ array['upload', 'version'])
+);
+
+alter table public.deploy_tokens enable row level security;
+
+create or replace function public.is_workspace_member(workspace uuid)
+returns boolean
+language sql
+security definer
+stable
+set search_path = public
+as $$
+  select exists (
+    select 1
+    from public.workspaces w
+    join public.organization_users ou on ou.organization_id = w.organization_id
+    where w.id = workspace and ou.user_id = auth.uid()
+  );
+$$;
+
+create policy "members read deploy tokens" on public.deploy_tokens
+  for select using (public.is_workspace_member(workspace_id));
+
+create policy "members create deploy tokens" on public.deploy_tokens
+  for insert with check (public.is_workspace_member(workspace_id) and created_by = auth.uid());
+
+-- Revocation is the only allowed update.
+create policy "members revoke deploy tokens" on public.deploy_tokens
+  for update using (public.is_workspace_member(workspace_id));
+
+revoke update on public.deploy_tokens from authenticated;
+grant update (revoked_at) on public.deploy_tokens to authenticated;
+
+create or replace function public.exchange_deploy_token(token text)
+returns json
+language plpgsql
+security definer
+set search_path = public
+as $$
+declare
+  found public.deploy_tokens;
+  expires bigint := extract(epoch from now() + interval '15 minutes')::bigint;
+begin
+  select * into found
+  from public.deploy_tokens
+  where token_hash = encode(extensions.digest(token, 'sha256'), 'hex')
+    and revoked_at is null
+    and (expires_at is null or expires_at > now());
+
+  if found.id is null then
+    raise exception 'invalid deploy token' using errcode = '28000', hint = 'Create a new token with: sublime token create';
+  end if;
+
+  update public.deploy_tokens set last_used_at = now() where id = found.id;
+
+  return json_build_object(
+    'access_token', extensions.sign(
+      json_build_object(
+        'role', 'deploy',
+        'aud', 'deploy',
+        'exp', expires,
+        'deploy_token_id', found.id,
+        'deploy_workspace_id', found.workspace_id,
+        'deploy_scopes', found.scopes
+      ),
+      current_setting('app.settings.jwt_secret')
+    ),
+    'expires_in', 900,
+    'workspace_id', found.workspace_id,
+    'scopes', found.scopes
+  );
+end;
+$$;
+
+grant execute on function public.exchange_deploy_token(text) to anon;
+
+do $$
+begin
+  if not exists (select 1 from pg_roles where rolname = 'deploy') then
+    create role deploy nologin noinherit;
+  end if;
+end;
+$$;
+
+grant deploy to authenticator;
+grant usage on schema public, storage to deploy;
+
+create or replace function public.deploy_scope(scope text)
+returns uuid
+language sql
+stable
+as $$
+  select case
+    when (auth.jwt() -> 'deploy_scopes') ? scope then (auth.jwt() ->> 'deploy_workspace_id')::uuid
+    else null
+  end;
+$$;
+
+-- The organization bucket of the workspace a token may upload to. The
+-- deploy role can not read organizations, so the lookup runs as definer.
+create or replace function public.deploy_bucket()
+returns text
+language sql
+security definer
+stable
+set search_path = public
+as $$
+  select o.name
+  from public.workspaces w
+  join public.organization o on o.id = w.organization_id
+  where w.id = public.deploy_scope('upload');
+$$;
+
+grant execute on function public.deploy_scope(text), public.deploy_bucket() to deploy;
+
+-- The token workspace itself is readable whatever the scopes.
+grant select on public.workspaces to deploy;
+
+create policy "deploy tokens read workspace" on public.workspaces
+  for select to deploy using (id = (auth.jwt() ->> 'deploy_workspace_id')::uuid);
+
+-- Version updates: only the version of packages of the token workspace.
+grant select, update (version) on public.packages to deploy;
+
+create policy "deploy tokens read packages" on public.packages
+  for select to deploy using (workspace_id = public.deploy_scope('version'));
+
+create policy "deploy tokens update package versions" on public.packages
+  for update to deploy
+  using (workspace_id = public.deploy_scope('version'))
+  with check (workspace_id = public.deploy_scope('version'));
+
+-- Uploads: only the organization bucket of the token workspace.
+grant select on storage.buckets to deploy;
+grant select, insert, update on storage.objects to deploy;
+
+create policy "deploy tokens read bucket" on storage.buckets
+  for select to deploy using (id = public.deploy_bucket());
+
+create policy "deploy tokens read artifacts" on storage.objects
+  for select to deploy using (bucket_id = public.deploy_bucket());
+
+create policy "deploy tokens upload artifacts" on storage.objects
+  for insert to deploy with check (bucket_id = public.deploy_bucket());
+
+create policy "deploy tokens overwrite artifacts" on storage.objects
+  for update to deploy
+  using (bucket_id = public.deploy_bucket())
+  with check (bucket_id = public.deploy_bucket());
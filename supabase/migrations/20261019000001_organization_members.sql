@@ -0,0 +1,151 @@
+-- Organization management from the CLI (`sublime org`).
+-- Authors create organizations through create_organization and become their
+-- owner. Owners invite registered authors by email and remove members.
+-- Emails and names live on auth.users, so members are listed through a
+-- security definer function.
+
+create or replace function public.organization_role(organization uuid)
+returns text
+language sql
+security definer
+stable
+set search_path = public
+as $$
+  select role
+  from public.organization_users
+  where organization_id = organization and user_id = auth.uid();
+$$;
+
+alter table public.organization enable row level security;
+alter table public.organization_users enable row level security;
+
+create policy "members read organizations" on public.organization
+  for select using (public.organization_role(id) is not null);
+
+create policy "owners delete organizations" on public.organization
+  for delete using (public.organization_role(id) = 'owner');
+
+create policy "members read memberships" on public.organization_users
+  for select using (public.organization_role(organization_id) is not null);
+
+-- Organizations and their first owner are created together, so no row is
+-- ever left without members for another author to read, join or delete.
+-- There are no insert policies: organizations only come from here and
+-- memberships from invite_organization_member.
+create or replace function public.create_organization(name text)
+returns public.organization
+language plpgsql
+security definer
+set search_path = public
+as $$
+declare
+  created public.organization;
+begin
+  if auth.uid() is null then
+    raise exception 'login required' using errcode = '42501';
+  end if;
+
+  insert into public.organization (name)
+  values (create_organization.name)
+  returning * into created;
+
+  insert into public.organization_users (organization_id, user_id, role)
+  values (created.id, auth.uid(), 'owner');
+
+  return created;
+end;
+$$;
+
+create or replace function public.organization_owners(organization uuid)
+returns bigint
+language sql
+security definer
+stable
+set search_path = public
+as $$
+  select count(*)
+  from public.organization_users
+  where organization_id = organization and role = 'owner';
+$$;
+
+-- Owners can not remove themselves, and an organization keeps an owner.
+create policy "owners remove members" on public.organization_users
+  for delete using (
+    public.organization_role(organization_id) = 'owner'
+    and user_id <> auth.uid()
+    and (role <> 'owner' or public.organization_owners(organization_id) > 1)
+  );
+
+-- Artifacts bucket of the organization, named after it.
+create policy "owners create organization buckets" on storage.buckets
+  for insert with check (
+    exists (
+      select 1
+      from public.organization o
+      join public.organization_users ou on ou.organization_id = o.id
+      where o.name = storage.buckets.name and ou.user_id = auth.uid() and ou.role = 'owner'
+    )
+  );
+
+create or replace function public.get_organization_members(organization_id uuid)
+returns table (user_id uuid, email text, name text, username text, role text, created_at timestamptz)
+language plpgsql
+security definer
+stable
+set search_path = public
+as $$
+begin
+  if public.organization_role(organization_id) is null then
+    raise exception 'not a member of organization %', organization_id using errcode = '42501';
+  end if;
+
+  return query
+    select u.id, u.email::text, u.raw_user_meta_data ->> 'name', u.raw_user_meta_data ->> 'author', ou.role, ou.created_at
+    from public.organization_users ou
+    join auth.users u on u.id = ou.user_id
+    where ou.organization_id = get_organization_members.organization_id
+    order by ou.created_at;
+end;
+$$;
+
+create or replace function public.invite_organization_member(organization_id uuid, email text, role text default 'member')
+returns json
+language plpgsql
+security definer
+set search_path = public
+as $$
+declare
+  invited auth.users;
+  membership public.organization_users;
+begin
+  if public.organization_role(organization_id) is distinct from 'owner' then
+    raise exception 'only owners can invite members' using errcode = '42501';
+  end if;
+
+  if role not in ('owner', 'member') then
+    raise exception 'invalid role %', role using errcode = '22023';
+  end if;
+
+  select * into invited from auth.users u where lower(u.email) = lower(invite_organization_member.email);
+  if invited.id is null then
+    raise exception 'no author registered with %', email using errcode = 'P0002', hint = 'Ask them to run "sublime register" first.';
+  end if;
+
+  insert into public.organization_users (organization_id, user_id, role)
+  values (invite_organization_member.organization_id, invited.id, invite_organization_member.role)
+  returning * into membership;
+
+  return json_build_object(
+    'user_id', invited.id,
+    'email', invited.email,
+    'name', invited.raw_user_meta_data ->> 'name',
+    'username', invited.raw_user_meta_data ->> 'author',
+    'role', membership.role,
+    'created_at', membership.created_at
+  );
+end;
+$$;
+
+grant execute on function public.create_organization(text) to authenticated;
+grant execute on function public.get_organization_members(uuid) to authenticated;
+grant execute on function public.invite_organization_member(uuid, text, text) to authenticated;
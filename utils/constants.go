@@ -27,15 +27,52 @@ type PackageType string
 
 type TemplateType string
 
+type TemplateSourceKind string
+
 type GitType string
 
 type EnvType string
 
+type DeployScope string
+
+type OrganizationRole string
+
+type DriftKind string
+
+type SyncActionKind string
+
+type OrphanPolicy string
+
+type FileStatus string
+
+type OutputFormat string
+
+type ProgressMode string
+
+type PackageManagerType string
+
+type BumpType string
+
+type DoctorStatus string
+
 type Templates struct {
 	Link     string       `json:"link"`
 	Template TemplateType `json:"template"`
 }
 
+const (
+	OutputTable OutputFormat = "table"
+	OutputJson  OutputFormat = "json"
+	OutputYaml  OutputFormat = "yaml"
+)
+
+const (
+	ProgressAuto        ProgressMode = "auto"
+	ProgressInteractive ProgressMode = "interactive"
+	ProgressPlain       ProgressMode = "plain"
+	ProgressJson        ProgressMode = "json"
+)
+
 const (
 	Production EnvType = "production"
 	Local      EnvType = "local"
@@ -48,6 +85,92 @@ const (
 	Branch GitType = "branch"
 )
 
+const (
+	ScopeUpload  DeployScope = "upload"
+	ScopeVersion DeployScope = "version"
+)
+
+const (
+	RoleOwner  OrganizationRole = "owner"
+	RoleMember OrganizationRole = "member"
+)
+
+const (
+	DriftMissingID       DriftKind = "missing-id"
+	DriftStaleID         DriftKind = "stale-id"
+	DriftOrphan          DriftKind = "orphan"
+	DriftField           DriftKind = "field"
+	DriftVersion         DriftKind = "version"
+	DriftMissingManifest DriftKind = "missing-manifest"
+)
+
+const (
+	SyncLink        SyncActionKind = "link"
+	SyncCreate      SyncActionKind = "create"
+	SyncDescription SyncActionKind = "push-description"
+	SyncVersion     SyncActionKind = "push-version"
+	SyncAdopt       SyncActionKind = "adopt"
+	SyncDelete      SyncActionKind = "delete"
+)
+
+const (
+	OrphanAsk    OrphanPolicy = "ask"
+	OrphanAdopt  OrphanPolicy = "adopt"
+	OrphanDelete OrphanPolicy = "delete"
+	OrphanSkip   OrphanPolicy = "skip"
+)
+
+const (
+	FileCreated     FileStatus = "created"
+	FileOverwritten FileStatus = "overwritten"
+	FileSkipped     FileStatus = "skipped"
+	FileUpdated     FileStatus = "updated"
+	FileUnchanged   FileStatus = "unchanged"
+	FileMerged      FileStatus = "merged"
+	FileKept        FileStatus = "kept"
+	FileConflict    FileStatus = "conflict"
+	FileDeleted     FileStatus = "deleted"
+)
+
+const (
+	Yarn      PackageManagerType = "yarn"
+	YarnBerry PackageManagerType = "yarn-berry"
+	Npm       PackageManagerType = "npm"
+	Pnpm      PackageManagerType = "pnpm"
+)
+
+const (
+	BumpMajor BumpType = "major"
+	BumpMinor BumpType = "minor"
+	BumpPatch BumpType = "patch"
+	BumpNone  BumpType = "none"
+)
+
+const (
+	DoctorOk      DoctorStatus = "ok"
+	DoctorWarning DoctorStatus = "warning"
+	DoctorError   DoctorStatus = "error"
+	DoctorSkipped DoctorStatus = "skipped"
+)
+
+const (
+	DoctorGroupEnvironment = "environment"
+	DoctorGroupTemplates   = "templates"
+	DoctorGroupAuthor      = "author"
+	DoctorGroupCloud       = "cloud"
+	DoctorGroupWorkspace   = "workspace"
+)
+
+// Minimum versions of the tools checked by "sublime doctor".
+const (
+	MinimumNode      = "16.0.0"
+	MinimumYarn      = "1.22.0"
+	MinimumYarnBerry = "2.0.0"
+	MinimumNpm       = "7.0.0"
+	MinimumPnpm      = "7.0.0"
+	MinimumGit       = "2.28.0"
+)
+
 const (
 	Library PackageType = "lib"
 	Package PackageType = "pkg"
@@ -58,24 +181,68 @@ const (
 	Solid      TemplateType = "solid"
 	Vue        TemplateType = "vue"
 	Typescript TemplateType = "typescript"
+	React      TemplateType = "react"
+	Preact     TemplateType = "preact"
+	Svelte     TemplateType = "svelte"
+	Vanilla    TemplateType = "vanilla"
+	Workspace  TemplateType = "workspace"
+)
+
+const (
+	SourceLocal   TemplateSourceKind = "local"
+	SourceGit     TemplateSourceKind = "git"
+	SourceTarball TemplateSourceKind = "tarball"
+	SourceNpm     TemplateSourceKind = "npm"
+)
+
+const NpmRegistry = "https://registry.npmjs.org"
+
+// TemplateManifest is the file a template declares its prompts, variables,
+// rendered files and hooks on.
+const TemplateManifest = "sublime-template.json"
+
+const (
+	TemplatePromptInput   = "input"
+	TemplatePromptSelect  = "select"
+	TemplatePromptConfirm = "confirm"
 )
 
 var TemplatesMap = []Templates{
 	{
 		Template: Vue,
-		Link:     "git@github.com:websublime/sublime-vue-template.git",
+		Link:     "https://github.com/websublime/sublime-vue-template.git",
 	},
 	{
 		Template: Lit,
-		Link:     "git@github.com:websublime/sublime-lit-template.git",
+		Link:     "https://github.com/websublime/sublime-lit-template.git",
 	},
 	{
 		Template: Solid,
-		Link:     "git@github.com:websublime/sublime-solid-template.git",
+		Link:     "https://github.com/websublime/sublime-solid-template.git",
 	},
 	{
 		Template: Typescript,
-		Link:     "git@github.com:websublime/sublime-typescript-template.git",
+		Link:     "https://github.com/websublime/sublime-typescript-template.git",
+	},
+	{
+		Template: React,
+		Link:     "https://github.com/websublime/sublime-react-template.git",
+	},
+	{
+		Template: Preact,
+		Link:     "https://github.com/websublime/sublime-preact-template.git",
+	},
+	{
+		Template: Svelte,
+		Link:     "https://github.com/websublime/sublime-svelte-template.git",
+	},
+	{
+		Template: Vanilla,
+		Link:     "https://github.com/websublime/sublime-vanilla-template.git",
+	},
+	{
+		Template: Workspace,
+		Link:     "https://github.com/websublime/sublime-workspace-template.git",
 	},
 }
 
@@ -102,13 +269,62 @@ const (
 	ErrorInvalidaIndentation   ErrorType = "EINDENTATION_INVALID"
 	ErrorInvalidTypescript     ErrorType = "ETYPESCRIPT_INVALID"
 	ErrorInvalidEnvironment    ErrorType = "EENVIRONMENT_INVALID"
+	ErrorWorkspaceDrift        ErrorType = "EWORKSPACE_DRIFT"
+	ErrorInvalidConfig         ErrorType = "ECONFIG_INVALID"
+	ErrorHookFailed            ErrorType = "EHOOK_FAILED"
+	ErrorVersionMismatch       ErrorType = "EVERSION_MISMATCH"
+	ErrorDoctorFailed          ErrorType = "EDOCTOR_FAILED"
 
 	CommandRoot                      string = "sublime"
 	CommandFlagRoot                  string = "root"
 	CommandFlagConfig                string = "config"
+	CommandFlagVerbose               string = "verbose"
+	CommandFlagTimeout               string = "timeout"
+	CommandFlagCommandTimeout        string = "command-timeout"
+	CommandFlagRetries               string = "retries"
+	CommandFlagProfile               string = "profile"
+	CommandFlagOutput                string = "output"
+	CommandFlagQuiet                 string = "quiet"
+	CommandFlagProgress              string = "progress"
 	CommandFlagWorkspaceOrganization string = "organization"
+	CommandFlagPackageManager        string = "package-manager"
+	CommandFlagTemplate              string = "template"
+	CommandFlagVar                   string = "var"
 	CommandFlagActionType            string = "type"
 	CommandFlagActionEnv             string = "env"
+	CommandFlagActionTag             string = "tag"
+	CommandFlagDevServerHost         string = "host"
+	CommandFlagDevServerPort         string = "port"
+	CommandFlagDevServerData         string = "data"
+	CommandFlagDevServerSeed         string = "seed"
+	CommandFlagDevServerApiKey       string = "api-key"
+	CommandFlagDevServerServiceKey   string = "service-key"
+	CommandFlagTokenWorkspace        string = "workspace"
+	CommandFlagTokenScope            string = "scope"
+	CommandFlagTokenName             string = "name"
+	CommandFlagTokenExpires          string = "expires"
+	CommandFlagOrgOrganization       string = "organization"
+	CommandFlagOrgRole               string = "role"
+	CommandFlagSyncCheck             string = "check"
+	CommandFlagSyncDryRun            string = "dry-run"
+	CommandFlagSyncOrphans           string = "orphans"
+	CommandFlagInitName              string = "name"
+	CommandFlagInitRepo              string = "repo"
+	CommandFlagInitDescription       string = "description"
+	CommandFlagInitForce             string = "force"
+	CommandFlagUpgradeDryRun         string = "dry-run"
+	CommandFlagUpgradeForce          string = "force"
+	CommandFlagChangesetPackage      string = "package"
+	CommandFlagChangesetBump         string = "bump"
+	CommandFlagChangesetSummary      string = "summary"
+	CommandFlagChangesetDryRun       string = "dry-run"
+	CommandFlagReleaseCloud          string = "cloud"
+	CommandFlagDoctorOffline         string = "offline"
+	CommandFlagLoginEmail            string = "email"
+	CommandFlagLoginPasswordStdin    string = "password-stdin"
+	CommandFlagCreateName            string = "name"
+	CommandFlagCreateType            string = "type"
+	CommandFlagCreateDescription     string = "description"
 
 	CommandRegister  string = "register"
 	CommandLogin     string = "login"
@@ -116,11 +332,61 @@ const (
 	CommandCreate    string = "create"
 	CommandAction    string = "action"
 	CommandStatus    string = "status"
-
-	MessageCommandConfigUsage     string = "Config file (default is .sublime.json)."
-	MessageCommandRootUsage       string = "Project working dir, default to current dir."
-	MessageCommandRootShort       string = "CLI tool to manage monorepo packages."
-	MessageCommandRootTokenExpire string = "Your token is expired. Start renew action."
+	CommandDevServer string = "dev-server"
+	CommandToken     string = "token"
+	CommandOrg       string = "org"
+	CommandSync      string = "sync"
+	CommandInit      string = "init"
+	CommandConfig    string = "config"
+	CommandUpgrade   string = "upgrade"
+	CommandChangeset string = "changeset"
+	CommandRelease   string = "release"
+	CommandDoctor    string = "doctor"
+
+	CommandConfigValidate string = "validate"
+	CommandConfigMigrate  string = "migrate"
+
+	CommandChangesetAdd     string = "add"
+	CommandChangesetStatus  string = "status"
+	CommandChangesetVersion string = "version"
+
+	CommandReleasePlan  string = "plan"
+	CommandReleaseApply string = "apply"
+
+	CommandTokenCreate string = "create"
+	CommandTokenList   string = "list"
+	CommandTokenRevoke string = "revoke"
+
+	CommandWorkspaceList string = "list"
+	CommandWorkspaceShow string = "show"
+
+	CommandOrgCreate       string = "create"
+	CommandOrgList         string = "list"
+	CommandOrgUse          string = "use"
+	CommandOrgMembers      string = "members"
+	CommandOrgInvite       string = "invite"
+	CommandOrgRemoveMember string = "remove-member"
+
+	EnvDeployToken string = "SUBLIME_DEPLOY_TOKEN"
+	EnvGithubRef   string = "GITHUB_REF"
+
+	SublimeSchemaVersion int    = 1
+	SublimeSchemaUrl     string = "https://raw.githubusercontent.com/websublime/sublime-cli/main/schemas/sublime.schema.json"
+
+	MessageCommandConfigUsage         string = "Config file (default is .sublime.json)."
+	MessageCommandRootUsage           string = "Project working dir, default to current dir."
+	MessageCommandVerboseUsage        string = "Log api requests and responses (secrets redacted) and stream the output of external commands."
+	MessageCommandTimeoutUsage        string = "Timeout for each api request."
+	MessageCommandCommandTimeoutUsage string = "Timeout for each external command (git, package manager and hooks), 0 for none."
+	MessageCommandRetriesUsage        string = "Number of retries for failed idempotent api requests."
+	MessageCommandProfileUsage        string = "Profile of ~/.sublime/config.json to use (env SUBLIME_PROFILE)."
+	MessageCommandOutputUsage         string = "Output format: table, json or yaml."
+	MessageCommandQuietUsage          string = "Do not print the banner and progress bars."
+	MessageErrorOutputFormat          string = "Output %s is not valid. Valid outputs are: table, json, yaml."
+	MessageCommandProgressUsage       string = "Progress renderer: auto, interactive, plain or json. Auto is plain when stdout is not a terminal."
+	MessageErrorProgressMode          string = "Progress %s is not valid. Valid renderers are: auto, interactive, plain, json."
+	MessageCommandRootShort           string = "CLI tool to manage monorepo packages."
+	MessageCommandRootTokenExpire     string = "Your token is expired. Start renew action."
 
 	MessageErrorAuthorFileMissing  string = "Author file not found. Please register first or login to cloud service."
 	MessageErrorParseFile          string = "Unable to parse file."
@@ -128,6 +394,7 @@ const (
 	MessageErrorWriteFile          string = "Unable to write file"
 	MessageErrorReadFile           string = "Unable to read file"
 	MessageErrorAuthorTokenMissing string = "Author is not authenticated. Please login first."
+	MessageErrorProfileMissing     string = "Profile %s not found on %s."
 
 	// Register command
 	MessageCommandRegisterShort string = "Register author on sublime cloud platform."
@@ -171,6 +438,8 @@ const (
 	MessageCommandLoginProgressInit   string = "Attempt to log you in on the cloud platform"
 	MessageCommandLoginAuthor         string = "Author loggedin. Init update author data."
 	MessageCommandLoginSuccess        string = "Author data update and loggedin."
+	MessageCommandLoginEmail          string = "Email of the author, its prompt is skipped."
+	MessageCommandLoginPasswordStdin  string = "Read the password from stdin, its prompt is skipped."
 
 	MessageErrorCommandLoginEmailPrompt    string = "Email is not valid."
 	MessageErrorCommandLoginPasswordPrompt string = "Password is not valid."
@@ -180,11 +449,15 @@ const (
 	MessageCommandWorkspaceLong  string = `Workspace is a monorepo structure powered by turbo with the ability to create javascript packages.
 	It supports typescript, vue, lit and solidjs governed by vite and all are build as web components.
 	`
-	MessageCommandWorkspaceOrganization      string = "Github organization name [REQUIRED]"
+	MessageCommandWorkspaceListShort         string = "List workspaces of an organization."
+	MessageCommandWorkspaceShowShort         string = "Show a cloud workspace and its packages (default is the one on .sublime.json)."
+	MessageCommandWorkspaceOrganization      string = "Github organization name (default is the one selected with \"sublime org use\")."
+	MessageCommandWorkspaceTemplate          string = "Workspace template, a name declared on templates or a source (default is workspace)."
 	MessageCommandWorkspaceProgressInit      string = "Starting creating monorepo structure"
 	MessageCommandWorkspaceProgressWorkflows string = "Initialise monorepo workflows"
 	MessageCommandWorkspaceProgressGit       string = "Initialise git on workspace"
-	MessageCommandWorkspaceProgressYarn      string = "Initialise yarn on workspace"
+	MessageCommandWorkspaceProgressYarn      string = "Initialise %s on workspace"
+	MessageCommandWorkspacePackageManager    string = "Package manager of the workspace: yarn, yarn-berry, npm or pnpm."
 	MessageCommandWorkspaceProgressVite      string = "Starting building vite plugin"
 	MessageCommandWorkspaceProgressCloud     string = "Publish workspace on cloud platform"
 	MessageCommandWorkspaceSuccess           string = "Your workspace is ready. Create your first package."
@@ -199,26 +472,50 @@ const (
 	MessageErrorCommandWorkspaceDescriptionPrompt   string = "Description provided is not valid."
 	MessageErrorCommandWorkspaceInvalidNamespace    string = "Please provide a valid github organization name without @."
 	MessageErrorCommandWorkspaceInvalidDirectory    string = "Cannot create workspace folder."
+	MessageErrorPackageManager                      string = "Package manager %s is not valid. Valid package managers are: yarn, yarn-berry, npm, pnpm."
 
 	// Create command
 	MessageCommandCreateShort string = "Create JS/TS packages"
 	MessageCommandCreateLong  string = "Create JS/TS packages based on templates provided by the CLI tool."
 
-	MessageCommandCreateProgressInit   string = "Starting creating package structure"
-	MessageCommandCreateProgressUpdate string = "Updating monorepo files"
-	MessageCommandCreateProgressYarn   string = "Yarn linking and install packages"
-	MessageCommandCreateProgressCloud  string = "Creating package on cloud organisation"
-	MessageCommandCreateSuccess        string = "Your package is ready. Start working on it."
+	MessageCommandCreateProgressInit      string = "Starting creating package structure"
+	MessageCommandCreateProgressUpdate    string = "Updating monorepo files"
+	MessageCommandCreateProgressYarn      string = "Linking and installing packages with %s"
+	MessageCommandCreateProgressCloud     string = "Creating package on cloud organisation"
+	MessageCommandCreateProgressHooks     string = "Running template hooks"
+	MessageCommandCreateProgressWorkspace string = "Running %s workspace hooks"
+	MessageCommandCreateSuccess           string = "Your package is ready. Start working on it."
 
 	MessageCommandCreateNamePrompt        string = "Provide the package name:"
 	MessageCommandCreateTypePrompt        string = "Provide the package type:"
 	MessageCommandCreateTemplatePrompt    string = "Provide the template type:"
 	MessageCommandCreateDescriptionPrompt string = "Provide package description:"
+	MessageCommandCreateTemplate          string = "Package template, a builtin (solid, lit, vue, react, preact, svelte, vanilla, typescript), a name declared on templates or a source."
+	MessageCommandCreateVar               string = "Template variable as key=value, its prompt is skipped."
+	MessageCommandCreateName              string = "Package name, its prompt is skipped."
+	MessageCommandCreateType              string = "Package type, pkg or lib, its prompt is skipped."
+	MessageCommandCreateDescription       string = "Package description, its prompt is skipped."
 
 	MessageErrorCommandCreateNamePrompt        string = "Name provided is not valid."
 	MessageErrorCommandCreateTemplateInvalid   string = "Template type is invalid."
+	MessageErrorCommandCreateType              string = "Package type %s is not valid. Valid types are: pkg, lib."
+	MessageErrorCommandCreatePackageExists     string = "Package directory %s already exists."
 	MessageErrorCommandCreateDescriptionPrompt string = "Description provided is not valid."
 
+	// Templates
+	MessageErrorTemplateUnknown  string = "Template %s is not a builtin, is not declared on templates of .sublime.json or ~/.sublime/config.json and is not a source."
+	MessageErrorTemplateFetch    string = "Unable to fetch template %s from %s: %s"
+	MessageErrorTemplateArchive  string = "Invalid template archive %s: %s"
+	MessageErrorTemplateNpm      string = "Package %s has no version %s on %s."
+	MessageErrorTemplateLocal    string = "Template directory %s not found."
+	MessageErrorTemplateDownload string = "Download of %s failed with status %d."
+	MessageErrorTemplateManifest string = "Invalid template manifest %s: %s"
+	MessageErrorTemplateRender   string = "Unable to render template file %s: %s"
+	MessageErrorTemplateVariable string = "Template variable %s is required."
+	MessageErrorTemplateHook     string = "Template hook \"%s\" failed: %s"
+	MessageCommandTemplateStale  string = "Unable to refresh template %s (%s), using the cached copy."
+	MessageErrorWorkspaceHook    string = "Workspace hook %s \"%s\" failed: %s"
+
 	// Action command
 	MessageCommandActionShort string = "Github action command"
 	MessageCommandActionLong  string = "Action command is built to run on github workflows to create artifacts of the packages."
@@ -230,9 +527,299 @@ const (
 	MessageCommandActionArtifact      string = "Artifact uploaded to bucket."
 	MessageCommandActionVersionUpdate string = "Package %s updated to version: %s."
 
+	MessageCommandActionHooks         string = "Running %s workspace hooks"
+	MessageCommandActionDeployToken   string = "Using deploy token %s with scopes: %s."
+	MessageCommandActionServiceSecret string = "SUBLIME_DEPLOY_TOKEN is not set. Using the service role secret from SUBLIME_API_SECRET, which is deprecated. Create a token with: sublime token create"
+	MessageCommandActionTag           string = "Package tag to deploy (@scope/name@version), repeatable. Default is the tag of GITHUB_REF, or the tags on HEAD."
+	MessageCommandActionTags          string = "Deploying tags: %s."
+	MessageCommandActionTagSkipped    string = "Skipping tag %s: %s"
+
 	MessageErrorCommandActionEnv       string = "Action command can only run on CI environments."
+	MessageErrorCommandActionNoToken   string = "SUBLIME_DEPLOY_TOKEN is not set. Create one with \"sublime token create\" and add it as a repository secret."
+	MessageErrorCommandActionScope     string = "Deploy token is missing the %s scope."
+	MessageErrorCommandActionWorkspace string = "Deploy token belongs to workspace %s, not to %s."
 	MessageErrorCommandActionNoCommits string = "No commits founded. Please commit first."
+	MessageErrorCommandActionManifest  string = "Unable to read package.json of %s: %s"
+	MessageErrorCommandActionDist      string = "Unable to read the dist folder of %s: %s"
+	MessageErrorCommandActionUpload    string = "Unable to upload %s: %s"
+	MessageErrorCommandActionVersions  string = "Unable to update the version of %d package(s)."
+	MessageErrorCommandActionTagName   string = "no package %s on .sublime.json."
+	MessageErrorCommandActionTagMatch  string = "Tag %s does not match version %s of %s on package.json."
 
 	// Status command
 	MessageCommandStatusShort string = "Status about workspace"
+
+	// Token command
+	MessageCommandTokenShort string = "Manage workspace deploy tokens."
+	MessageCommandTokenLong  string = `Deploy tokens are scoped and revocable credentials for CI. Store the token
+	as the SUBLIME_DEPLOY_TOKEN secret of the repository and the action command will
+	exchange it for a short lived access token restricted to the workspace and scopes.
+	`
+	MessageCommandTokenCreateShort string = "Create a deploy token."
+	MessageCommandTokenListShort   string = "List deploy tokens of a workspace."
+	MessageCommandTokenRevokeShort string = "Revoke a deploy token."
+	MessageCommandTokenWorkspace   string = "Workspace id (default is the id on .sublime.json)."
+	MessageCommandTokenScope       string = "Token scopes: upload, version."
+	MessageCommandTokenName        string = "Token name."
+	MessageCommandTokenExpires     string = "Token lifetime (ex: 2160h). Default never expires."
+	MessageCommandTokenCreated     string = "Deploy token %s created with scopes: %s."
+	MessageCommandTokenCopy        string = "Copy the token below to the SUBLIME_DEPLOY_TOKEN repository secret. It will not be shown again."
+	MessageCommandTokenRevoked     string = "Deploy token %s revoked."
+
+	MessageErrorCommandTokenWorkspace string = "No workspace found. Use --workspace or run inside a workspace."
+	MessageErrorCommandTokenScope     string = "Scope %s is not valid. Valid scopes are: upload, version."
+
+	// Org command
+	MessageCommandOrgShort string = "Manage organizations and members."
+	MessageCommandOrgLong  string = `Organizations group workspaces and authors. Create one, select the default
+	organization used by the workspace command and manage its members.
+	Organization names should correspond to the github organization name.
+	`
+	MessageCommandOrgCreateShort       string = "Create an organization and its artifacts bucket."
+	MessageCommandOrgListShort         string = "List organizations of the author."
+	MessageCommandOrgUseShort          string = "Select the default organization."
+	MessageCommandOrgMembersShort      string = "List members of an organization."
+	MessageCommandOrgInviteShort       string = "Add a registered author to an organization."
+	MessageCommandOrgRemoveMemberShort string = "Remove a member from an organization."
+	MessageCommandOrgOrganization      string = "Organization name (default is the one selected with \"sublime org use\")."
+	MessageCommandOrgRole              string = "Member role: owner, member."
+	MessageCommandOrgCreated           string = "Organization %s created with bucket %s."
+	MessageCommandOrgUsed              string = "Default organization is now %s."
+	MessageCommandOrgInvited           string = "%s added to %s as %s."
+	MessageCommandOrgRemoved           string = "%s removed from %s."
+	MessageCommandOrgNextStep          string = "Select it as default with: sublime org use %s"
+
+	MessageErrorCommandOrgMissing    string = "No organization given. Use --organization or select one with \"sublime org use\"."
+	MessageErrorCommandOrgMember     string = "Author is not a member of organization %s."
+	MessageErrorCommandOrgOwner      string = "Only owners of %s can manage members."
+	MessageErrorCommandOrgRole       string = "Role %s is not valid. Valid roles are: owner, member."
+	MessageErrorCommandOrgName       string = "Organization name %s is not valid, it is also the id of its bucket. Use a lowercase slug like %s."
+	MessageErrorCommandOrgNoUser     string = "No member %s found on %s."
+	MessageErrorCommandOrgRemoveSelf string = "Owners can not remove themselves from %s."
+
+	// Init command
+	MessageCommandInitShort string = "Adopt an existing monorepo as a workspace."
+	MessageCommandInitLong  string = `Init runs inside an existing yarn workspaces repo with libs/ and packages/
+	folders. It detects the packages and their templates, generates .sublime.json, the
+	tsconfig.base.json references, the changeset config and the github workflows, and
+	registers the workspace and its packages on the cloud. Existing files are kept unless
+	--force is given.
+	`
+	MessageCommandInitName         string = "Workspace name (default is the root package.json name or the folder name)."
+	MessageCommandInitRepo         string = "Short name repo [org/repo] (default is the origin remote)."
+	MessageCommandInitDescription  string = "Workspace description (default is the root package.json description)."
+	MessageCommandInitForce        string = "Overwrite existing files."
+	MessageCommandInitDetected     string = "Detected %d packages."
+	MessageCommandInitCloud        string = "Workspace %s registered on the cloud with id %s."
+	MessageCommandInitCloudPackage string = "Package %s registered on the cloud with id %s."
+	MessageCommandInitSuccess      string = "Workspace adopted. Commit the generated files and add the repository secrets."
+
+	MessageErrorCommandInitExists   string = ".sublime.json already exists. Use \"sublime sync\" to reconcile it or --force to regenerate it."
+	MessageErrorCommandInitRepo     string = "Unable to resolve the repo from the origin remote. Use --repo org/repo."
+	MessageErrorCommandInitTsconfig string = "tsconfig.base.json could not be parsed, references were not added."
+	MessageErrorCommandInitPackage  string = "Package %s was not registered: %s. Run \"sublime sync\" after fixing it."
+
+	// Config command
+	MessageCommandConfigShort         string = "Validate and migrate .sublime.json."
+	MessageCommandConfigValidateShort string = "Validate .sublime.json against its JSON schema."
+	MessageCommandConfigMigrateShort  string = "Migrate .sublime.json to the current schema version."
+	MessageCommandConfigValid         string = "%s is valid (schema version %d)."
+	MessageCommandConfigOutdated      string = "%s uses schema version %d, current is %d. Run \"sublime config migrate\" to update it."
+	MessageCommandConfigMigrated      string = "%s migrated from schema version %d to %d."
+	MessageCommandConfigMigration     string = "v%d: %s"
+	MessageCommandConfigUpToDate      string = "%s is already on schema version %d."
+
+	MessageErrorCommandConfigInvalid string = "%s has %d schema violations."
+	MessageErrorSublimeSchemaNewer   string = ".sublime.json uses schema version %d, this cli supports up to %d. Please update sublime."
+	MessageErrorSublimeMigration     string = "Unable to migrate .sublime.json to schema version %d: %s"
+	MessageErrorWorkspaceLocked      string = "%s is locked by another sublime process. Try again when it finishes."
+
+	// Sync command
+	MessageCommandSyncShort string = "Reconcile .sublime.json packages with the cloud."
+	MessageCommandSyncLong  string = `Sync compares the packages on .sublime.json (name, id, type, description) and
+	the version on their package.json with the cloud packages of the workspace. It back-fills
+	missing ids, creates missing cloud packages, pushes descriptions and versions and adopts
+	or deletes orphaned cloud packages. Use --check to only report drift.
+	`
+	MessageCommandSyncCheck    string = "Only report drift, exit with error when any is found."
+	MessageCommandSyncDryRun   string = "Print the plan without changing .sublime.json or the cloud."
+	MessageCommandSyncOrphans  string = "What to do with cloud packages unknown to .sublime.json: ask, adopt, delete, skip."
+	MessageCommandSyncInSync   string = "Workspace %s is in sync with the cloud."
+	MessageCommandSyncSummary  string = "%d differences found between .sublime.json and the cloud."
+	MessageCommandSyncPlan     string = "Sync plan for workspace %s"
+	MessageCommandSyncDryRunOk string = "Dry run, nothing was changed."
+	MessageCommandSyncApplied  string = "%d changes applied to workspace %s."
+	MessageCommandSyncOrphan   string = "Cloud package %s (%s) is not on .sublime.json"
+	MessageCommandSyncConflict string = "%d conflicts need a manual fix."
+
+	MessageErrorCommandSyncWorkspace    string = "No workspace id on .sublime.json. Run inside a workspace created with \"sublime workspace\"."
+	MessageErrorCommandSyncOrphans      string = "Orphans policy %s is not valid. Valid policies are: ask, adopt, delete, skip."
+	MessageErrorCommandSyncCheckDryRun  string = "--check and --dry-run can not be used together."
+	MessageErrorCommandSyncNewerCloud   string = "Cloud version is newer than package.json. Pull the latest changes."
+	MessageErrorCommandSyncFieldChanged string = "Differs from the cloud. Update .sublime.json or the cloud package."
+	MessageErrorCommandSyncNoManifest   string = "package.json not found, version was not pushed."
+	MessageErrorCommandSyncOrphanSkip   string = "Cloud package is not on .sublime.json and was left untouched."
+
+	// Upgrade command
+	MessageCommandUpgradeShort string = "Upgrade the generated files to the templates of this version."
+	MessageCommandUpgradeLong  string = `Upgrade renders the embedded templates (github workflows, changeset config and the
+	vite, tsconfig and api-extractor configs of the packages) again with the variables stored
+	on .sublime/generated.json and three-way merges them with the current files, using the
+	originally generated content as base. Files without a known base only get the cli version
+	bumped, the other changes are reported as conflicts.
+	`
+	MessageCommandUpgradeDryRun    string = "Print the diff without changing any file."
+	MessageCommandUpgradeForce     string = "Replace the files with the new templates, discarding local changes."
+	MessageCommandUpgradeUpToDate  string = "Generated files are up to date with version %s."
+	MessageCommandUpgradeApplied   string = "%d files upgraded to version %s."
+	MessageCommandUpgradeDryRunOk  string = "Dry run, nothing was changed."
+	MessageCommandUpgradeConflicts string = "%d files have conflicts with the new templates. Merge the diff by hand or use --force."
+
+	MessageErrorCommandUpgradeTemplate string = "%s was generated from %s, which is no longer a template. It was left untouched."
+
+	// Changeset command
+	MessageCommandChangesetShort string = "Author changesets and version the packages."
+	MessageCommandChangesetLong  string = `Changeset writes the .changeset/*.md files of the changesets release workflow.
+	Add asks the bump of the packages changed since the base branch, status previews the version
+	bumps of the pending changesets (dependents of bumped packages get a patch) and version applies
+	them to the package.json and CHANGELOG.md of the packages.
+	`
+	MessageCommandChangesetAddShort      string = "Add a changeset for the changed packages."
+	MessageCommandChangesetStatusShort   string = "Preview the version bumps of the pending changesets."
+	MessageCommandChangesetVersionShort  string = "Bump the versions and changelogs, consuming the changesets."
+	MessageCommandChangesetPackage       string = "Package (package.json name or folder) to release, repeatable (default is the changed packages)."
+	MessageCommandChangesetBump          string = "Bump of the packages: major, minor or patch (prompted when missing)."
+	MessageCommandChangesetSummary       string = "Summary of the changes for the changelog (prompted when missing)."
+	MessageCommandChangesetDryRun        string = "Preview the version bumps without changing any file."
+	MessageCommandChangesetBumpPrompt    string = "Bump of %s (%s)"
+	MessageCommandChangesetSkip          string = "skip"
+	MessageCommandChangesetSummaryPrompt string = "Summary of the changes:"
+	MessageCommandChangesetAllPackages   string = "No changed packages found since %s, choose from every package."
+	MessageCommandChangesetAdded         string = "Changeset written to %s."
+	MessageCommandChangesetNone          string = "No pending changesets."
+	MessageCommandChangesetUnreleased    string = "%s changed without a changeset."
+	MessageCommandChangesetVersioned     string = "%d packages versioned. Run %s to update the lockfile."
+	MessageCommandChangesetDryRunOk      string = "Dry run, nothing was changed."
+
+	MessageErrorCommandChangesetBump        string = "Bump %s is not valid. Valid bumps are: major, minor, patch."
+	MessageErrorCommandChangesetPackage     string = "No package %s on the workspace."
+	MessageErrorCommandChangesetEmpty       string = "No packages to release on the changeset."
+	MessageErrorCommandChangesetPrompt      string = "--bump and --summary are required without a terminal."
+	MessageErrorCommandChangesetSummary     string = "Summary is required."
+	MessageErrorCommandChangesetParse       string = "Invalid changeset %s: %s"
+	MessageErrorCommandChangesetFrontMatter string = "Invalid changeset %s: the releases must be between --- lines."
+	MessageErrorCommandChangesetChanges     string = "Unable to detect the changed packages: %s"
+	MessageErrorCommandChangesetNoChanges   string = "No changed packages found since %s. Choose the packages to release with --package."
+
+	// Release command
+	MessageCommandReleaseShort string = "Version the packages from their conventional commits."
+	MessageCommandReleaseLong  string = `Release versions the packages without changesets, from the conventional commits
+	(feat, fix, perf, ! and BREAKING CHANGE) changing each package since its last @scope/name@version tag.
+	Plan previews the version bumps (dependents of bumped packages get a patch) and apply writes them
+	to the package.json and CHANGELOG.md of the packages, commits them and tags the released packages.
+	`
+	MessageCommandReleasePlanShort  string = "Preview the version bumps of the commits since the last tags."
+	MessageCommandReleaseApplyShort string = "Bump the versions and changelogs, commit and tag the release."
+	MessageCommandReleaseCloud      string = "Update the versions of the released packages on the cloud."
+	MessageCommandReleaseCommit     string = "chore(release): version packages"
+	MessageCommandReleaseNone       string = "No releasable commits since the last tags."
+	MessageCommandReleaseTagged     string = "Tagged %s."
+	MessageCommandReleaseApplied    string = "%d packages released on %s. Push them with git push --follow-tags."
+	MessageCommandReleaseNoCloudID  string = "%s has no cloud id, its version was not updated."
+
+	MessageErrorCommandReleaseVersions string = "Unable to update %d package versions on the cloud."
+
+	// Doctor command
+	MessageCommandDoctorShort string = "Diagnose the environment and the workspace."
+	MessageCommandDoctorLong  string = `Doctor checks the tools (node, package manager and git), the access to the git
+	template repos, the author file and its token, the api and the organization membership, and on a
+	workspace the .sublime.json, the packages, the tsconfig references and the generated workflows.
+	Problems are reported with a hint to fix them.
+	`
+	MessageCommandDoctorOffline          string = "Skip the checks needing the network (template repos, api and organization)."
+	MessageCommandDoctorOk               string = "Everything looks fine."
+	MessageCommandDoctorWarnings         string = "%d warnings found."
+	MessageCommandDoctorHint             string = "%s: %s"
+	MessageCommandDoctorVersion          string = "%s (minimum %s)"
+	MessageCommandDoctorMissing          string = "%s not found"
+	MessageCommandDoctorSkippedOffline   string = "offline"
+	MessageCommandDoctorNoWorkspace      string = "not on a workspace"
+	MessageCommandDoctorSkippedApi       string = "api not reachable"
+	MessageCommandDoctorSkippedToken     string = "token expired"
+	MessageCommandDoctorNoAuthor         string = "not logged in"
+	MessageCommandDoctorAccess           string = "reachable"
+	MessageCommandDoctorAuthorFile       string = "%s readable"
+	MessageCommandDoctorPermissions      string = "%s mode %s"
+	MessageCommandDoctorTokenValid       string = "expires %s"
+	MessageCommandDoctorTokenExpired     string = "expired %s"
+	MessageCommandDoctorApi              string = "%s (%s)"
+	MessageCommandDoctorMember           string = "member of %s"
+	MessageCommandDoctorNotMember        string = "not a member of %s"
+	MessageCommandDoctorNoOrganization   string = "no organization selected"
+	MessageCommandDoctorSublimeValid     string = "%s valid"
+	MessageCommandDoctorSublimeInvalid   string = "%d schema violations on %s"
+	MessageCommandDoctorPackageDir       string = "%s missing"
+	MessageCommandDoctorPackageName      string = "package.json name is %s, expected %s"
+	MessageCommandDoctorTsconfigValid    string = "%d references"
+	MessageCommandDoctorTsconfigMissing  string = "no reference to %s"
+	MessageCommandDoctorTsconfigStale    string = "reference %s has no package"
+	MessageCommandDoctorWorkflowVersion  string = "cli %s"
+	MessageCommandDoctorWorkflowOutdated string = "cli %s, running %s"
+
+	MessageCommandDoctorHintNode            string = "Install NodeJS %s or newer (https://nodejs.org)."
+	MessageCommandDoctorHintPackageManager  string = "Install %s %s or newer, or run corepack enable."
+	MessageCommandDoctorHintGit             string = "Install git %s or newer, needed for your commits and the generated workflows."
+	MessageCommandDoctorHintSsh             string = "Add your ssh key to the agent (ssh-add), check it with ssh -T git@<host> and that the host is on ~/.ssh/known_hosts."
+	MessageCommandDoctorHintHttps           string = "Check the url, and set the token of private repos on the tokenEnv variable of the template."
+	MessageCommandDoctorHintLogin           string = "Run sublime login."
+	MessageCommandDoctorHintPermissions     string = "The file holds your token, run chmod 600 %s."
+	MessageCommandDoctorHintToken           string = "The next command needing a login refreshes it, run sublime login if it fails."
+	MessageCommandDoctorHintApi             string = "Check your connection and the endpoint shown by sublime status (--profile, SUBLIME_API_URL and SUBLIME_API_KEY)."
+	MessageCommandDoctorHintOrganization    string = "Ask an owner of %s to invite you (sublime org invite), or select your organization with sublime org use."
+	MessageCommandDoctorHintOrganizationUse string = "Select your organization with sublime org use <name>."
+	MessageCommandDoctorHintSublime         string = "Run sublime config validate to list the violations, and sublime config migrate for old schema versions."
+	MessageCommandDoctorHintPackage         string = "Restore the package folder, or remove the package from .sublime.json."
+	MessageCommandDoctorHintTsconfig        string = "Fix the references of tsconfig.base.json (sublime init adds the missing ones)."
+	MessageCommandDoctorHintWorkflow        string = "Run sublime upgrade to update the generated files."
+
+	MessageErrorCommandDoctorFailed string = "%d checks failed."
+
+	// External commands
+	MessageErrorProcess          string = "%s failed: %s"
+	MessageErrorProcessLog       string = "Full log: %s"
+	MessageErrorProcessCancelled string = "cancelled"
+	MessageErrorProcessTimeout   string = "timed out after %s"
+
+	// Git
+	MessageErrorGitRepo      string = "%s is not on a git repository."
+	MessageErrorGitRef       string = "Git ref %s not found."
+	MessageErrorGitShallow   string = "Git ref %s not found on this shallow clone. Fetch the history with fetch-depth: 0 on actions/checkout or git fetch --unshallow."
+	MessageErrorGitHistory   string = "The git history is incomplete on this shallow clone. Fetch the history and the tags with fetch-depth: 0 on actions/checkout or git fetch --unshallow --tags."
+	MessageErrorGitClone     string = "Unable to clone %s: %s"
+	MessageErrorGitAuthor    string = "Set user.name and user.email on the git config to commit and tag."
+	MessageErrorGitTagExists string = "Tag %s already exists."
+	MessageErrorGitStaged    string = "Files are staged on the git index: %s. Commit or unstage them first, they would end up in the release commit."
+	MessageErrorPackageTag   string = "%s is not a package tag (@scope/name@version)."
+
+	// Plugins
+	MessageCommandPluginShort string = "Plugin %s"
+
+	MessageErrorPluginRun string = "Unable to run plugin %s: %s"
+
+	// Dev server command
+	MessageCommandDevServerShort string = "Run a local fake of the cloud platform."
+	MessageCommandDevServerLong  string = `Dev server implements the auth, rest and storage endpoints used by the CLI
+	in memory (or on disk with --data), so register, login, workspace, create and action
+	can run without the cloud platform.
+	`
+	MessageCommandDevServerHost         string = "Host to listen on."
+	MessageCommandDevServerPort         string = "Port to listen on."
+	MessageCommandDevServerData         string = "Directory to persist state and uploaded objects (default in memory)."
+	MessageCommandDevServerSeed         string = "Json file with rows to seed, shaped as {\"table\": [rows]}."
+	MessageCommandDevServerOrganization string = "Organization every registered author joins automatically."
+	MessageCommandDevServerApiKey       string = "Anon api key accepted by the server."
+	MessageCommandDevServerServiceKey   string = "Service role key accepted by the server."
+	MessageCommandDevServerListening    string = "Dev server listening on %s"
+	MessageCommandDevServerKeys         string = "Anon key: %s | Service key: %s"
+	MessageCommandDevServerUsage        string = "Point the CLI to it with: export SUBLIME_API_URL=%s SUBLIME_API_KEY=%s SUBLIME_API_SECRET=%s"
 )
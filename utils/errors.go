@@ -0,0 +1,77 @@
+/*
+Copyright © 2022 Websublime.dev organization@websublime.dev
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package utils
+
+// CliError is an error carrying the ErrorType reported to the user. Commands
+// return it (RunE) instead of exiting, and Execute maps it to the exit code.
+type CliError struct {
+	Type    ErrorType
+	Message string
+}
+
+func (ctx *CliError) Error() string {
+	return ctx.Message
+}
+
+func NewCliError(message string, code ErrorType) error {
+	return &CliError{Type: code, Message: message}
+}
+
+// ExitCodes is the exit code of the cli for each ErrorType. 0 is success or
+// nothing to do, codes are grouped by area and never reused.
+var ExitCodes = map[ErrorType]int{
+	ErrorUnknown:               1,
+	ErrorCmdExecution:          2,
+	ErrorInvalidFlag:           3,
+	ErrorPromptInvalid:         4,
+	ErrorInvalidEnvironment:    5,
+	ErrorInvalidConfig:         6,
+	ErrorDoctorFailed:          7,
+	ErrorInvalidAuthor:         10,
+	ErrorInvalidToken:          11,
+	ErrorInvalidOrganization:   12,
+	ErrorInvalidWorkspace:      13,
+	ErrorWorkspaceDrift:        14,
+	ErrorVersionMismatch:       15,
+	ErrorOpenFile:              20,
+	ErrorReadFile:              21,
+	ErrorMissingFile:           22,
+	ErrorMissingDirectory:      23,
+	ErrorCreateDirectory:       24,
+	ErrorCreateFile:            25,
+	ErrorInvalidaIndentation:   26,
+	ErrorInvalidTemplate:       27,
+	ErrorInvalidGit:            30,
+	ErrorInvalidYarn:           31,
+	ErrorInvalidBuild:          32,
+	ErrorInvalidTypescript:     33,
+	ErrorHookFailed:            34,
+	ErrorInvalidCloudOperation: 40,
+}
+
+func ExitCode(code ErrorType) int {
+	if exit, ok := ExitCodes[code]; ok {
+		return exit
+	}
+
+	return ExitCodes[ErrorUnknown]
+}
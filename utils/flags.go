@@ -23,7 +23,6 @@ package utils
 
 //https://github.com/supabase/supabase/discussions/2337
 var (
-	ApiUrl    string = ""
-	ApiKey    string = ""
-	ApiSecret string = ""
+	ApiUrl string = ""
+	ApiKey string = ""
 )
@@ -23,7 +23,6 @@ package utils
 
 import (
 	"bytes"
-	"errors"
 	"fmt"
 	"html/template"
 	"os"
@@ -31,7 +30,6 @@ import (
 	"strings"
 
 	"github.com/gookit/color"
-	"github.com/spf13/cobra"
 )
 
 // Walk recursive a directory
@@ -39,6 +37,9 @@ func PathWalk(root string) ([]string, error) {
 	var files []string
 
 	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
 
 		if !info.IsDir() && !strings.HasPrefix(info.Name(), ".") {
 			files = append(files, path)
@@ -255,21 +256,84 @@ func Present(args []string, lookup string) bool {
 	return false
 }
 
-// Prints error and exit application
+// Check if scope is a known deploy token scope
+func IsDeployScope(scope string) bool {
+	return scope == string(ScopeUpload) || scope == string(ScopeVersion)
+}
+
+func IsOrganizationRole(role string) bool {
+	return role == string(RoleOwner) || role == string(RoleMember)
+}
+
+// IsInteractive reports if stdin is a terminal, so prompts can be shown.
+func IsInteractive() bool {
+	return IsTerminal(os.Stdin)
+}
+
+// IsTerminal reports if file is a terminal (not a pipe or a regular file).
+func IsTerminal(file *os.File) bool {
+	stat, err := file.Stat()
+	if err != nil {
+		return false
+	}
+
+	return stat.Mode()&os.ModeCharDevice != 0
+}
+
+func IsPackageType(packageType string) bool {
+	return packageType == string(Package) || packageType == string(Library)
+}
+
+func IsPackageManager(manager string) bool {
+	return manager == string(Yarn) || manager == string(YarnBerry) || manager == string(Npm) || manager == string(Pnpm)
+}
+
+func IsBumpType(bump string) bool {
+	return bump == string(BumpMajor) || bump == string(BumpMinor) || bump == string(BumpPatch)
+}
+
+func IsProgressMode(mode string) bool {
+	return mode == string(ProgressAuto) || mode == string(ProgressInteractive) || mode == string(ProgressPlain) || mode == string(ProgressJson)
+}
+
+// Prints error and exit application with the exit code of the error type.
+// Only Execute calls it, commands return a CliError.
 func ErrorOut(message string, code ErrorType) {
+	if IsStructured() {
+		output.SetError(message, code)
+		output.Flush()
+		os.Exit(ExitCode(code))
+	}
+
 	color.Red.Println(message)
-	cobra.CheckErr(errors.New(fmt.Sprintf("🚨 TYPE: %s", code)))
+	fmt.Fprintln(os.Stderr, fmt.Sprintf("Error: 🚨 TYPE: %s", code))
+	os.Exit(ExitCode(code))
 }
 
 func InfoOut(message string) {
+	if IsStructured() {
+		output.AddMessage("info", message)
+		return
+	}
+
 	color.Blue.Println(fmt.Sprintf("🚦 %s", message))
 }
 
 func SuccessOut(message string) {
+	if IsStructured() {
+		output.AddMessage("success", message)
+		return
+	}
+
 	color.Green.Println(fmt.Sprintf("⭐️ %s", message))
 }
 
 func WarningOut(message string) {
+	if IsStructured() {
+		output.AddMessage("warning", message)
+		return
+	}
+
 	color.Yellow.Println(fmt.Sprintf("🌨 %s", message))
 }
 
@@ -0,0 +1,166 @@
+/*
+Copyright © 2022 Websublime.dev organization@websublime.dev
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// OutputMessage is a line printed by InfoOut, SuccessOut or WarningOut,
+// collected on the result when the output is structured.
+type OutputMessage struct {
+	Level   string `json:"level" yaml:"level"`
+	Message string `json:"message" yaml:"message"`
+}
+
+type OutputError struct {
+	Type    ErrorType `json:"type" yaml:"type"`
+	Message string    `json:"message" yaml:"message"`
+}
+
+// OutputResult is the object printed by every command with --output json
+// or yaml. Result is the command specific object, Error is set on failures.
+type OutputResult struct {
+	Ok       bool            `json:"ok" yaml:"ok"`
+	Command  string          `json:"command" yaml:"command"`
+	Result   interface{}     `json:"result,omitempty" yaml:"result,omitempty"`
+	Error    *OutputError    `json:"error,omitempty" yaml:"error,omitempty"`
+	Messages []OutputMessage `json:"messages" yaml:"messages"`
+}
+
+type Output struct {
+	Format  OutputFormat
+	Quiet   bool
+	Writer  io.Writer
+	result  *OutputResult
+	flushed bool
+}
+
+var output = &Output{
+	Format: OutputTable,
+	Writer: os.Stdout,
+	result: &OutputResult{Ok: true, Messages: []OutputMessage{}},
+}
+
+func GetOutput() *Output {
+	return output
+}
+
+func IsOutputFormat(format string) bool {
+	return format == string(OutputTable) || format == string(OutputJson) || format == string(OutputYaml)
+}
+
+// IsStructured reports if the output is json or yaml, so human output
+// (banner, tables, progress, colored lines) must not be printed.
+func IsStructured() bool {
+	return output.Format != OutputTable
+}
+
+// IsQuiet reports if the banner and progress bars are suppressed.
+func IsQuiet() bool {
+	return output.Quiet || IsStructured()
+}
+
+func (ctx *Output) SetCommand(command string) {
+	ctx.result.Command = command
+}
+
+// SetResult sets the result object of the command.
+func (ctx *Output) SetResult(result interface{}) {
+	ctx.result.Result = result
+}
+
+func (ctx *Output) AddMessage(level string, message string) {
+	ctx.result.Messages = append(ctx.result.Messages, OutputMessage{Level: level, Message: message})
+}
+
+// SetError marks the result as failed.
+func (ctx *Output) SetError(message string, code ErrorType) {
+	ctx.result.Ok = false
+	ctx.result.Error = &OutputError{Type: code, Message: message}
+}
+
+// Flush prints the result once, when the output is structured.
+func (ctx *Output) Flush() {
+	if !IsStructured() || ctx.flushed {
+		return
+	}
+
+	ctx.flushed = true
+
+	var data []byte
+	var err error
+
+	if ctx.Format == OutputYaml {
+		data, err = jsonToYaml(ctx.result)
+	} else {
+		data, err = json.MarshalIndent(ctx.result, "", "  ")
+		data = append(data, '\n')
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return
+	}
+
+	_, _ = ctx.Writer.Write(data)
+}
+
+// jsonToYaml encodes value with the keys (and order) of its json encoding,
+// as most models only have json tags.
+func jsonToYaml(value interface{}) ([]byte, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	node := &yaml.Node{}
+	if err := yaml.Unmarshal(data, node); err != nil {
+		return nil, err
+	}
+
+	blockStyle(node)
+
+	var buffer bytes.Buffer
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(2)
+
+	if err := encoder.Encode(node); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
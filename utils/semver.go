@@ -0,0 +1,193 @@
+/*
+Copyright © 2022 Websublime.dev organization@websublime.dev
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// CompareVersions compares two semver strings (major.minor.patch with an
+// optional -prerelease) and returns -1, 0 or 1. A leading "v" is ignored and
+// a version with prerelease is lower than the same version without it.
+func CompareVersions(left string, right string) int {
+	leftCore, leftPre := splitVersion(left)
+	rightCore, rightPre := splitVersion(right)
+
+	for idx := 0; idx < 3; idx++ {
+		if leftCore[idx] != rightCore[idx] {
+			if leftCore[idx] < rightCore[idx] {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	switch {
+	case leftPre == rightPre:
+		return 0
+	case leftPre == "":
+		return 1
+	case rightPre == "":
+		return -1
+	}
+
+	return comparePrerelease(leftPre, rightPre)
+}
+
+// comparePrerelease compares dot separated identifiers as semver does:
+// numeric ones numerically and lower than alphanumeric ones, the rest as
+// strings, and a shorter prerelease is lower when all its fields are equal
+// (rc.2 < rc.10 and alpha < alpha.1).
+func comparePrerelease(left string, right string) int {
+	leftFields := strings.Split(left, ".")
+	rightFields := strings.Split(right, ".")
+
+	for idx := 0; idx < len(leftFields) && idx < len(rightFields); idx++ {
+		leftNumber, leftErr := strconv.ParseUint(leftFields[idx], 10, 64)
+		rightNumber, rightErr := strconv.ParseUint(rightFields[idx], 10, 64)
+
+		switch {
+		case leftErr == nil && rightErr == nil:
+			if leftNumber != rightNumber {
+				if leftNumber < rightNumber {
+					return -1
+				}
+				return 1
+			}
+		case leftErr == nil:
+			return -1
+		case rightErr == nil:
+			return 1
+		case leftFields[idx] != rightFields[idx]:
+			if leftFields[idx] < rightFields[idx] {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	switch {
+	case len(leftFields) < len(rightFields):
+		return -1
+	case len(leftFields) > len(rightFields):
+		return 1
+	}
+
+	return 0
+}
+
+// BumpVersion increments version by bump. A prerelease is released without
+// an increment when it is already on the bump (1.2.0-beta.1 minor is 1.2.0).
+func BumpVersion(version string, bump BumpType) string {
+	numbers, prerelease := splitVersion(version)
+
+	switch bump {
+	case BumpMajor:
+		if prerelease == "" || numbers[1] != 0 || numbers[2] != 0 {
+			numbers[0]++
+		}
+		numbers[1], numbers[2] = 0, 0
+	case BumpMinor:
+		if prerelease == "" || numbers[2] != 0 {
+			numbers[1]++
+		}
+		numbers[2] = 0
+	case BumpPatch:
+		if prerelease == "" {
+			numbers[2]++
+		}
+	default:
+		return version
+	}
+
+	return fmt.Sprintf("%d.%d.%d", numbers[0], numbers[1], numbers[2])
+}
+
+// SatisfiesRange reports if version is on the range of a workspace
+// dependency: *, x.y.z, ^x.y.z, ~x.y.z or >=x.y.z, with an optional
+// workspace: prefix. Other ranges are reported as satisfied.
+func SatisfiesRange(rangeVersion string, version string) bool {
+	operator, base := SplitRange(rangeVersion)
+	if base == "" || strings.ContainsAny(base, " |<>") {
+		return true
+	}
+
+	wanted, _ := splitVersion(base)
+	current, _ := splitVersion(version)
+	newer := CompareVersions(version, base) >= 0
+
+	switch operator {
+	case ">=":
+		return newer
+	case "^":
+		if wanted[0] > 0 {
+			return newer && current[0] == wanted[0]
+		}
+		if wanted[1] > 0 {
+			return newer && current[0] == 0 && current[1] == wanted[1]
+		}
+		return CompareVersions(version, base) == 0
+	case "~":
+		return newer && current[0] == wanted[0] && current[1] == wanted[1]
+	}
+
+	return CompareVersions(version, base) == 0
+}
+
+// SplitRange splits a range on its prefix (workspace: and operator) and
+// version. Ranges without a version (*, workspace:^) have an empty version.
+func SplitRange(rangeVersion string) (string, string) {
+	rest := strings.TrimPrefix(strings.TrimSpace(rangeVersion), "workspace:")
+
+	for _, operator := range []string{">=", "^", "~", "="} {
+		if strings.HasPrefix(rest, operator) {
+			return operator, strings.TrimPrefix(rest, operator)
+		}
+	}
+
+	if rest == "*" || rest == "" {
+		return rest, ""
+	}
+
+	return "", rest
+}
+
+func splitVersion(version string) ([3]int, string) {
+	numbers := [3]int{}
+
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	version = strings.SplitN(version, "+", 2)[0]
+
+	prerelease := ""
+	if parts := strings.SplitN(version, "-", 2); len(parts) == 2 {
+		version = parts[0]
+		prerelease = parts[1]
+	}
+
+	for idx, part := range strings.SplitN(version, ".", 3) {
+		numbers[idx], _ = strconv.Atoi(part)
+	}
+
+	return numbers, prerelease
+}